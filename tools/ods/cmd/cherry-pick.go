@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"regexp"
@@ -20,6 +21,8 @@ type CherryPickOptions struct {
 	Yes      bool
 	NoVerify bool
 	Continue bool
+	Retries  int
+	NoStash  bool
 }
 
 // NewCherryPickCommand creates a new cherry-pick command
@@ -45,6 +48,9 @@ The --release flag can be specified multiple times to cherry-pick to multiple re
 If a cherry-pick hits a merge conflict, resolve it manually, then run:
   $ ods cherry-pick --continue
 
+Uncommitted changes are stashed before switching branches and restored
+afterwards. Pass --no-stash to fail instead when the worktree is dirty.
+
 Example usage:
 
 	$ ods cherry-pick foo123 bar456 --release 2.5 --release 2.6
@@ -63,6 +69,7 @@ Example usage:
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			git.RetryAttempts = opts.Retries
 			if opts.Continue {
 				runCherryPickContinue()
 			} else {
@@ -76,6 +83,8 @@ Example usage:
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Perform all local operations but skip pushing to remote and creating PRs")
 	cmd.Flags().BoolVar(&opts.Yes, "yes", false, "Skip confirmation prompts and automatically proceed")
 	cmd.Flags().BoolVar(&opts.NoVerify, "no-verify", false, "Skip pre-commit and commit-msg hooks for cherry-pick and push")
+	cmd.Flags().BoolVar(&opts.NoStash, "no-stash", false, "Fail if there are uncommitted changes instead of stashing and restoring them")
+	cmd.Flags().IntVar(&opts.Retries, "fetch-retries", git.RetryAttempts, "Number of attempts for git fetches that fail with transient network errors")
 
 	return cmd
 }
@@ -102,10 +111,16 @@ func runCherryPick(cmd *cobra.Command, args []string, opts *CherryPickOptions) {
 	log.Debugf("Original branch: %s", originalBranch)
 
 	// Stash any uncommitted changes before switching branches
+	if opts.NoStash && git.HasUncommittedChanges() {
+		log.Fatal("You have uncommitted changes. Commit or stash them first, or drop --no-stash to have them stashed and restored automatically.")
+	}
 	stashResult, err := git.StashChanges()
 	if err != nil {
 		log.Fatalf("Failed to stash changes: %v", err)
 	}
+	if stashResult.Stashed {
+		log.Warn("Your uncommitted changes were stashed and will be restored when the cherry-pick finishes (use --no-stash to fail instead).")
+	}
 
 	// Fetch commits from remote before cherry-picking
 	if err := git.FetchCommits(commitSHAs); err != nil {
@@ -259,12 +274,17 @@ func finishCherryPick(state *git.CherryPickState, stashResult *git.StashResult)
 		log.Warnf("Failed to switch back to original branch: %v", err)
 	}
 
-	git.RestoreStash(stashResult)
+	stashErr := git.RestoreStash(stashResult)
 	git.CleanCherryPickState()
 
 	for i, prURL := range prURLs {
 		log.Infof("PR %d: %s", i+1, prURL)
 	}
+	if errors.Is(stashErr, git.ErrStashConflict) {
+		log.Warnf("The cherry-pick finished, but restoring your stashed changes on %s conflicted; see above.", state.OriginalBranch)
+	} else if stashErr != nil {
+		log.Warn("The cherry-pick finished, but your stashed changes could not be restored; see above.")
+	}
 }
 
 // runCherryPickContinue resumes a cherry-pick after manual conflict resolution.
@@ -301,7 +321,7 @@ func cherryPickToRelease(commitSHAs, commitMessages []string, branchSuffix, vers
 
 	// Fetch the release branch
 	log.Infof("Fetching release branch: %s", releaseBranch)
-	if err := git.RunCommand("fetch", "--prune", "--quiet", "origin", releaseBranch); err != nil {
+	if err := git.FetchWithRetry("--prune", "--quiet", "origin", releaseBranch); err != nil {
 		return "", fmt.Errorf("failed to fetch release branch %s: %w", releaseBranch, err)
 	}
 
@@ -455,6 +475,34 @@ func findNearestStableTag(commitSHA string) (string, error) {
 	return matches[1], nil
 }
 
+// fullCommitMessages returns a PR body section quoting the full message of
+// each commit, or "" if none could be read.
+func fullCommitMessages(commitSHAs []string) string {
+	var sections []string
+	for _, sha := range commitSHAs {
+		msg, err := git.GetCommitMessageFull(sha)
+		if err != nil {
+			log.Warnf("Failed to get full commit message for %s: %v", sha, err)
+			continue
+		}
+		shortSHA := sha
+		if len(shortSHA) > 8 {
+			shortSHA = shortSHA[:8]
+		}
+		quoted := "> " + strings.ReplaceAll(msg, "\n", "\n> ")
+		sections = append(sections, fmt.Sprintf("%s:\n\n%s", shortSHA, quoted))
+	}
+	if len(sections) == 0 {
+		return ""
+	}
+
+	heading := "### Original commit message"
+	if len(commitSHAs) > 1 {
+		heading += "s"
+	}
+	return heading + "\n\n" + strings.Join(sections, "\n\n")
+}
+
 // createCherryPickPR creates a pull request for cherry-picks using the GitHub CLI
 func createCherryPickPR(headBranch, baseBranch, title string, commitSHAs, commitMessages []string) (string, error) {
 	var body string
@@ -488,6 +536,11 @@ func createCherryPickPR(headBranch, baseBranch, title string, commitSHAs, commit
 		}
 	}
 
+	// Include the original commit messages so reviewers see the rationale
+	if messages := fullCommitMessages(commitSHAs); messages != "" {
+		body += "\n\n" + messages
+	}
+
 	// Add standard checklist
 	body += "\n\n"
 	body += "- [x] [Required] I have considered whether this PR needs to be cherry-picked to the latest beta branch.\n"
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewCompletionCommand creates the completion command. It replaces cobra's
+// default completion command so the install instructions live in one place.
+func NewCompletionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate a shell completion script",
+		Long: `Generate a shell completion script for ods.
+
+Completions include dynamic suggestions such as running service names for
+'ods logs' and profiles for 'ods compose'.
+
+Bash (requires the bash-completion package):
+  # Current shell only
+  source <(ods completion bash)
+
+  # Every new shell
+  ods completion bash | sudo tee /etc/bash_completion.d/ods > /dev/null
+
+Zsh:
+  # Enable completion if it is not already (add to ~/.zshrc)
+  autoload -U compinit; compinit
+
+  # Linux
+  ods completion zsh | sudo tee "${fpath[1]}/_ods" > /dev/null
+
+  # macOS (Homebrew)
+  ods completion zsh > $(brew --prefix)/share/zsh/site-functions/_ods
+
+Fish:
+  # Current shell only
+  ods completion fish | source
+
+  # Every new shell
+  ods completion fish > ~/.config/fish/completions/ods.fish
+
+PowerShell:
+  # Current shell only
+  ods completion powershell | Out-String | Invoke-Expression
+
+  # Every new shell: add the line above to your PowerShell profile
+
+Start a new shell after installing for the completions to take effect.`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			runCompletion(cmd.Root(), args[0])
+		},
+	}
+
+	return cmd
+}
+
+func runCompletion(root *cobra.Command, shell string) {
+	var err error
+	switch shell {
+	case "bash":
+		err = root.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		err = root.GenZshCompletion(os.Stdout)
+	case "fish":
+		err = root.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		err = root.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("Failed to generate %s completion: %v", shell, err)
+	}
+}
@@ -5,11 +5,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/atomicfile"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/envfile"
 	"github.com/onyx-dot-app/onyx/tools/ods/internal/paths"
 )
 
@@ -17,13 +21,30 @@ var validProfiles = []string{"dev", "multitenant"}
 
 const composeProjectName = "onyx"
 
+const (
+	// localOverrideFile is a gitignored compose file in the compose directory
+	// for per-developer tweaks (extra ports, mounts), stacked after the
+	// profile's files when local overrides are enabled.
+	localOverrideFile = "docker-compose.override.local.yml"
+
+	// LocalOverrideEnv names the environment variable that enables
+	// localOverrideFile like --local-override, when set to 1.
+	LocalOverrideEnv = "ODS_LOCAL_OVERRIDE"
+)
+
+// localOverride is the --local-override flag value.
+var localOverride bool
+
 // ComposeOptions holds options for the compose command
 type ComposeOptions struct {
 	Down          bool
 	Wait          bool
 	ForceRecreate bool
 	Tag           string
+	Platform      string
 	NoEE          bool
+	Profiles      []string // docker compose profiles to enable (--profile)
+	DryRun        bool
 }
 
 // NewComposeCommand creates a new compose command for launching docker containers
@@ -38,10 +59,19 @@ func NewComposeCommand() *cobra.Command {
 By default, this runs docker compose up -d with the standard docker-compose.yml.
 Enterprise Edition features are enabled by default for development.
 
+With --wait (the default), each service's state and health is printed once
+compose returns, and the command fails if any service is unhealthy or
+exited with an error.
+
 Available profiles:
   dev          Use dev configuration (exposes service ports for development)
   multitenant  Use multitenant configuration
 
+The positional profile selects which compose files (-f) are used. It is
+unrelated to docker compose's own profiles, which opt optional services
+(those with a "profiles:" key, e.g. gpu-model) in; enable those with
+--compose-profile, which is passed through as docker compose --profile.
+
 Examples:
   # Start containers with default configuration (EE enabled)
   ods compose
@@ -66,7 +96,24 @@ Examples:
   ods compose --force-recreate
 
   # Use a specific image tag
-  ods compose --tag edge`,
+  ods compose --tag edge
+
+  # Also start optional services in the gpu-model compose profile
+  ods compose dev --compose-profile gpu-model
+
+  # Run native arm64 images instead of emulating amd64 (or vice versa)
+  ods compose --platform linux/arm64
+
+  # Show the docker command and .env changes without running anything
+  ods compose dev --tag edge --dry-run
+
+  # Show container processes or resource usage
+  ods compose top
+  ods compose stats --no-stream
+
+  # View or change settings in the compose .env file
+  ods compose env list
+  ods compose env set IMAGE_TAG=edge`,
 		Args:      cobra.MaximumNArgs(1),
 		ValidArgs: validProfiles,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -79,10 +126,19 @@ Examples:
 	}
 
 	cmd.Flags().BoolVar(&opts.Down, "down", false, "Stop running containers instead of starting them")
-	cmd.Flags().BoolVar(&opts.Wait, "wait", true, "Wait for services to be healthy before returning")
+	cmd.Flags().BoolVar(&opts.Wait, "wait", true, "Wait for services to be healthy before returning, then print a per-service health summary")
 	cmd.Flags().BoolVar(&opts.ForceRecreate, "force-recreate", false, "Force recreate containers even if unchanged")
-	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Set the IMAGE_TAG for docker compose (e.g. edge, v2.10.4)")
+	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Set the IMAGE_TAG for docker compose (e.g. edge, v2.10.4); defaults to the tag in .ods-image-tag at the repo root, then an exported or .env IMAGE_TAG")
+	cmd.Flags().StringVar(&opts.Platform, "platform", "", "Set DOCKER_DEFAULT_PLATFORM for docker compose (e.g. linux/amd64, linux/arm64)")
+	cmd.Flags().StringSliceVar(&opts.Profiles, "compose-profile", nil, "Enable a docker compose profile, starting its optional services (repeatable; not the positional profile)")
 	cmd.Flags().BoolVar(&opts.NoEE, "no-ee", false, "Disable Enterprise Edition features (enabled by default)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the docker command and .env changes without running docker or writing files")
+	addLocalOverrideFlag(cmd)
+
+	// Add subcommands
+	cmd.AddCommand(NewComposeTopCommand())
+	cmd.AddCommand(NewComposeStatsCommand())
+	cmd.AddCommand(NewComposeEnvCommand())
 
 	return cmd
 }
@@ -94,16 +150,51 @@ func validateProfile(profile string) {
 	}
 }
 
-// composeFiles returns the list of docker compose files for the given profile.
+// composeFiles returns the list of docker compose files for the given
+// profile, followed by the local override file when enabled and present.
 func composeFiles(profile string) []string {
+	var files []string
 	switch profile {
 	case "multitenant":
-		return []string{"docker-compose.multitenant-dev.yml"}
+		files = []string{"docker-compose.multitenant-dev.yml"}
 	case "dev":
-		return []string{"docker-compose.yml", "docker-compose.dev.yml"}
+		files = []string{"docker-compose.yml", "docker-compose.dev.yml"}
 	default:
-		return []string{"docker-compose.yml"}
+		files = []string{"docker-compose.yml"}
 	}
+
+	if useLocalOverride() {
+		files = append(files, localOverrideFile)
+	}
+	return files
+}
+
+// useLocalOverride reports whether composeFiles should stack the local
+// override file: it is enabled and exists. It is resolved, and logged, once
+// per command, however many docker compose invocations the command makes.
+var useLocalOverride = sync.OnceValue(func() bool {
+	if !localOverrideEnabled() {
+		return false
+	}
+	path := filepath.Join(composeDir(), localOverrideFile)
+	if _, err := os.Stat(path); err != nil {
+		log.Warnf("Local compose override requested, but %s does not exist", path)
+		return false
+	}
+	log.Infof("Applying local compose override %s", path)
+	return true
+})
+
+// addLocalOverrideFlag registers --local-override on a command that runs
+// docker compose, and its subcommands.
+func addLocalOverrideFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&localOverride, "local-override", false, "Stack "+localOverrideFile+" from the compose directory after the profile's compose files, if it exists (or set "+LocalOverrideEnv+"=1)")
+}
+
+// localOverrideEnabled reports whether --local-override or
+// ODS_LOCAL_OVERRIDE=1 asks for the local override file.
+func localOverrideEnabled() bool {
+	return localOverride || os.Getenv(LocalOverrideEnv) == "1"
 }
 
 // baseArgs builds the common "docker compose -p <project> -f ... -f ..." argument prefix.
@@ -126,6 +217,14 @@ func profileLabel(profile string) string {
 // execDockerCompose runs a docker compose command in the correct directory with
 // optional extra environment variables.
 func execDockerCompose(args []string, extraEnv []string) {
+	if err := runDockerCompose(args, extraEnv); err != nil {
+		log.Fatalf("Docker compose failed: %v", err)
+	}
+}
+
+// runDockerCompose is execDockerCompose, returning the error instead of
+// exiting.
+func runDockerCompose(args []string, extraEnv []string) error {
 	log.Debugf("Running: docker %v", args)
 
 	dockerCmd := exec.Command("docker", args...)
@@ -133,12 +232,47 @@ func execDockerCompose(args []string, extraEnv []string) {
 	dockerCmd.Stdout = os.Stdout
 	dockerCmd.Stderr = os.Stderr
 	dockerCmd.Stdin = os.Stdin
-	if len(extraEnv) > 0 {
-		dockerCmd.Env = append(os.Environ(), extraEnv...)
+	dockerCmd.Env = composeEnv(extraEnv)
+
+	return dockerCmd.Run()
+}
+
+// composeEnv returns the environment for a docker compose command: the
+// current one plus extraEnv, or nil (inherit) when there is nothing extra.
+func composeEnv(extraEnv []string) []string {
+	if len(extraEnv) == 0 {
+		return nil
 	}
+	return append(os.Environ(), extraEnv...)
+}
 
-	if err := dockerCmd.Run(); err != nil {
-		log.Fatalf("Docker compose failed: %v", err)
+// printDockerCompose prints the command execDockerCompose would run, for
+// --dry-run.
+func printDockerCompose(args []string, extraEnv []string) {
+	var words []string
+	for _, kv := range extraEnv {
+		key, value, _ := strings.Cut(kv, "=")
+		words = append(words, key+"="+shellQuote(value))
+	}
+	words = append(words, "docker")
+	for _, a := range args {
+		words = append(words, shellQuote(a))
+	}
+	log.Infof("Dry run: would run in %s:", composeDir())
+	fmt.Println("  " + strings.Join(words, " "))
+}
+
+// printEnvChange prints the change setEnvValue(key, value) would make, for
+// --dry-run.
+func printEnvChange(key, value string) {
+	current, ok := getEnvValue(key)
+	switch {
+	case !ok:
+		log.Infof("Dry run: would add %s=%s to %s", key, value, envFilePath())
+	case current == value:
+		log.Infof("Dry run: %s=%s is already set in %s", key, value, envFilePath())
+	default:
+		log.Infof("Dry run: would change %s from %q to %q in %s", key, current, value, envFilePath())
 	}
 }
 
@@ -169,12 +303,95 @@ func runningServiceNames() []string {
 	return services
 }
 
+// imageTagFile is the file at the repository root that pins the default
+// IMAGE_TAG for compose and pull, so a team shares one reproducible tag.
+const imageTagFile = ".ods-image-tag"
+
 // envForTag returns the environment slice needed to set IMAGE_TAG, or nil.
+// The tag comes from, in order: --tag, the tag pinned in imageTagFile, an
+// IMAGE_TAG exported in the shell, IMAGE_TAG in the compose .env file, or
+// the compose file's default. Only --tag overrides the pin, so a stale
+// IMAGE_TAG left in a developer's shell or .env cannot silently diverge
+// from it; one that differs is warned about. Only a tag from --tag or
+// imageTagFile needs setting; docker compose reads the others itself. It
+// logs where the tag came from.
 func envForTag(tag string) []string {
-	if tag == "" {
+	if tag != "" {
+		log.Infof("Using IMAGE_TAG=%s from --tag", tag)
+		return []string{fmt.Sprintf("IMAGE_TAG=%s", tag)}
+	}
+
+	pinned, err := readImageTagFile()
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", imageTagFile, err)
+	}
+	exported, _ := os.LookupEnv("IMAGE_TAG")
+	fromEnvFile, _ := getEnvValue("IMAGE_TAG")
+
+	if pinned != "" {
+		if exported != "" && exported != pinned {
+			log.Warnf("Ignoring IMAGE_TAG=%s from the environment: %s pins %s (use --tag to override)", exported, imageTagFile, pinned)
+		}
+		if fromEnvFile != "" && fromEnvFile != pinned {
+			log.Warnf("Ignoring IMAGE_TAG=%s from %s: %s pins %s (use --tag to override)", fromEnvFile, envFilePath(), imageTagFile, pinned)
+		}
+		log.Infof("Using IMAGE_TAG=%s from %s", pinned, imageTagFile)
+		return []string{fmt.Sprintf("IMAGE_TAG=%s", pinned)}
+	}
+	if exported != "" {
+		log.Infof("Using IMAGE_TAG=%s from the environment", exported)
+		return nil
+	}
+	if fromEnvFile != "" {
+		log.Infof("Using IMAGE_TAG=%s from %s", fromEnvFile, envFilePath())
+		return nil
+	}
+	log.Infof("Using the compose default IMAGE_TAG (no --tag, %s, or IMAGE_TAG)", imageTagFile)
+	return nil
+}
+
+// readImageTagFile returns the tag pinned in imageTagFile at the repository
+// root: its first line that is neither blank nor a # comment. It returns ""
+// when there is no such file.
+func readImageTagFile() (string, error) {
+	gitRoot, err := paths.GitRoot()
+	if err != nil {
+		return "", nil
+	}
+	path := filepath.Join(gitRoot, imageTagFile)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.ContainsAny(line, " \t") {
+			return "", fmt.Errorf("tag %q in %s contains whitespace", line, path)
+		}
+		return line, nil
+	}
+	return "", nil
+}
+
+// envForPlatform returns the environment slice needed to set
+// DOCKER_DEFAULT_PLATFORM, or nil. It exits on a malformed platform and logs
+// the one chosen, since running under emulation is otherwise silent.
+func envForPlatform(platform string) []string {
+	if platform == "" {
 		return nil
 	}
-	return []string{fmt.Sprintf("IMAGE_TAG=%s", tag)}
+	goos, arch, ok := strings.Cut(platform, "/")
+	if !ok || goos == "" || arch == "" || strings.ContainsAny(platform, " \t") {
+		log.Fatalf("Invalid --platform %q: expected <os>/<arch>[/<variant>], e.g. linux/amd64", platform)
+	}
+	log.Infof("Using platform %s", platform)
+	return []string{fmt.Sprintf("DOCKER_DEFAULT_PLATFORM=%s", platform)}
 }
 
 // composeDir returns the path to the docker compose directory.
@@ -186,49 +403,37 @@ func composeDir() string {
 	return filepath.Join(gitRoot, "deployment", "docker_compose")
 }
 
-// setEnvValue sets a key=value pair in the .env file within the compose
-// directory. If the key already exists its value is updated in place;
-// otherwise the entry is appended. The file is created if it does not exist.
-func setEnvValue(key, value string) {
-	envPath := filepath.Join(composeDir(), ".env")
+// envFilePath returns the path to the .env file within the compose directory.
+func envFilePath() string {
+	return filepath.Join(composeDir(), ".env")
+}
 
+// readEnvFile returns the contents of the compose .env file, or nil if it
+// does not exist.
+func readEnvFile() []byte {
+	envPath := envFilePath()
 	data, err := os.ReadFile(envPath)
 	if err != nil && !os.IsNotExist(err) {
 		log.Fatalf("Failed to read %s: %v", envPath, err)
 	}
+	return data
+}
 
-	entry := fmt.Sprintf("%s=%s", key, value)
-	prefix := key + "="
-
-	if len(data) == 0 {
-		// File missing or empty – create with just this entry.
-		if err := os.WriteFile(envPath, []byte(entry+"\n"), 0644); err != nil {
-			log.Fatalf("Failed to write %s: %v", envPath, err)
-		}
-		return
-	}
-
-	lines := strings.Split(string(data), "\n")
-	found := false
-	for i, line := range lines {
-		if strings.HasPrefix(line, prefix) {
-			lines[i] = entry
-			found = true
-			break
-		}
-	}
-
-	if !found {
-		// Insert before the trailing empty line (if the file ended with \n)
-		// so we don't accumulate blank lines.
-		if lines[len(lines)-1] == "" {
-			lines = append(lines[:len(lines)-1], entry, "")
-		} else {
-			lines = append(lines, entry)
-		}
-	}
+// getEnvValue returns the value of key in the .env file within the compose
+// directory, and whether it is set.
+func getEnvValue(key string) (string, bool) {
+	return envfile.Get(readEnvFile(), key)
+}
 
-	if err := os.WriteFile(envPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+// setEnvValue sets a key=value pair in the .env file within the compose
+// directory. If the key already exists its value is updated in place,
+// keeping its quoting; otherwise the entry is appended. Comments and other
+// entries are preserved. The file is created if it does not exist, and
+// replaced atomically so an interrupted write cannot truncate it.
+func setEnvValue(key, value string) {
+	envPath := envFilePath()
+	data := envfile.Set(readEnvFile(), key, value)
+	if err := atomicfile.WriteFile(envPath, data); err != nil {
 		log.Fatalf("Failed to write %s: %v", envPath, err)
 	}
 }
@@ -236,18 +441,28 @@ func setEnvValue(key, value string) {
 func runCompose(profile string, opts *ComposeOptions) {
 	validateProfile(profile)
 
+	setEnv := setEnvValue
+	if opts.DryRun {
+		setEnv = printEnvChange
+	}
+
 	if !opts.Down {
 		eeValue := "true"
 		if opts.NoEE {
 			eeValue = "false"
 		}
-		setEnvValue("ENABLE_PAID_ENTERPRISE_EDITION_FEATURES", eeValue)
+		setEnv("ENABLE_PAID_ENTERPRISE_EDITION_FEATURES", eeValue)
 		if !opts.NoEE {
-			setEnvValue("LICENSE_ENFORCEMENT_ENABLED", "false")
+			setEnv("LICENSE_ENFORCEMENT_ENABLED", "false")
 		}
 	}
 
 	args := baseArgs(profile)
+	// Also for down, which otherwise leaves the profiles' services running
+	for _, p := range opts.Profiles {
+		args = append(args, "--profile", p)
+	}
+	psArgs := append(slices.Clone(args), "ps", "--all", "--format", "json")
 
 	if opts.Down {
 		args = append(args, "down")
@@ -266,15 +481,34 @@ func runCompose(profile string, opts *ComposeOptions) {
 		action = "Stopping"
 	}
 	log.Infof("%s containers with %s configuration...", action, profileLabel(profile))
+	if len(opts.Profiles) > 0 {
+		log.Infof("Docker compose profiles: %s", strings.Join(opts.Profiles, ", "))
+	}
 	if !opts.Down && !opts.NoEE {
 		log.Info("Enterprise Edition features enabled (use --no-ee to disable)")
 	}
 
-	execDockerCompose(args, envForTag(opts.Tag))
-
+	env := append(envForTag(opts.Tag), envForPlatform(opts.Platform)...)
+	if opts.DryRun {
+		printDockerCompose(args, env)
+		return
+	}
 	if opts.Down {
+		execDockerCompose(args, env)
 		log.Info("Containers stopped successfully")
-	} else {
-		log.Info("Containers started successfully")
+		return
+	}
+
+	// Summarize service health even when --wait failed, so it is clear
+	// which services did not come up
+	err := runDockerCompose(args, env)
+	if opts.Wait {
+		if failing := printServiceHealth(psArgs, env); len(failing) > 0 {
+			log.Fatalf("Services not healthy: %s (see: ods logs %s)", strings.Join(failing, ", "), failing[0])
+		}
+	}
+	if err != nil {
+		log.Fatalf("Docker compose failed: %v", err)
 	}
+	log.Info("Containers started successfully")
 }
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/envfile"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/paths"
+)
+
+// ComposeEnvListOptions holds options for the compose env list command.
+type ComposeEnvListOptions struct {
+	ShowSecrets bool
+}
+
+// maskedValue replaces secret values in compose env list output.
+const maskedValue = "********"
+
+// NewComposeEnvCommand creates the compose env command and its subcommands.
+func NewComposeEnvCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "View or change values in the compose .env file",
+		Long: `View or change values in deployment/docker_compose/.env.
+
+Edits preserve comments, other entries, and the quoting of existing values,
+so prefer these commands over editing the file by hand.
+
+Examples:
+  # List all values (secrets are masked)
+  ods compose env list
+
+  # Print a single value
+  ods compose env get IMAGE_TAG
+
+  # Set one or more values
+  ods compose env set IMAGE_TAG=edge AUTH_TYPE=disabled`,
+	}
+
+	cmd.AddCommand(newComposeEnvGetCommand())
+	cmd.AddCommand(newComposeEnvSetCommand())
+	cmd.AddCommand(newComposeEnvListCommand())
+
+	return cmd
+}
+
+func newComposeEnvGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print a value from the compose .env file",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return envKeys(), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			value, ok := getEnvValue(args[0])
+			if !ok {
+				log.Fatalf("%s is not set in %s", args[0], envFilePath())
+			}
+			fmt.Println(value)
+		},
+	}
+}
+
+func newComposeEnvSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set KEY=VALUE...",
+		Short: "Set values in the compose .env file",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runComposeEnvSet(args)
+		},
+	}
+}
+
+func newComposeEnvListCommand() *cobra.Command {
+	opts := &ComposeEnvListOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List values in the compose .env file",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runComposeEnvList(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.ShowSecrets, "show-secrets", false, "Print secret-looking values (passwords, tokens, keys) instead of masking them")
+
+	return cmd
+}
+
+func runComposeEnvSet(args []string) {
+	// Validate every pair before writing any of them
+	type pair struct{ key, value string }
+	var pairs []pair
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			log.Fatalf("Invalid argument %q: expected KEY=VALUE", arg)
+		}
+		if !envfile.ValidKey(key) {
+			log.Fatalf("Invalid key %q: must match [A-Za-z_][A-Za-z0-9_]*", key)
+		}
+		pairs = append(pairs, pair{key, value})
+	}
+
+	for _, p := range pairs {
+		setEnvValue(p.key, p.value)
+		log.Infof("Set %s in %s", p.key, envFilePath())
+	}
+}
+
+func runComposeEnvList(opts *ComposeEnvListOptions) {
+	entries := envfile.Parse(readEnvFile())
+	if len(entries) == 0 {
+		log.Infof("No values set in %s", envFilePath())
+		return
+	}
+
+	for _, e := range entries {
+		value := e.Value
+		if !opts.ShowSecrets && value != "" && envfile.LooksSecret(e.Key) {
+			value = maskedValue
+		}
+		fmt.Printf("%s=%s\n", e.Key, value)
+	}
+}
+
+// envKeys returns the keys in the compose .env file, for shell completion.
+// On any error it returns nil (completions will just be empty).
+func envKeys() []string {
+	gitRoot, err := paths.GitRoot()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(gitRoot, "deployment", "docker_compose", ".env"))
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, e := range envfile.Parse(data) {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// serviceState is one container from "docker compose ps --format json".
+type serviceState struct {
+	Service  string `json:"Service"`
+	State    string `json:"State"`  // running, exited, restarting, ...
+	Health   string `json:"Health"` // healthy, unhealthy, starting, or empty without a healthcheck
+	ExitCode int    `json:"ExitCode"`
+}
+
+// ok reports whether the service counts as up: running and not unhealthy,
+// or a one-shot service that exited successfully.
+func (s serviceState) ok() bool {
+	switch s.State {
+	case "running":
+		return s.Health != "unhealthy"
+	case "exited":
+		return s.ExitCode == 0
+	}
+	return false
+}
+
+// describe returns the state shown in the health summary, e.g.
+// "running (healthy)" or "exited (1)".
+func (s serviceState) describe() string {
+	switch {
+	case s.State == "exited":
+		return fmt.Sprintf("exited (%d)", s.ExitCode)
+	case s.Health != "":
+		return fmt.Sprintf("%s (%s)", s.State, s.Health)
+	}
+	return s.State
+}
+
+// parseServiceStates parses "docker compose ps --format json" output, which
+// is a JSON array in older Compose releases and one object per line in
+// newer ones.
+func parseServiceStates(out []byte) ([]serviceState, error) {
+	out = bytes.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	var states []serviceState
+	if out[0] == '[' {
+		if err := json.Unmarshal(out, &states); err != nil {
+			return nil, err
+		}
+		return states, nil
+	}
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var s serviceState
+		if err := json.Unmarshal(line, &s); err != nil {
+			return nil, err
+		}
+		states = append(states, s)
+	}
+	return states, nil
+}
+
+// printServiceHealth prints one line per compose service with its state and
+// health, and returns the services that are unhealthy, exited with an
+// error, or otherwise not running. If the states cannot be read it only
+// warns and returns nil.
+func printServiceHealth(args []string, env []string) []string {
+	log.Debugf("Running: docker %v", args)
+
+	psCmd := exec.Command("docker", args...)
+	psCmd.Dir = composeDir()
+	psCmd.Env = composeEnv(env)
+	out, err := psCmd.Output()
+	if err != nil {
+		log.Warnf("Could not read service health: %v", err)
+		return nil
+	}
+	states, err := parseServiceStates(out)
+	if err != nil {
+		log.Warnf("Could not parse service health: %v", err)
+		return nil
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Service < states[j].Service })
+
+	width := 0
+	for _, s := range states {
+		width = max(width, len(s.Service))
+	}
+
+	var failing []string
+	fmt.Println()
+	for _, s := range states {
+		fmt.Printf("  %s %-*s  %s\n", serviceMark(s.ok()), width, s.Service, s.describe())
+		if !s.ok() {
+			failing = append(failing, s.Service)
+		}
+	}
+	fmt.Println()
+	return failing
+}
+
+// serviceMark returns the status marker for a health summary line: a green
+// check or red cross, or plain OK/FAIL without color output.
+func serviceMark(ok bool) string {
+	switch {
+	case !colorOutput && ok:
+		return "OK  "
+	case !colorOutput:
+		return "FAIL"
+	case ok:
+		return "\033[32m✔\033[0m"
+	}
+	return "\033[31m✘\033[0m"
+}
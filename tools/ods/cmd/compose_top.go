@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// ComposeStatsOptions holds options for the compose stats command.
+type ComposeStatsOptions struct {
+	NoStream bool
+}
+
+// NewComposeTopCommand creates the compose top command.
+func NewComposeTopCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top [profile] [service...]",
+		Short: "Show running processes in Onyx containers",
+		Long: `Show the running processes of each Onyx container using docker compose top.
+
+The first argument may be a profile (dev, multitenant); any remaining
+arguments are treated as service names to filter the output.
+
+Examples:
+  # Show processes for all services
+  ods compose top
+
+  # Show processes for specific services in the dev profile
+  ods compose top dev api_server background`,
+		Args: cobra.ArbitraryArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return profileOrServiceCompletions(args), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			profile, services := splitProfileArg(args)
+			runComposeTop(profile, services)
+		},
+	}
+
+	return cmd
+}
+
+// NewComposeStatsCommand creates the compose stats command.
+func NewComposeStatsCommand() *cobra.Command {
+	opts := &ComposeStatsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stats [profile] [service...]",
+		Short: "Show resource usage of Onyx containers",
+		Long: `Show live CPU, memory, network, and block I/O usage of Onyx containers
+using docker compose stats.
+
+The first argument may be a profile (dev, multitenant); any remaining
+arguments are treated as service names to filter the output.
+
+Examples:
+  # Live resource usage for all services
+  ods compose stats
+
+  # One-shot snapshot, suitable for scripting
+  ods compose stats --no-stream
+
+  # Resource usage for specific services
+  ods compose stats api_server background`,
+		Args: cobra.ArbitraryArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return profileOrServiceCompletions(args), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			profile, services := splitProfileArg(args)
+			runComposeStats(profile, services, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.NoStream, "no-stream", false, "Print a single snapshot instead of streaming live usage")
+
+	return cmd
+}
+
+// splitProfileArg treats the first argument as a profile if it names one,
+// returning it separately from the remaining service names.
+func splitProfileArg(args []string) (string, []string) {
+	if len(args) > 0 && slices.Contains(validProfiles, args[0]) {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+// profileOrServiceCompletions offers profiles for the first argument and
+// running service names for all arguments.
+func profileOrServiceCompletions(args []string) []string {
+	services := runningServiceNames()
+	if len(args) == 0 {
+		return append(slices.Clone(validProfiles), services...)
+	}
+	return services
+}
+
+func runComposeTop(profile string, services []string) {
+	validateProfile(profile)
+
+	args := baseArgs(profile)
+	args = append(args, "top")
+	args = append(args, services...)
+
+	log.Debugf("Showing container processes with %s configuration...", profileLabel(profile))
+	execDockerCompose(args, nil)
+}
+
+func runComposeStats(profile string, services []string, opts *ComposeStatsOptions) {
+	validateProfile(profile)
+
+	args := baseArgs(profile)
+	args = append(args, "stats")
+	if opts.NoStream {
+		args = append(args, "--no-stream")
+	}
+	args = append(args, services...)
+
+	log.Debugf("Showing container resource usage with %s configuration...", profileLabel(profile))
+	execDockerCompose(args, nil)
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/paths"
+)
+
+// DoctorOptions holds options for the doctor command.
+type DoctorOptions struct {
+	JSON bool
+}
+
+// DiagnosticResult is the outcome of a single doctor check. Its JSON form is
+// a stable contract for automation.
+type DiagnosticResult struct {
+	Name     string `json:"name"`
+	OK       bool   `json:"ok"`
+	Required bool   `json:"required"`
+	Detail   string `json:"detail"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// DiagnosticReport is the output of ods doctor --json.
+type DiagnosticReport struct {
+	// OK is true when every required check passed.
+	OK     bool               `json:"ok"`
+	Checks []DiagnosticResult `json:"checks"`
+}
+
+// diagnostic is one doctor check. run returns a short detail on success.
+type diagnostic struct {
+	name     string
+	required bool
+	hint     string
+	run      func() (string, error)
+}
+
+// doctorCommandTimeout bounds each external command a check runs, so a hung
+// docker daemon can't hang the doctor.
+const doctorCommandTimeout = 10 * time.Second
+
+// NewDoctorCommand creates the doctor command.
+func NewDoctorCommand() *cobra.Command {
+	opts := &DoctorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the tools ods relies on are installed and working",
+		Long: `Check the local environment for the tools and files ods relies on and
+print a checklist with hints for anything missing.
+
+Required checks (git, docker, docker compose, a running docker daemon) make
+the command exit 1 when they fail; optional ones (aws, azcopy, gh, alembic)
+only affect the commands that use them.
+
+--json prints a machine-readable report instead:
+  {"ok": false, "checks": [{"name": "docker", "ok": false, "required": true,
+   "detail": "...", "hint": "..."}, ...]}
+
+Examples:
+  # Print a checklist
+  ods doctor
+
+  # Machine-readable output for onboarding scripts
+  ods doctor --json`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runDoctor(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.JSON, "json", false, "Print the results as JSON")
+
+	return cmd
+}
+
+func runDoctor(opts *DoctorOptions) {
+	report := DiagnosticReport{OK: true}
+	for _, d := range diagnostics() {
+		result := DiagnosticResult{Name: d.name, Required: d.required}
+		detail, err := d.run()
+		if err != nil {
+			result.Detail = err.Error()
+			result.Hint = d.hint
+			if d.required {
+				report.OK = false
+			}
+		} else {
+			result.OK = true
+			result.Detail = detail
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Failed to write JSON: %v", err)
+		}
+	} else {
+		printDiagnostics(report)
+	}
+
+	if !report.OK {
+		os.Exit(1)
+	}
+}
+
+// diagnostics returns the doctor checks, in the order they are reported.
+func diagnostics() []diagnostic {
+	return []diagnostic{
+		{
+			name:     "git",
+			required: true,
+			hint:     "Install git from https://git-scm.com/downloads",
+			run:      func() (string, error) { return commandOutput("git", "--version") },
+		},
+		{
+			name:     "git repository",
+			required: true,
+			hint:     "Run ods from inside a clone of the onyx repository",
+			run: func() (string, error) {
+				gitRoot, err := paths.GitRoot()
+				if err != nil {
+					return "", fmt.Errorf("not inside a git repository")
+				}
+				return gitRoot, nil
+			},
+		},
+		{
+			name:     "docker",
+			required: true,
+			hint:     "Install Docker Desktop or Docker Engine from https://docs.docker.com/get-docker/",
+			run:      func() (string, error) { return commandOutput("docker", "--version") },
+		},
+		{
+			name:     "docker compose",
+			required: true,
+			hint:     "Install the Docker Compose plugin: https://docs.docker.com/compose/install/",
+			run:      func() (string, error) { return commandOutput("docker", "compose", "version") },
+		},
+		{
+			name:     "docker daemon",
+			required: true,
+			hint:     "Start Docker Desktop or the docker service (e.g. sudo systemctl start docker)",
+			run: func() (string, error) {
+				version, err := commandOutput("docker", "info", "--format", "{{.ServerVersion}}")
+				if err != nil {
+					return "", err
+				}
+				return "server " + version, nil
+			},
+		},
+		{
+			name: "compose .env",
+			hint: "Run `ods compose env set KEY=VALUE` to create it, or copy deployment/docker_compose/env.template",
+			run: func() (string, error) {
+				gitRoot, err := paths.GitRoot()
+				if err != nil {
+					return "", err
+				}
+				path := strings.TrimPrefix(envFilePath(), gitRoot+string(os.PathSeparator))
+				if _, err := os.Stat(envFilePath()); err != nil {
+					return "", fmt.Errorf("%s not found", path)
+				}
+				return path, nil
+			},
+		},
+		{
+			name: "aws",
+			hint: "Install the AWS CLI (needed for screenshot-diff S3 baselines): https://aws.amazon.com/cli/",
+			run:  func() (string, error) { return commandOutput("aws", "--version") },
+		},
+		{
+			name: "azcopy",
+			hint: "Install azcopy (needed for az:// screenshot baselines): https://learn.microsoft.com/azure/storage/common/storage-use-azcopy-v10",
+			run:  func() (string, error) { return commandOutput("azcopy", "--version") },
+		},
+		{
+			name: "gh",
+			hint: "Install the GitHub CLI (needed for cherry-pick and run-ci): https://cli.github.com/",
+			run:  func() (string, error) { return commandOutput("gh", "--version") },
+		},
+		{
+			name: "alembic",
+			hint: "Activate the backend virtualenv (needed for db migrate)",
+			run: func() (string, error) {
+				path, err := exec.LookPath("alembic")
+				if err != nil {
+					return "", fmt.Errorf("alembic not found on PATH")
+				}
+				return path, nil
+			},
+		},
+	}
+}
+
+// commandOutput runs a command and returns the first line of its output.
+func commandOutput(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), doctorCommandTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", fmt.Errorf("%s not found on PATH", name)
+	}
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("%s timed out after %s", name, doctorCommandTimeout)
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if err != nil {
+		if first != "" {
+			return "", fmt.Errorf("%s: %s", err, first)
+		}
+		return "", err
+	}
+	return first, nil
+}
+
+// printDiagnostics prints the doctor checklist.
+func printDiagnostics(report DiagnosticReport) {
+	width := 0
+	for _, r := range report.Checks {
+		width = max(width, len(r.Name))
+	}
+
+	fmt.Println()
+	for _, r := range report.Checks {
+		mark := "ok"
+		switch {
+		case !r.OK && r.Required:
+			mark = "FAIL"
+		case !r.OK:
+			mark = "warn"
+		}
+		fmt.Printf("  %-4s  %-*s  %s\n", mark, width, r.Name, r.Detail)
+		if r.Hint != "" {
+			fmt.Printf("        %-*s  → %s\n", width, "", r.Hint)
+		}
+	}
+	fmt.Println()
+
+	if report.OK {
+		fmt.Println("All required checks passed.")
+	} else {
+		fmt.Println("Some required checks failed; see the hints above.")
+	}
+}
@@ -1,6 +1,15 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +18,15 @@ import (
 type LogsOptions struct {
 	Follow bool
 	Tail   string
+	Since  string
+	Grep   string
+	Export string
+	DryRun bool
 }
 
+// maxLogLineBytes bounds the memory used for a single log line when filtering.
+const maxLogLineBytes = 1024 * 1024
+
 // NewLogsCommand creates a new logs command for viewing docker container logs
 func NewLogsCommand() *cobra.Command {
 	opts := &LogsOptions{}
@@ -37,7 +53,16 @@ Examples:
   ods logs --tail 100 api_server
 
   # View logs without following
-  ods logs --follow=false`,
+  ods logs --follow=false
+
+  # Only show lines matching a regular expression (works with --follow)
+  ods logs --grep 'ERROR|Traceback' api_server background
+
+  # Write each service's logs from the last hour to logs/<service>.log
+  ods logs --export logs --since 1h
+
+  # Show the docker command without running it
+  ods logs --tail 100 --dry-run api_server`,
 		Args: cobra.ArbitraryArgs,
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			return runningServiceNames(), cobra.ShellCompDirectiveNoFileComp
@@ -49,21 +74,215 @@ Examples:
 
 	cmd.Flags().BoolVar(&opts.Follow, "follow", true, "Follow log output")
 	cmd.Flags().StringVar(&opts.Tail, "tail", "", "Number of lines to show from the end of the logs (e.g. 100)")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only show logs since a timestamp (e.g. 2024-01-02T13:23:37Z) or relative duration (e.g. 42m)")
+	cmd.Flags().StringVar(&opts.Export, "export", "", "Write each service's logs to <dir>/<service>.log instead of streaming them")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the docker command without running it")
+	addLocalOverrideFlag(cmd)
+	cmd.Flags().StringVar(&opts.Grep, "grep", "", "Only show lines matching this regular expression, highlighting matches (use (?i) for case-insensitive)")
 
 	return cmd
 }
 
 func runComposeLogs(services []string, opts *LogsOptions) {
+	if opts.Export != "" {
+		exportComposeLogs(services, opts)
+		return
+	}
+
 	args := baseArgs("")
 	args = append(args, "logs")
 	if opts.Follow {
 		args = append(args, "-f")
 	}
+	args = append(args, logRangeArgs(opts)...)
+	args = append(args, services...)
+
+	if opts.DryRun {
+		printDockerCompose(args, nil)
+		if opts.Grep != "" {
+			log.Infof("Dry run: output would be filtered by %q", opts.Grep)
+		}
+		return
+	}
+
+	if opts.Grep == "" {
+		log.Info("Viewing container logs...")
+		execDockerCompose(args, nil)
+		return
+	}
+
+	pattern, err := regexp.Compile(opts.Grep)
+	if err != nil {
+		log.Fatalf("Invalid --grep pattern: %v", err)
+	}
+
+	log.Infof("Viewing container logs matching %q...", opts.Grep)
+	if err := grepDockerCompose(args, pattern, os.Stdout); err != nil {
+		log.Fatalf("Docker compose failed: %v", err)
+	}
+}
+
+// logRangeArgs returns the docker compose logs flags for --tail and --since.
+func logRangeArgs(opts *LogsOptions) []string {
+	var args []string
 	if opts.Tail != "" {
 		args = append(args, "--tail", opts.Tail)
 	}
-	args = append(args, services...)
+	if opts.Since != "" {
+		args = append(args, "--since", opts.Since)
+	}
+	return args
+}
+
+// exportComposeLogs writes the logs of each service (default: every running
+// service) to <dir>/<service>.log, without following.
+func exportComposeLogs(services []string, opts *LogsOptions) {
+	if opts.Grep != "" {
+		log.Fatal("--grep cannot be used with --export")
+	}
+
+	if len(services) == 0 {
+		services = runningServiceNames()
+		if len(services) == 0 {
+			log.Fatal("No running services found to export logs from")
+		}
+	}
+
+	if !opts.DryRun {
+		if err := os.MkdirAll(opts.Export, 0755); err != nil {
+			log.Fatalf("Failed to create export directory: %v", err)
+		}
+	}
 
-	log.Info("Viewing container logs...")
-	execDockerCompose(args, nil)
+	failed := 0
+	for _, service := range services {
+		args := baseArgs("")
+		args = append(args, "logs", "--no-color")
+		args = append(args, logRangeArgs(opts)...)
+		args = append(args, service)
+
+		path := filepath.Join(opts.Export, service+".log")
+		if opts.DryRun {
+			printDockerCompose(args, nil)
+			log.Infof("Dry run: output would be written to %s", path)
+			continue
+		}
+
+		if err := writeDockerComposeOutput(args, path); err != nil {
+			log.Errorf("Failed to export logs for %s: %v", service, err)
+			failed++
+			continue
+		}
+		log.Infof("Wrote %s", path)
+	}
+
+	if failed > 0 {
+		log.Fatalf("Failed to export logs for %d of %d service(s)", failed, len(services))
+	}
+}
+
+// writeDockerComposeOutput runs a docker compose command with its standard
+// output written to path.
+func writeDockerComposeOutput(args []string, path string) (err error) {
+	log.Debugf("Running: docker %v", args)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	dockerCmd := exec.Command("docker", args...)
+	dockerCmd.Dir = composeDir()
+	dockerCmd.Stdout = f
+	dockerCmd.Stderr = os.Stderr
+	return dockerCmd.Run()
+}
+
+// grepDockerCompose runs a docker compose command and streams only the output
+// lines matching pattern to w, line by line, so memory stays bounded even
+// when following logs indefinitely.
+func grepDockerCompose(args []string, pattern *regexp.Regexp, w io.Writer) error {
+	log.Debugf("Running: docker %v", args)
+
+	dockerCmd := exec.Command("docker", args...)
+	dockerCmd.Dir = composeDir()
+	dockerCmd.Stderr = os.Stderr
+	stdout, err := dockerCmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture docker output: %w", err)
+	}
+
+	if err := dockerCmd.Start(); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReaderSize(stdout, 64*1024)
+	warnedTruncated := false
+	for {
+		line, truncated, err := readLogLine(reader)
+		if truncated && !warnedTruncated {
+			log.Warnf("Truncating log lines longer than %d bytes", maxLogLineBytes)
+			warnedTruncated = true
+		}
+		if (err == nil || line != "") && pattern.MatchString(line) {
+			_, _ = fmt.Fprintln(w, highlightMatches(line, pattern))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Warnf("Stopped reading logs: %v", err)
+			// Drain anything left so docker never blocks on a full pipe
+			_, _ = io.Copy(io.Discard, stdout)
+			break
+		}
+	}
+
+	return dockerCmd.Wait()
+}
+
+// readLogLine reads one line from r without its line ending. At most
+// maxLogLineBytes of it are kept and the rest is discarded, reported by
+// truncated, so a single huge line neither exhausts memory nor stops the
+// filter.
+func readLogLine(r *bufio.Reader) (line string, truncated bool, err error) {
+	const limit = maxLogLineBytes + len("\r\n")
+	var buf []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		room := limit - len(buf)
+		if len(chunk) > room {
+			chunk, truncated = chunk[:room], true
+		}
+		buf = append(buf, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		buf = bytes.TrimSuffix(buf, []byte("\n"))
+		buf = bytes.TrimSuffix(buf, []byte("\r"))
+		if len(buf) > maxLogLineBytes {
+			buf, truncated = buf[:maxLogLineBytes], true
+		}
+		return string(buf), truncated, err
+	}
+}
+
+// highlightMatches wraps each match of pattern in bold red when color output
+// is enabled.
+func highlightMatches(line string, pattern *regexp.Regexp) string {
+	if !colorOutput {
+		return line
+	}
+	return pattern.ReplaceAllStringFunc(line, func(m string) string {
+		if m == "" {
+			return m
+		}
+		return "\033[1;31m" + m + "\033[0m"
+	})
 }
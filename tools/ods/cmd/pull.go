@@ -1,13 +1,19 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 // PullOptions holds options for the pull command.
 type PullOptions struct {
-	Tag string
+	Tag      string
+	Platform string
+	Parallel int
+	DryRun   bool
 }
 
 // NewPullCommand creates a new pull command for pulling docker images
@@ -15,7 +21,7 @@ func NewPullCommand() *cobra.Command {
 	opts := &PullOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "pull",
+		Use:   "pull [service...]",
 		Short: "Pull images for Onyx docker containers",
 		Long: `Pull the latest images for Onyx docker containers.
 
@@ -24,23 +30,60 @@ Examples:
   ods pull
 
   # Pull images with a specific tag
-  ods pull --tag edge`,
-		Args: cobra.NoArgs,
+  ods pull --tag edge
+
+  # Pull arm64 images on Apple Silicon
+  ods pull --platform linux/arm64
+
+  # Refresh only the model server image
+  ods pull inference_model_server
+
+  # Limit concurrent image pulls on a slow connection
+  ods pull --parallel 2
+
+  # Show the docker command without pulling
+  ods pull --tag edge --dry-run`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return runningServiceNames(), cobra.ShellCompDirectiveNoFileComp
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			runComposePull(opts)
+			runComposePull(args, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Set the IMAGE_TAG for docker compose (e.g. edge, v2.10.4)")
+	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Set the IMAGE_TAG for docker compose (e.g. edge, v2.10.4); defaults to the tag in .ods-image-tag at the repo root, then an exported or .env IMAGE_TAG")
+	cmd.Flags().StringVar(&opts.Platform, "platform", "", "Set DOCKER_DEFAULT_PLATFORM for docker compose (e.g. linux/amd64, linux/arm64)")
+	cmd.Flags().IntVar(&opts.Parallel, "parallel", 0, "Maximum number of images to pull concurrently (0 = docker compose default)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Print the docker command without running it")
+	addLocalOverrideFlag(cmd)
 
 	return cmd
 }
 
-func runComposePull(opts *PullOptions) {
+func runComposePull(services []string, opts *PullOptions) {
+	if opts.Parallel < 0 {
+		log.Fatal("--parallel must not be negative")
+	}
+
 	args := baseArgs("")
 	args = append(args, "pull")
+	args = append(args, services...)
+
+	env := append(envForTag(opts.Tag), envForPlatform(opts.Platform)...)
+	if opts.Parallel > 0 {
+		env = append(env, fmt.Sprintf("COMPOSE_PARALLEL_LIMIT=%d", opts.Parallel))
+	}
 
-	log.Info("Pulling images...")
-	execDockerCompose(args, envForTag(opts.Tag))
+	if opts.DryRun {
+		printDockerCompose(args, env)
+		return
+	}
+
+	if len(services) > 0 {
+		log.Infof("Pulling images for: %s", strings.Join(services, ", "))
+	} else {
+		log.Info("Pulling images...")
+	}
+	execDockerCompose(args, env)
 	log.Info("Images pulled successfully")
 }
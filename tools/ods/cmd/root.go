@@ -2,19 +2,27 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// Version and Commit describe the build. They are set from main, which
+// receives them via -ldflags -X at build time.
 var (
-	Version string
-	Commit  string
+	Version = "dev"
+	Commit  = "none"
 )
 
+// colorOutput reports whether decorated, colored terminal output should be
+// used. It is resolved once in the root command's PersistentPreRun.
+var colorOutput = true
+
 // RootOptions holds options for the root command
 type RootOptions struct {
-	Debug bool
+	Debug   bool
+	NoColor bool
 }
 
 // NewRootCommand creates the root command
@@ -31,25 +39,31 @@ func NewRootCommand() *cobra.Command {
 			} else {
 				log.SetLevel(log.InfoLevel)
 			}
+			colorOutput = shouldUseColor(opts.NoColor)
 			log.SetFormatter(&log.TextFormatter{
 				DisableTimestamp: true,
+				DisableColors:    !colorOutput,
 			})
 		},
 		Version: fmt.Sprintf("%s\ncommit %s", Version, Commit),
 	}
 
 	cmd.PersistentFlags().BoolVar(&opts.Debug, "debug", false, "run in debug mode")
+	cmd.PersistentFlags().BoolVar(&opts.NoColor, "no-color", false, "disable colored and decorated output (also honors NO_COLOR)")
 
 	// Add subcommands
 	cmd.AddCommand(NewCheckLazyImportsCommand())
 	cmd.AddCommand(NewCherryPickCommand())
+	cmd.AddCommand(NewCompletionCommand())
 	cmd.AddCommand(NewDBCommand())
+	cmd.AddCommand(NewDoctorCommand())
 	cmd.AddCommand(NewOpenAPICommand())
 	cmd.AddCommand(NewComposeCommand())
 	cmd.AddCommand(NewLogsCommand())
 	cmd.AddCommand(NewPullCommand())
 	cmd.AddCommand(NewRunCICommand())
 	cmd.AddCommand(NewScreenshotDiffCommand())
+	cmd.AddCommand(NewVersionCommand())
 
 	return cmd
 }
@@ -57,3 +71,19 @@ func NewRootCommand() *cobra.Command {
 func rootCmd(cmd *cobra.Command, args []string) {
 	_ = cmd.Help()
 }
+
+// shouldUseColor returns false when --no-color is passed, the NO_COLOR
+// environment variable is set (https://no-color.org), or stdout is not a terminal.
+func shouldUseColor(noColor bool) bool {
+	if noColor {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/archive"
+)
+
+// artifactTokenEnv names the environment variable holding a bearer token for
+// downloading baseline archives from CI systems that require authentication.
+const artifactTokenEnv = "ODS_ARTIFACT_TOKEN"
+
+// archiveIdleTimeout is how long a baseline archive download may go without
+// receiving data, whether waiting for the response headers or for the body,
+// before it is abandoned as stalled. A slow download that keeps making
+// progress is never cut off.
+const archiveIdleTimeout = time.Minute
+
+// errDownloadStalled cancels a download once archiveIdleTimeout passes
+// without data.
+var errDownloadStalled = errors.New("download stalled")
+
+// archiveFormat returns the format of a --baseline that is an http(s) URL
+// of a .zip or .tar.gz archive, or false if it is not one.
+func archiveFormat(baseline string) (archive.Format, bool) {
+	u, err := url.Parse(baseline)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	return archive.FormatOf(u.Path)
+}
+
+// isArchiveURL reports whether baseline is an http(s) URL of an archive.
+func isArchiveURL(baseline string) bool {
+	_, ok := archiveFormat(baseline)
+	return ok
+}
+
+// downloadArchiveDir downloads the archive at archiveURL, extracts it into
+// a registered temp directory, and returns the directory to read
+// screenshots from (see archive.ContentRoot) along with the temp directory
+// to clean up.
+func downloadArchiveDir(archiveURL, prefix string) (contentDir, tmpDir string, err error) {
+	format, ok := archiveFormat(archiveURL)
+	if !ok {
+		return "", "", fmt.Errorf("%s is not a .zip or .tar.gz URL", archiveURL)
+	}
+
+	tmpDir, err = makeTempDir(prefix)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			removeTempDir(tmpDir)
+		}
+	}()
+
+	archivePath := filepath.Join(tmpDir, "archive."+string(format))
+	if err := downloadFile(archiveURL, archivePath); err != nil {
+		return "", "", err
+	}
+
+	extractDir := filepath.Join(tmpDir, "extracted")
+	if err := archive.ExtractFile(archivePath, format, extractDir); err != nil {
+		return "", "", fmt.Errorf("failed to extract %s: %w", archiveURL, err)
+	}
+	_ = os.Remove(archivePath)
+
+	contentDir, err = archive.ContentRoot(extractDir)
+	if err != nil {
+		return "", "", err
+	}
+	return contentDir, tmpDir, nil
+}
+
+// downloadFile fetches u to path, sending $ODS_ARTIFACT_TOKEN as a bearer
+// token when set.
+func downloadFile(u, path string) error {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if token := os.Getenv(artifactTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	log.Infof("Downloading %s ...", u)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = archiveIdleTimeout
+	client := &http.Client{Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return downloadError(ctx, u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		hint := ""
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			hint = fmt.Sprintf(" (set %s if the artifact requires authentication)", artifactTokenEnv)
+		}
+		return fmt.Errorf("failed to download %s: HTTP %s%s", u, strings.TrimSpace(resp.Status), hint)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	body := newIdleTimeoutReader(resp.Body, archiveIdleTimeout, func() { cancel(errDownloadStalled) })
+	defer body.stop()
+	if _, err := io.Copy(f, body); err != nil {
+		_ = f.Close()
+		return downloadError(ctx, u, err)
+	}
+	return f.Close()
+}
+
+// downloadError wraps a failed download of u, naming the timeout when
+// archiveIdleTimeout passing without data is what stopped it.
+func downloadError(ctx context.Context, u string, err error) error {
+	var netErr net.Error
+	if errors.Is(context.Cause(ctx), errDownloadStalled) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("failed to download %s: timed out after %s without receiving data", u, archiveIdleTimeout)
+	}
+	return fmt.Errorf("failed to download %s: %w", u, err)
+}
+
+// idleTimeoutReader calls onIdle once no data has been read from r for
+// timeout; every read that returns data restarts the clock.
+type idleTimeoutReader struct {
+	r       io.Reader
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+func newIdleTimeoutReader(r io.Reader, timeout time.Duration, onIdle func()) *idleTimeoutReader {
+	return &idleTimeoutReader{r: r, timeout: timeout, timer: time.AfterFunc(timeout, onIdle)}
+}
+
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
+}
+
+// stop disarms the timer once the download is over.
+func (r *idleTimeoutReader) stop() {
+	r.timer.Stop()
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+)
+
+// baselineCacheRef is the --baseline value that selects the locally cached
+// copy of the project's baseline instead of downloading it.
+const baselineCacheRef = "@cache"
+
+// defaultBaselineCacheDir returns the default location for cached baselines
+// (e.g. ~/.cache/ods/screenshot-baselines on Linux), or "" if the user cache
+// directory cannot be determined.
+func defaultBaselineCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ods", "screenshot-baselines")
+}
+
+// baselineCachePath returns the cache directory for a project's baseline at a revision.
+func baselineCachePath(cacheDir, project, rev string) string {
+	return filepath.Join(cacheDir, project, sanitizeRev(rev))
+}
+
+// cachedBaselineDir resolves --baseline @cache to the cached baseline for
+// project at rev, failing with a hint if nothing has been cached yet.
+func cachedBaselineDir(cacheDir, project, rev string) (string, error) {
+	if project == "" {
+		return "", fmt.Errorf("--baseline %s requires --project", baselineCacheRef)
+	}
+	if cacheDir == "" {
+		return "", fmt.Errorf("--baseline %s requires --cache-dir (no user cache directory found)", baselineCacheRef)
+	}
+
+	dir := baselineCachePath(cacheDir, project, rev)
+	has, err := imgdiff.HasScreenshots(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read baseline cache %s: %w", dir, err)
+	}
+	if !has {
+		return "", fmt.Errorf("no cached baseline for %s@%s in %s; run 'ods screenshot-diff compare --project %s' once while online to populate it",
+			project, rev, cacheDir, project)
+	}
+	return dir, nil
+}
+
+// updateBaselineCache replaces the cached baseline for project at rev with
+// the files in srcDir. The new copy is staged next to the old one and swapped
+// in, so an interrupted update never leaves a half-written cache.
+func updateBaselineCache(cacheDir, project, rev, srcDir string) error {
+	dest := baselineCachePath(cacheDir, project, rev)
+	staging := dest + ".tmp"
+
+	_ = os.RemoveAll(staging)
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", srcDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := copyFile(filepath.Join(srcDir, entry.Name()), filepath.Join(staging, entry.Name())); err != nil {
+			_ = os.RemoveAll(staging)
+			return err
+		}
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to clear old cache %s: %w", dest, err)
+	}
+	if err := os.Rename(staging, dest); err != nil {
+		return fmt.Errorf("failed to update cache %s: %w", dest, err)
+	}
+	return nil
+}
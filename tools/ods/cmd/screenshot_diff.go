@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/azure"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/filelock"
 	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/prompt"
 	"github.com/onyx-dot-app/onyx/tools/ods/internal/s3"
 )
 
@@ -27,6 +38,17 @@ const (
 
 	// DefaultRev is the default revision used when --rev is not specified.
 	DefaultRev = "main"
+
+	// DefaultBaselineTemplate is the default S3 layout of baselines; see
+	// --path-template.
+	DefaultBaselineTemplate = "s3://{bucket}/baselines/{project}/{rev}/"
+
+	// BaselineTemplateEnv names the environment variable that overrides
+	// DefaultBaselineTemplate when --path-template is not given.
+	BaselineTemplateEnv = "ODS_BASELINE_TEMPLATE"
+
+	// DefaultThreshold is the default per-channel pixel difference threshold.
+	DefaultThreshold = 0.2
 )
 
 // getS3Bucket returns the S3 bucket name, preferring the PLAYWRIGHT_S3_BUCKET
@@ -44,36 +66,234 @@ func sanitizeRev(rev string) string {
 	return strings.ReplaceAll(rev, "/", "-")
 }
 
+// baselineTemplate is the --path-template flag value, set before any
+// subcommand runs.
+var baselineTemplate string
+
+// getBaselineTemplate returns the S3 layout of baselines, preferring
+// --path-template over ODS_BASELINE_TEMPLATE over the default.
+func getBaselineTemplate() string {
+	if baselineTemplate != "" {
+		return baselineTemplate
+	}
+	if tmpl := os.Getenv(BaselineTemplateEnv); tmpl != "" {
+		return tmpl
+	}
+	return DefaultBaselineTemplate
+}
+
+// validateBaselineTemplate checks that a baseline layout is an s3:// URL with
+// {project} and exactly one {rev} path segment.
+func validateBaselineTemplate(tmpl string) error {
+	if !strings.HasPrefix(tmpl, "s3://") {
+		return fmt.Errorf("%q must start with s3://", tmpl)
+	}
+	if !strings.Contains(tmpl, "{project}") {
+		return fmt.Errorf("%q must contain {project}", tmpl)
+	}
+	if strings.Count(tmpl, "{rev}") != 1 {
+		return fmt.Errorf("%q must contain {rev} exactly once", tmpl)
+	}
+	if _, after, _ := strings.Cut(tmpl, "{rev}"); after != "" && !strings.HasPrefix(after, "/") {
+		return fmt.Errorf("%q: {rev} must be a whole path segment", tmpl)
+	}
+	return nil
+}
+
+// baselineS3URL returns the S3 prefix holding the baselines for a project at a revision.
+func baselineS3URL(bucket, project, rev string) string {
+	url := strings.NewReplacer("{bucket}", bucket, "{project}", project, "{rev}", sanitizeRev(rev)).Replace(getBaselineTemplate())
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	return url
+}
+
+// baselineRevisionPrefix splits the baseline layout for a project around
+// {rev}: the S3 prefix every revision shares, and the path from a revision
+// to its screenshots ("" in the default layout).
+func baselineRevisionPrefix(bucket, project string) (prefix, suffix string) {
+	before, after, _ := strings.Cut(getBaselineTemplate(), "{rev}")
+	r := strings.NewReplacer("{bucket}", bucket, "{project}", project)
+	suffix = strings.TrimPrefix(r.Replace(after), "/")
+	if suffix != "" && !strings.HasSuffix(suffix, "/") {
+		suffix += "/"
+	}
+	return r.Replace(before), suffix
+}
+
+// protectedRevs lists revisions whose baselines must not be deleted without --force.
+var protectedRevs = []string{DefaultRev}
+
+// isProtectedRev reports whether rev is a long-lived baseline (main, release
+// branches, version tags) rather than an ephemeral one such as "pr-1234".
+func isProtectedRev(rev string) bool {
+	rev = sanitizeRev(rev)
+	for _, p := range protectedRevs {
+		if rev == p {
+			return true
+		}
+	}
+	return strings.HasPrefix(rev, "release-") || (len(rev) > 1 && rev[0] == 'v' && rev[1] >= '0' && rev[1] <= '9')
+}
+
 // ScreenshotDiffCompareOptions holds options for the compare subcommand.
 type ScreenshotDiffCompareOptions struct {
-	Project      string
-	Rev          string // revision whose baseline to compare against (default: "main")
-	FromRev      string // cross-revision mode: source (older) revision
-	ToRev        string // cross-revision mode: target (newer) revision
+	Projects     []string // one or more projects; each is compared in turn
+	Project      string   // project currently being compared
+	Rev          string   // revision whose baseline to compare against (default: "main")
+	RevFallback  []string // revisions to try, in order, when the primary baseline is empty
+	FromRev      string   // cross-revision mode: source (older) revision
+	ToRev        string   // cross-revision mode: target (newer) revision
 	Baseline     string
 	Current      string
 	Output       string
 	Threshold    float64
 	MaxDiffRatio float64
+	GIFDir       string // optional directory for blink-comparator GIFs of changed screenshots
+	GIFDelay     int    // frame delay for blink GIFs, in milliseconds
+	SortBy       string // ordering of changed results: diff-percent, diff-pixels, or regions
+
+	UnchangedThumbnails bool   // render unchanged screenshots as a thumbnail gallery in the report
+	ReportMaxWidth      int    // downscale embedded report images to at most this width (0 = full size)
+	ReportMaxHeight     int    // downscale embedded report images to at most this height (0 = full size)
+	PNGCompression      string // compression for re-encoded PNGs: default, fast, or best
+	ReportImageFormat   string // encoding of images embedded in the report: png or webp
+	ReportMode          string // inline (data URIs) or external (files in images/ next to the report)
+
+	Crop    string // restrict comparison to "x,y,w,h"
+	CropTop int    // restrict comparison to everything below the top N pixels
+
+	KeepTemp       bool   // keep downloaded baseline/current directories for debugging
+	RequireCurrent bool   // fail when the current directory is missing or has no screenshots
+	Strict         bool   // turn misconfiguration warnings (e.g. baseline == current) into errors
+	IgnoreAlpha    bool   // compare RGB channels only
+	Mask           string // JSON file of regions to ignore or compare with their own threshold
+	RenameMap      string // JSON file mapping current filenames to the baseline filenames they replace
+	FromList       string // newline-separated screenshot paths to restrict the comparison to ("-" = stdin)
+	ChangedInGit   string // base ref: only compare screenshots whose sources (per SourceMap) changed since it
+	SourceMap      string // JSON file mapping source file patterns to the screenshots that depend on them
+	Dedupe         bool   // collapse current screenshots with identical pixels into one result
+	TrustMtime     bool   // treat pairs with the same size and mtime as unchanged without decoding
+	OverlayBase    string // what unchanged pixels show in the diff overlay: current, baseline, white, or black
+	Palette        string // diff highlight colors: default, deuteranopia, protanopia, or high-contrast
+
+	SVGDPI float64 // resolution .svg screenshots are rasterized at
+
+	IgnoreScrollbar       int  // ignore the rightmost N pixel columns (scrollbar rendering differs across OSes)
+	IgnoreScrollbarBottom bool // also ignore the bottom N pixel rows
+
+	Sidecars bool // read <name>.json sidecars for the URL and viewport shown on report cards
+
+	MinRegionPixels int // only mark a screenshot changed when a connected diff cluster has at least this many pixels
+
+	FailOnBlank bool // report pairs where either image is a single flat color as errors
+
+	OnTraverseError string // what to do when a screenshot or project directory can't be read: fail or skip
+
+	FailFast   bool     // stop at the first screenshot with a FailFastOn status and exit non-zero
+	FailFastOn []string // statuses that trigger --fail-fast
+
+	ReportTitle   string // report <title> and heading; {project} is replaced with the project name
+	ReportFavicon bool   // embed a green/red pass/fail favicon in the report
+
+	ReportCollapseBelow float64 // render changed cards under this diff percentage collapsed
+
+	ReportEmbedBaseline bool // embed baseline images of changed screenshots in the report
+
+	NDJSON bool // stream one JSON object per compared screenshot to stdout instead of the summary box
+
+	ProfileTimings bool // log how long each phase took and record it in summary.json
+
+	Concurrency int // screenshot pairs to compare at once
+
+	ComparePremultiplied bool // compare alpha-premultiplied channels (false = divide out alpha first)
+
+	Verify bool // check downloaded S3 objects against the listing and re-fetch mismatches
+
+	ThresholdAbs float64 // per-channel cutoff in 8-bit units, replacing Threshold (-1 = unset)
+
+	CleanOutput bool // empty the report's directory before writing and prune empty directories after
+
+	Nested bool // treat each first-level subdirectory as a project (<dir>/<project>/<screenshot>)
+
+	NormalizeDPR bool // scale the larger image down when sizes differ by an integer factor
+
+	ImagesDir  string // where changed/added/removed images and diff overlays are saved (or read with ReportOnly)
+	ReportOnly bool   // regenerate the report from Summary and ImagesDir without comparing
+	Summary    string // summary.json (local path or s3://) to rebuild the report from with ReportOnly
+	Append     bool   // merge into an existing summary.json and report the combined results (sharded runs)
+
+	BaselineSummary string        // previous run's summary.json (local path or s3://) to report deltas against
+	CSV             string        // optional path for a per-screenshot CSV export
+	CacheDir        string        // local copy of downloaded baselines, used by --baseline @cache
+	StaleAfter      time.Duration // warn when the newest S3 baseline object is older than this (0 = off)
 }
 
 // ScreenshotDiffUploadOptions holds options for the upload-baselines subcommand.
 type ScreenshotDiffUploadOptions struct {
+	Project  string
+	Rev      string // revision to store the baseline under (default: "main")
+	Dir      string
+	Dest     string
+	Delete   bool
+	CacheDir string // local copy of the uploaded baseline, used by compare --baseline @cache
+}
+
+// ScreenshotDiffAcceptOptions holds options for the accept subcommand.
+type ScreenshotDiffAcceptOptions struct {
+	Project   string
+	Rev       string // revision whose baseline to update (default: "main")
+	Baseline  string
+	Current   string
+	Threshold float64
+	Names     []string // only accept screenshots whose filename matches one of these globs
+	Yes       bool
+
+	ThresholdAbs float64 // per-channel cutoff in 8-bit units, replacing Threshold (-1 = unset)
+
+	// Comparison flags shared with compare, so accept picks the same
+	// screenshots as changed that compare reported
+	Mask                  string
+	RenameMap             string
+	Crop                  string
+	CropTop               int
+	IgnoreAlpha           bool
+	ComparePremultiplied  bool
+	SVGDPI                float64
+	IgnoreScrollbar       int
+	IgnoreScrollbarBottom bool
+	MinRegionPixels       int
+	NormalizeDPR          bool
+	FailOnBlank           bool
+}
+
+// ScreenshotDiffCleanupOptions holds options for the cleanup subcommand.
+type ScreenshotDiffCleanupOptions struct {
 	Project string
-	Rev     string // revision to store the baseline under (default: "main")
-	Dir     string
-	Dest    string
-	Delete  bool
+	Rev     string // revision whose baseline prefix should be deleted (e.g. "pr-1234")
+	Yes     bool
+	Force   bool // allow deleting protected revisions such as "main"
+}
+
+// ScreenshotDiffAWSOptions holds the AWS flags shared by every screenshot-diff
+// subcommand.
+type ScreenshotDiffAWSOptions struct {
+	Profile string
+	Region  string
 }
 
 // NewScreenshotDiffCommand creates the screenshot-diff command with subcommands.
 func NewScreenshotDiffCommand() *cobra.Command {
+	awsOpts := &ScreenshotDiffAWSOptions{}
+
 	cmd := &cobra.Command{
 		Use:   "screenshot-diff",
 		Short: "Visual regression testing for Playwright screenshots",
 		Long: `Compare Playwright screenshots against baselines and generate visual diff reports.
 
-Supports comparing local directories and downloading baselines from S3.
+Supports comparing local directories and downloading baselines from S3
+or Azure Blob Storage (az://<account>/<container>/<path>).
 The generated HTML report is self-contained (images base64-inlined) and can
 be opened locally or hosted on S3.
 
@@ -81,6 +301,9 @@ Baselines are stored per-project and per-revision in S3:
 
   s3://<bucket>/baselines/<project>/<rev>/
 
+Buckets with a different layout can set --path-template (or
+ODS_BASELINE_TEMPLATE), e.g. 's3://{bucket}/{rev}/{project}/snapshots/'.
+
 The --project flag provides sensible defaults so you don't need to specify
 every path. For example:
 
@@ -99,16 +322,44 @@ every path. For example:
   # Upload baselines for a release branch
   ods screenshot-diff upload-baselines --project admin --rev release/2.5
 
+  # Store ephemeral per-PR baselines, then delete them once the PR merges
+  ods screenshot-diff upload-baselines --project admin --rev pr-1234
+  ods screenshot-diff cleanup --project admin --rev pr-1234
+
 You can override any default with explicit flags:
 
   ods screenshot-diff compare --baseline ./my-baselines --current ./my-screenshots`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// Cobra only runs the nearest PersistentPreRun, so chain to the
+			// root's to keep --debug and --no-color working.
+			if root := cmd.Root(); root.PersistentPreRun != nil {
+				root.PersistentPreRun(cmd, args)
+			}
+			s3.Configure(s3.Config{Profile: awsOpts.Profile, Region: awsOpts.Region})
+			if err := validateBaselineTemplate(getBaselineTemplate()); err != nil {
+				log.Fatalf("Invalid baseline path template: %v", err)
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = cmd.Help()
 		},
 	}
 
+	cmd.PersistentFlags().StringVar(&awsOpts.Profile, "aws-profile", "", "AWS profile for S3 operations (default: AWS_PROFILE or the ambient AWS config)")
+	cmd.PersistentFlags().StringVar(&baselineTemplate, "path-template", "", "S3 layout of baselines, with {bucket}, {project}, and {rev} placeholders (default: "+BaselineTemplateEnv+" or "+DefaultBaselineTemplate+")")
+	cmd.PersistentFlags().StringVar(&awsOpts.Region, "aws-region", "", "AWS region for S3 operations (default: AWS_REGION or the ambient AWS config)")
+
 	cmd.AddCommand(newCompareCommand())
 	cmd.AddCommand(newUploadBaselinesCommand())
+	cmd.AddCommand(newAcceptCommand())
+	cmd.AddCommand(newCleanupCommand())
+	cmd.AddCommand(newHistoryCommand())
+	cmd.AddCommand(newCalibrateCommand())
+	cmd.AddCommand(newCompare3Command())
+	cmd.AddCommand(newServeCommand())
+	cmd.AddCommand(newDiffOneCommand())
+	cmd.AddCommand(newBadgeCommand())
+	cmd.AddCommand(newGCCommand())
 
 	return cmd
 }
@@ -141,10 +392,20 @@ are no visual differences, the HTML report is skipped.
 CROSS-REVISION MODE:
 
 Use --from-rev and --to-rev to compare two stored revisions directly.
-Both sides are downloaded from S3 — no local screenshots are needed.
+Both sides are downloaded from S3 — no local screenshots are needed. A
+revision with nothing stored (e.g. a typo) is an error rather than a report
+of every screenshot added or removed.
 
   ods screenshot-diff compare --project admin --from-rev v1.0.0 --to-rev v2.0.0
 
+OFFLINE MODE:
+
+Baselines downloaded by compare (or pushed by upload-baselines) for a
+project's default baseline are cached under --cache-dir. Pass
+--baseline @cache to compare against that copy without touching S3.
+
+  ods screenshot-diff compare --project admin --baseline @cache
+
 Examples:
 
   # Compare local screenshots against main (default)
@@ -153,12 +414,66 @@ Examples:
   # Compare against a specific revision
   ods screenshot-diff compare --project admin --rev release/2.5
 
+  # Compare a freshly-cut branch, falling back to main until it has baselines
+  ods screenshot-diff compare --project admin --rev release/2.6 --rev-fallback main
+
+  # Compare several projects in one run (exits non-zero if any project fails)
+  ods screenshot-diff compare --project admin,chat,web
+
   # Compare two revisions
   ods screenshot-diff compare --project admin --from-rev v1.0.0 --to-rev v2.0.0
 
   # Override specific flags
   ods screenshot-diff compare --project admin --current ./custom-dir/
 
+  # Ignore a fixed 80px header on every page
+  ods screenshot-diff compare --project admin --crop-top 80
+
+  # Fail CI if the capture step produced no screenshots
+  ods screenshot-diff compare --project admin --require-current
+
+  # Keep the report small when screenshots are 4K full-page captures
+  ods screenshot-diff compare --project admin --report-max-width 1600
+
+  # Ignore or loosen specific regions via a mask file
+  ods screenshot-diff compare --project admin --mask ./screenshot-mask.json
+
+  # Show the baseline (rather than the current image) behind diff highlights
+  ods screenshot-diff compare --project admin --overlay-base baseline
+
+  # Ignore a 15px scrollbar strip when baselines come from another OS
+  ods screenshot-diff compare --project admin --ignore-scrollbar 15
+
+  # Ignore scattered anti-aliasing noise, but not a solid 5x5 change
+  ods screenshot-diff compare --project admin --min-region-pixels 25
+
+  # Just answer "did anything change?" as fast as possible (e.g. while bisecting)
+  ods screenshot-diff compare --project admin --fail-fast
+
+  # Compare working-tree screenshots against the committed ones, without S3
+  ods screenshot-diff compare --current ./web/tests/screenshots --baseline git:HEAD
+
+  # Pre-commit check: uncommitted screenshot changes vs. HEAD
+  ods screenshot-diff compare --current git:worktree:web/tests/screenshots
+
+  # Report a page captured twice (e.g. under a retry suffix) only once
+  ods screenshot-diff compare --project admin --dedupe
+
+  # Keep comparing screenshots whose tests were renamed
+  ods screenshot-diff compare --project admin --rename-map ./screenshot-renames.json
+
+  # Stream per-screenshot results as newline-delimited JSON for a live UI
+  ods screenshot-diff compare --project admin --ndjson | my-ci-renderer
+
+  # Name the report after the app, with a pass/fail favicon for the browser tab
+  ods screenshot-diff compare --project admin --report-title "Admin UI ({project})" --report-favicon
+
+  # Collapse changes under 0.5% so the big regressions stand out
+  ods screenshot-diff compare --project admin --report-collapse-below 0.5
+
+  # Also export animated before/after GIFs for changed screenshots
+  ods screenshot-diff compare --project admin --gif-dir ./gifs/
+
   # Fully manual (no project flag)
   ods screenshot-diff compare \
     --baseline s3://my-bucket/baselines/admin/main/ \
@@ -169,15 +484,71 @@ Examples:
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin); sets sensible defaults for baseline, current, and output")
+	cmd.Flags().StringSliceVar(&opts.Projects, "project", nil, "Project name(s) (e.g. admin or admin,chat); sets sensible defaults for baseline, current, and output")
 	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision to compare against (default: main). Ignored when --from-rev/--to-rev are set")
 	cmd.Flags().StringVar(&opts.FromRev, "from-rev", "", "Source (older) revision for cross-revision comparison")
 	cmd.Flags().StringVar(&opts.ToRev, "to-rev", "", "Target (newer) revision for cross-revision comparison")
-	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "Baseline directory or S3 URL (s3://...)")
-	cmd.Flags().StringVar(&opts.Current, "current", "", "Current screenshots directory or S3 URL (s3://...)")
+	cmd.Flags().StringSliceVar(&opts.RevFallback, "rev-fallback", nil, "Revisions to fall back to, in order, when the baseline revision has no screenshots in S3 (e.g. main)")
+	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "Baseline directory, S3 URL (s3://...), Azure Blob URL (az://...), @cache for the locally cached baseline, git:<ref>[:<dir>] for screenshots committed at a git ref, or an http(s) URL of a .zip or .tar.gz archive")
+	cmd.Flags().StringVar(&opts.Current, "current", "", "Current screenshots directory, S3 URL (s3://...), Azure Blob URL (az://...), or git:worktree[:<dir>] for the git working tree (baseline defaults to git:HEAD)")
 	cmd.Flags().StringVar(&opts.Output, "output", "", "Output path for the HTML report")
-	cmd.Flags().Float64Var(&opts.Threshold, "threshold", 0.2, "Per-channel pixel difference threshold (0.0-1.0)")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", DefaultThreshold, "Per-channel pixel difference threshold (0.0-1.0)")
+	cmd.Flags().Float64Var(&opts.ThresholdAbs, "threshold-abs", -1, "Per-channel pixel difference threshold in 8-bit units (0-255), instead of --threshold; --threshold T equals --threshold-abs T*255 (-1 = use --threshold)")
+	cmd.MarkFlagsMutuallyExclusive("threshold", "threshold-abs")
 	cmd.Flags().Float64Var(&opts.MaxDiffRatio, "max-diff-ratio", 0.01, "Max diff pixel ratio before marking as changed (informational)")
+	cmd.Flags().StringVar(&opts.SortBy, "sort-by", string(imgdiff.SortByDiffPercent), "Order changed screenshots by diff-percent, diff-pixels, or regions (number of changed clusters)")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", "JSON file of regions to ignore, or to compare with a per-region threshold")
+	cmd.Flags().StringVar(&opts.ChangedInGit, "compare-only-changed-in-git", "", "Only compare the screenshots whose source files (per --source-map) changed between this base ref and HEAD")
+	cmd.Flags().StringVar(&opts.SourceMap, "source-map", "", "JSON file mapping source file patterns to the screenshots that depend on them, for --compare-only-changed-in-git")
+	cmd.Flags().StringVar(&opts.FromList, "from-list", "", "Only compare the screenshots named in this file, one path per line (- reads stdin; with --nested, the parent directory names the project); listed names missing from current are removed, missing from the baseline added")
+	cmd.Flags().StringVar(&opts.RenameMap, "rename-map", "", "JSON file mapping current screenshot filenames to baseline filenames, so renamed screenshots are still compared")
+	cmd.Flags().BoolVar(&opts.Dedupe, "dedupe", false, "Collapse current screenshots with identical pixels (e.g. retry captures) into one result listing the other names")
+	cmd.Flags().BoolVar(&opts.TrustMtime, "trust-mtime", false, "Treat a screenshot as unchanged without decoding it when the baseline and current files have the same size and modification time (faster, but misses rewrites that keep both)")
+	cmd.Flags().StringVar(&opts.OverlayBase, "overlay-base", string(imgdiff.OverlayBaseCurrent), "What unchanged pixels show in the diff overlay: current or baseline (dimmed), or white or black")
+	cmd.Flags().StringVar(&opts.Palette, "palette", string(imgdiff.PaletteDefault), "Diff highlight colors: default (magenta), deuteranopia (orange), protanopia (sky blue), or high-contrast (cyan)")
+	cmd.Flags().BoolVar(&opts.FailFast, "fail-fast", false, "Stop at the first difference and exit non-zero, skipping the report (for quick yes/no checks such as bisecting)")
+	cmd.Flags().StringVar(&opts.OnTraverseError, "on-traverse-error", string(imgdiff.TraverseErrorFail), "What to do when a screenshot or project directory can't be read: fail the run, or skip it and report it as an error")
+	cmd.Flags().StringSliceVar(&opts.FailFastOn, "fail-fast-on", []string{"changed", "added", "removed"}, "Statuses that stop a --fail-fast run (changed, added, removed)")
+	cmd.Flags().BoolVar(&opts.IgnoreAlpha, "ignore-alpha", false, "Compare RGB channels only, ignoring differences in alpha")
+	cmd.Flags().BoolVar(&opts.ComparePremultiplied, "compare-alpha-premultiplied", true, "Compare alpha-premultiplied channels, which under-reports color changes on translucent pixels; set to false to compare true colors")
+	cmd.Flags().Float64Var(&opts.SVGDPI, "svg-dpi", imgdiff.DefaultSVGDPI, "Resolution .svg screenshots are rasterized at before comparing (needs rsvg-convert or resvg)")
+	cmd.Flags().StringVar(&opts.ImagesDir, "images-dir", "", "Save the images of changed, added, and removed screenshots (and their diff overlays) here, so a later --report-only run can rebuild the report")
+	cmd.Flags().BoolVar(&opts.ReportOnly, "report-only", false, "Regenerate the report from --summary and --images-dir without comparing any screenshots")
+	cmd.Flags().BoolVar(&opts.Append, "append", false, "Merge this run's results into the summary.json already in the output directory and report the combined results, for sharded CI runs (needs --images-dir)")
+	cmd.Flags().StringVar(&opts.Summary, "summary", "", "With --report-only, the summary.json of the earlier compare (path or s3://...)")
+	cmd.Flags().BoolVar(&opts.NormalizeDPR, "normalize-dpr", false, "When one screenshot is an integer multiple of the other's size (a device pixel ratio change), scale the larger down before comparing")
+	cmd.Flags().IntVar(&opts.IgnoreScrollbar, "ignore-scrollbar", 0, "Ignore the rightmost N pixels of every screenshot, where scrollbars render differently across platforms")
+	cmd.Flags().BoolVar(&opts.IgnoreScrollbarBottom, "ignore-scrollbar-bottom", false, "With --ignore-scrollbar, also ignore the bottom N pixels (horizontal scrollbars)")
+	cmd.Flags().IntVar(&opts.MinRegionPixels, "min-region-pixels", 0, "Only mark a screenshot changed when a connected cluster of differing pixels has at least this many pixels")
+	cmd.Flags().BoolVar(&opts.FailOnBlank, "fail-on-blank", false, "Report a screenshot as an error instead of comparing it when the baseline or current image is a single flat color (e.g. an all-black or transparent failed capture)")
+	cmd.Flags().BoolVar(&opts.Sidecars, "sidecars", false, "Read each screenshot's JSON sidecar (page.json or page.png.json) and show its URL and viewport on the report card")
+	cmd.Flags().StringVar(&opts.Crop, "crop", "", "Only compare this region of every screenshot, as x,y,w,h in pixels")
+	cmd.Flags().IntVar(&opts.CropTop, "crop-top", 0, "Ignore the top N pixels of every screenshot (e.g. a fixed header)")
+	cmd.Flags().BoolVar(&opts.UnchangedThumbnails, "unchanged-thumbnails", false, "Show unchanged screenshots as a thumbnail gallery in the report (increases report size)")
+	cmd.Flags().IntVar(&opts.ReportMaxWidth, "report-max-width", 0, "Downscale images embedded in the report to at most this width in pixels (0 = full size)")
+	cmd.Flags().IntVar(&opts.ReportMaxHeight, "report-max-height", 0, "Downscale images embedded in the report to at most this height in pixels (0 = full size)")
+	cmd.Flags().StringVar(&opts.ReportImageFormat, "report-image-format", string(imgdiff.ImageFormatPNG), "Encoding for images embedded in the report: png, or webp for a much smaller report (needs a modern browser)")
+	cmd.Flags().StringVar(&opts.ReportMode, "report-mode", string(imgdiff.ReportModeInline), "Where report images go: inline (self-contained HTML) or external (an images/ directory next to the report, linked with relative paths)")
+	cmd.Flags().StringVar(&opts.PNGCompression, "png-compression", string(imgdiff.PNGCompressionDefault), "Compression for PNGs encoded into the report and diff overlays saved to --images-dir: default, fast, or best")
+	cmd.Flags().StringVar(&opts.ReportTitle, "report-title", imgdiff.DefaultReportTitle, "Title and heading of the HTML report; {project} is replaced with the project name")
+	cmd.Flags().BoolVar(&opts.ReportEmbedBaseline, "report-embed-baseline", true, "Include the baseline images of changed screenshots in the report; set to false to show only current and the diff overlay, for a smaller report")
+	cmd.Flags().Float64Var(&opts.ReportCollapseBelow, "report-collapse-below", 0, "Render changed screenshots that differ by less than this percentage collapsed, expanding on click (0 = expand all)")
+	cmd.Flags().BoolVar(&opts.ReportFavicon, "report-favicon", false, "Embed a green/red favicon reflecting pass/fail, visible in the browser tab")
+	cmd.Flags().BoolVar(&opts.NDJSON, "ndjson", false, "Stream one JSON object per screenshot to stdout as each comparison finishes (replaces the terminal summary)")
+	cmd.Flags().BoolVar(&opts.Nested, "nested", false, "Compare <baseline>/<project>/ against <current>/<project>/ for every project subdirectory (e.g. Playwright multi-project output) in one run, grouping the report and summary by project")
+	cmd.Flags().BoolVar(&opts.CleanOutput, "clean-output", false, "Delete an earlier run's report, summary.json, images/, and any --csv, --gif-dir, or --images-dir output inside the report's directory before writing fresh results")
+	cmd.Flags().BoolVar(&opts.Verify, "verify", false, "After downloading from S3, check each file's size and MD5 against the bucket listing and re-fetch mismatches")
+	cmd.Flags().IntVar(&opts.Concurrency, "concurrency", 1, "Number of screenshots to decode and compare (and encode for the report) at once; results are still reported in the same order")
+	cmd.Flags().BoolVar(&opts.ProfileTimings, "profile-timings", false, "Log how long each phase (download, decode, compare, encode, report) took and record the breakdown in summary.json")
+	cmd.Flags().StringVar(&opts.CSV, "csv", "", "Also write per-screenshot results as CSV to this path")
+	cmd.Flags().StringVar(&opts.BaselineSummary, "baseline-summary", "", "Previous run's summary.json (path or s3://...) to report new regressions and fixes against; {project} is replaced with the project name")
+	cmd.Flags().BoolVar(&opts.RequireCurrent, "require-current", false, "Fail if the current screenshots directory is missing or empty (default: write an empty summary)")
+	cmd.Flags().StringVar(&opts.CacheDir, "cache-dir", defaultBaselineCacheDir(), "Directory where downloaded baselines are cached for --baseline @cache")
+	cmd.Flags().DurationVar(&opts.StaleAfter, "stale-after", 0, "Warn if the S3 baseline was last updated longer ago than this (e.g. 720h for 30 days; 0 = off)")
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false, "Fail instead of warning on suspicious setups, such as --baseline and --current being the same directory")
+	cmd.Flags().BoolVar(&opts.KeepTemp, "keep-temp", false, "Keep downloaded baseline/current temp directories and log their paths (for debugging)")
+	cmd.Flags().StringVar(&opts.GIFDir, "gif-dir", "", "Write an animated baseline/current GIF for each changed screenshot to this directory")
+	cmd.Flags().IntVar(&opts.GIFDelay, "gif-delay", imgdiff.DefaultGIFDelayMs, "Frame delay for --gif-dir GIFs, in milliseconds")
 
 	return cmd
 }
@@ -227,36 +598,153 @@ Examples:
 	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin); sets sensible defaults for dir and dest")
 	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision to store the baseline under (default: main)")
 	cmd.Flags().StringVar(&opts.Dir, "dir", "", "Local directory containing screenshots to upload")
-	cmd.Flags().StringVar(&opts.Dest, "dest", "", "S3 destination URL (s3://...)")
-	cmd.Flags().BoolVar(&opts.Delete, "delete", false, "Delete S3 files not present locally")
+	cmd.Flags().StringVar(&opts.Dest, "dest", "", "Destination S3 URL (s3://...) or Azure Blob URL (az://...)")
+	cmd.Flags().BoolVar(&opts.Delete, "delete", false, "Delete files at --dest (S3 or Azure) not present locally")
+	cmd.Flags().StringVar(&opts.CacheDir, "cache-dir", defaultBaselineCacheDir(), "Directory where uploaded baselines are cached for compare --baseline @cache")
+
+	return cmd
+}
+
+func newAcceptCommand() *cobra.Command {
+	opts := &ScreenshotDiffAcceptOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "accept",
+		Short: "Accept current screenshots as the new baseline",
+		Long: `Bless the current screenshots as the new baseline, similar to
+updating snapshots in Jest.
+
+The current screenshots are compared against the baseline first, with the
+same comparison flags compare takes (--mask, --crop, --ignore-alpha, ...),
+and the pending changes are printed. Only changed and added screenshots
+are then copied over the baseline (or uploaded, if the baseline is an S3
+URL); unchanged files are left alone and removed files are not deleted.
+
+When --project is specified, the following defaults are applied:
+  --baseline  → s3://<bucket>/baselines/<project>/<rev>/
+  --current   → web/output/screenshots/
+  --rev       → main
+
+Examples:
+
+  # Accept the current screenshots as the "main" baseline
+  ods screenshot-diff accept --project admin
+
+  # Accept into a local baseline directory without prompting
+  ods screenshot-diff accept --baseline ./baselines --current ./web/output/screenshots/ --yes
+
+  # Accept a single screenshot (the report's "Copy accept command" button does this)
+  ods screenshot-diff accept --project admin --name admin-documents-explorer.png`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runAccept(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin); sets sensible defaults for baseline and current")
+	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision whose baseline to update (default: main)")
+	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "Baseline directory, S3 URL (s3://...), or Azure Blob URL (az://...) to update")
+	cmd.Flags().StringVar(&opts.Current, "current", "", "Current screenshots directory")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", DefaultThreshold, "Per-channel pixel difference threshold (0.0-1.0)")
+	cmd.Flags().Float64Var(&opts.ThresholdAbs, "threshold-abs", -1, "Per-channel pixel difference threshold in 8-bit units (0-255), instead of --threshold; --threshold T equals --threshold-abs T*255 (-1 = use --threshold)")
+	cmd.MarkFlagsMutuallyExclusive("threshold", "threshold-abs")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", "JSON file of regions to ignore, or to compare with a per-region threshold")
+	cmd.Flags().StringVar(&opts.RenameMap, "rename-map", "", "JSON file mapping current screenshot filenames to baseline filenames, so renamed screenshots are still compared")
+	cmd.Flags().StringVar(&opts.Crop, "crop", "", "Only compare this region of every screenshot, as x,y,w,h in pixels")
+	cmd.Flags().IntVar(&opts.CropTop, "crop-top", 0, "Ignore the top N pixels of every screenshot (e.g. a fixed header)")
+	cmd.Flags().BoolVar(&opts.IgnoreAlpha, "ignore-alpha", false, "Compare RGB channels only, ignoring differences in alpha")
+	cmd.Flags().BoolVar(&opts.ComparePremultiplied, "compare-alpha-premultiplied", true, "Compare alpha-premultiplied channels; set to false to compare true colors of translucent pixels")
+	cmd.Flags().Float64Var(&opts.SVGDPI, "svg-dpi", imgdiff.DefaultSVGDPI, "Resolution .svg screenshots are rasterized at before comparing (needs rsvg-convert or resvg)")
+	cmd.Flags().IntVar(&opts.IgnoreScrollbar, "ignore-scrollbar", 0, "Ignore the rightmost N pixels of every screenshot, where scrollbars render differently across platforms")
+	cmd.Flags().BoolVar(&opts.IgnoreScrollbarBottom, "ignore-scrollbar-bottom", false, "With --ignore-scrollbar, also ignore the bottom N pixels (horizontal scrollbars)")
+	cmd.Flags().IntVar(&opts.MinRegionPixels, "min-region-pixels", 0, "Only mark a screenshot changed when a connected cluster of differing pixels has at least this many pixels")
+	cmd.Flags().BoolVar(&opts.NormalizeDPR, "normalize-dpr", false, "When one screenshot is an integer multiple of the other's size (a device pixel ratio change), scale the larger down before comparing")
+	cmd.Flags().BoolVar(&opts.FailOnBlank, "fail-on-blank", false, "Treat a screenshot whose baseline or current image is a single flat color as an error, so it is not accepted")
+	cmd.Flags().StringSliceVar(&opts.Names, "name", nil, "Only accept screenshots whose filename matches this glob (repeatable)")
+	cmd.Flags().BoolVar(&opts.Yes, "yes", false, "Skip confirmation prompt")
+
+	return cmd
+}
+
+func newCleanupCommand() *cobra.Command {
+	opts := &ScreenshotDiffCleanupOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Delete a revision's baselines from S3",
+		Long: `Delete all baselines stored for a project at a given revision:
+
+  s3://<bucket>/baselines/<project>/<rev>/
+
+This is intended for ephemeral baselines such as per-PR previews
+(--rev pr-1234) once they are no longer needed. Long-lived revisions
+(main, release/* branches, and v* tags) are protected and can only be
+deleted with --force.
+
+Examples:
+
+  # Delete the baselines for a merged PR
+  ods screenshot-diff cleanup --project admin --rev pr-1234
+
+  # Skip the confirmation prompt (e.g. in CI)
+  ods screenshot-diff cleanup --project admin --rev pr-1234 --yes`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runCleanup(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin)")
+	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision whose baselines should be deleted (e.g. pr-1234)")
+	cmd.Flags().BoolVar(&opts.Yes, "yes", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Allow deleting protected revisions (main, release/*, v*)")
 
 	return cmd
 }
 
+// expandEnvPath expands $VAR and ${VAR} references in a path or URL.
+// Paths without "$" are returned unchanged, and references to unset
+// variables are left as-is (with a warning) rather than silently dropped,
+// so a typo'd variable can't turn "s3://$BUCKET/x" into "s3:///x".
+func expandEnvPath(p string) string {
+	if !strings.Contains(p, "$") {
+		return p
+	}
+	return os.Expand(p, func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		log.Warnf("Environment variable %q is not set; leaving it unexpanded in %s", name, p)
+		return "${" + name + "}"
+	})
+}
+
 // resolveCompareDefaults fills in missing flags from the --project default when set.
 func resolveCompareDefaults(opts *ScreenshotDiffCompareOptions) {
 	bucket := getS3Bucket()
+	opts.Baseline = expandEnvPath(opts.Baseline)
+	opts.Current = expandEnvPath(opts.Current)
+	opts.Output = expandEnvPath(opts.Output)
+	opts.CSV = expandEnvPath(opts.CSV)
+
+	// A working-tree comparison is against the last commit unless told otherwise
+	if isGitWorktree(opts.Current) && opts.Baseline == "" {
+		opts.Baseline = gitBaselinePrefix + "HEAD"
+	}
 
 	if opts.Project != "" {
 		// Cross-revision mode: both sides come from S3
 		if opts.FromRev != "" && opts.ToRev != "" {
 			if opts.Baseline == "" {
-				opts.Baseline = fmt.Sprintf("s3://%s/baselines/%s/%s/",
-					bucket, opts.Project, sanitizeRev(opts.FromRev))
+				opts.Baseline = baselineS3URL(bucket, opts.Project, opts.FromRev)
 			}
 			if opts.Current == "" {
-				opts.Current = fmt.Sprintf("s3://%s/baselines/%s/%s/",
-					bucket, opts.Project, sanitizeRev(opts.ToRev))
+				opts.Current = baselineS3URL(bucket, opts.Project, opts.ToRev)
 			}
 		} else {
 			// Standard mode: compare local screenshots against a revision
-			rev := opts.Rev
-			if rev == "" {
-				rev = DefaultRev
-			}
 			if opts.Baseline == "" {
-				opts.Baseline = fmt.Sprintf("s3://%s/baselines/%s/%s/",
-					bucket, opts.Project, sanitizeRev(rev))
+				opts.Baseline = baselineS3URL(bucket, opts.Project, compareBaselineRev(opts))
 			}
 			if opts.Current == "" {
 				opts.Current = DefaultScreenshotDir
@@ -274,157 +762,967 @@ func resolveCompareDefaults(opts *ScreenshotDiffCompareOptions) {
 	}
 }
 
+// compareBaselineRev returns the revision the compare baseline belongs to:
+// --from-rev in cross-revision mode, otherwise --rev (default: main).
+func compareBaselineRev(opts *ScreenshotDiffCompareOptions) string {
+	if opts.FromRev != "" {
+		return opts.FromRev
+	}
+	if opts.Rev != "" {
+		return opts.Rev
+	}
+	return DefaultRev
+}
+
+// resolveRevFallback returns the first revision in [rev, fallbacks...] that
+// has baselines stored in S3 for project. If none do, rev is returned so the
+// comparison proceeds as before (every screenshot reported as added).
+func resolveRevFallback(project, rev string, fallbacks []string) (string, error) {
+	bucket := getS3Bucket()
+	for _, candidate := range append([]string{rev}, fallbacks...) {
+		url := baselineS3URL(bucket, project, candidate)
+		exists, err := hasBaselines(url)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return candidate, nil
+		}
+		log.Debugf("No baselines at %s", url)
+	}
+	log.Warnf("No baselines found for %s at %s or any fallback revision", project, rev)
+	return rev, nil
+}
+
+// hasBaselines reports whether anything is stored under an S3 baseline
+// prefix, prompting for AWS login once if needed.
+func hasBaselines(url string) (bool, error) {
+	exists, err := s3.HasObjects(url)
+	if s3.IsAuthError(err) && promptAWSLogin() {
+		exists, err = s3.HasObjects(url)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check for baselines at %s: %w", url, err)
+	}
+	return exists, nil
+}
+
+// requireRevBaselines fails when either side of a cross-revision comparison
+// has no baselines stored, e.g. because of a typo'd revision, which would
+// otherwise be reported as every screenshot added or removed. Sides whose
+// URL was set explicitly with --baseline or --current are not checked.
+func requireRevBaselines(opts *ScreenshotDiffCompareOptions) error {
+	bucket := getS3Bucket()
+	for _, side := range []struct{ rev, url string }{
+		{opts.FromRev, opts.Baseline},
+		{opts.ToRev, opts.Current},
+	} {
+		if side.url != baselineS3URL(bucket, opts.Project, side.rev) {
+			continue
+		}
+		exists, err := hasBaselines(side.url)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("no baseline found for rev %s (nothing stored at %s)", side.rev, side.url)
+		}
+	}
+	return nil
+}
+
+// warnIfStale logs a warning when the newest object under an S3 baseline
+// prefix is older than staleAfter. Lookup failures are only logged: this is
+// a nudge to re-baseline, never a reason to fail the comparison.
+func warnIfStale(baselineURL, project, rev string, staleAfter time.Duration) {
+	modified, err := s3.LastModified(baselineURL)
+	if err != nil {
+		log.Warnf("Could not check baseline age: %v", err)
+		return
+	}
+	if modified.IsZero() {
+		return
+	}
+
+	age := time.Since(modified)
+	if age <= staleAfter {
+		return
+	}
+	log.Warnf("Baseline %s was last updated %s ago (%s), older than --stale-after %s",
+		baselineURL, age.Round(time.Hour), modified.Local().Format("2006-01-02"), staleAfter)
+	if project != "" {
+		log.Warnf("If the UI changed intentionally, re-baseline with: ods screenshot-diff upload-baselines --project %s --rev %s", project, rev)
+	} else {
+		log.Warn("If the UI changed intentionally, re-baseline with: ods screenshot-diff upload-baselines")
+	}
+}
+
+// uploadRev returns the revision baselines are uploaded under (default: main).
+func uploadRev(opts *ScreenshotDiffUploadOptions) string {
+	if opts.Rev != "" {
+		return opts.Rev
+	}
+	return DefaultRev
+}
+
 // resolveUploadDefaults fills in missing flags from the --project default when set.
 func resolveUploadDefaults(opts *ScreenshotDiffUploadOptions) {
 	bucket := getS3Bucket()
+	opts.Dir = expandEnvPath(opts.Dir)
+	opts.Dest = expandEnvPath(opts.Dest)
 
 	if opts.Project != "" {
-		rev := opts.Rev
-		if rev == "" {
-			rev = DefaultRev
-		}
 		if opts.Dir == "" {
 			opts.Dir = DefaultScreenshotDir
 		}
 		if opts.Dest == "" {
-			opts.Dest = fmt.Sprintf("s3://%s/baselines/%s/%s/",
-				bucket, opts.Project, sanitizeRev(rev))
+			opts.Dest = baselineS3URL(bucket, opts.Project, uploadRev(opts))
 		}
 	}
 }
 
-// downloadS3Dir downloads an S3 URL into a local temporary directory and
-// returns the path. The caller is responsible for cleaning up the directory.
-func downloadS3Dir(s3URL string, prefix string) (string, error) {
-	tmpDir, err := os.MkdirTemp("", prefix)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %w", err)
-	}
+// resolveAcceptDefaults fills in missing flags from the --project default when set.
+func resolveAcceptDefaults(opts *ScreenshotDiffAcceptOptions) {
+	opts.Baseline = expandEnvPath(opts.Baseline)
+	opts.Current = expandEnvPath(opts.Current)
 
-	if err := s3.SyncDown(s3URL, tmpDir); err != nil {
-		_ = os.RemoveAll(tmpDir)
-		return "", fmt.Errorf("failed to download from S3 (%s): %w", s3URL, err)
+	if opts.Project != "" {
+		rev := opts.Rev
+		if rev == "" {
+			rev = DefaultRev
+		}
+		if opts.Baseline == "" {
+			opts.Baseline = baselineS3URL(getS3Bucket(), opts.Project, rev)
+		}
+		if opts.Current == "" {
+			opts.Current = DefaultScreenshotDir
+		}
 	}
-
-	return tmpDir, nil
 }
 
-func runCompare(opts *ScreenshotDiffCompareOptions) {
-	// Validate cross-revision flags are used together
-	if (opts.FromRev != "") != (opts.ToRev != "") {
-		log.Fatal("--from-rev and --to-rev must be used together")
+// outputEntries returns what --clean-output removes from outputRoot: the
+// report, summary.json, and images/, plus the --csv, --gif-dir, and
+// --images-dir outputs when they are inside outputRoot.
+func outputEntries(outputRoot, reportName string, opts *ScreenshotDiffCompareOptions) []string {
+	entries := imgdiff.OutputEntries(reportName)
+	for _, p := range []string{opts.CSV, opts.GIFDir, opts.ImagesDir} {
+		if p == "" {
+			continue
+		}
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(outputRoot, abs)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		entries = append(entries, rel)
 	}
+	return entries
+}
 
-	resolveCompareDefaults(opts)
+// compareOptions builds the image comparison options from the compare flags.
+func compareOptions(opts *ScreenshotDiffCompareOptions) (imgdiff.CompareOptions, error) {
+	compareOpts := imgdiff.CompareOptions{
+		Threshold:   opts.Threshold,
+		IgnoreAlpha: opts.IgnoreAlpha,
+		Dedupe:      opts.Dedupe,
+		TrustMtime:  opts.TrustMtime,
+		SVGDPI:      opts.SVGDPI,
+
+		IgnoreScrollbar:       opts.IgnoreScrollbar,
+		IgnoreScrollbarBottom: opts.IgnoreScrollbarBottom,
+		Sidecars:              opts.Sidecars,
+		MinRegionPixels:       opts.MinRegionPixels,
+		FailOnBlank:           opts.FailOnBlank,
+		Concurrency:           opts.Concurrency,
+		UnpremultiplyAlpha:    !opts.ComparePremultiplied,
+		NormalizeDPR:          opts.NormalizeDPR,
+	}
 
-	// Validate required fields
-	if opts.Baseline == "" {
-		log.Fatal("--baseline is required (or use --project to set defaults)")
+	if opts.SVGDPI <= 0 {
+		return compareOpts, fmt.Errorf("--svg-dpi must be positive")
 	}
-	if opts.Current == "" {
-		log.Fatal("--current is required (or use --project to set defaults)")
+	thresholdAbs, err := parseThresholdAbs(opts.ThresholdAbs)
+	if err != nil {
+		return compareOpts, err
 	}
-
-	// Determine the project name for the summary (use flag or derive from path)
-	project := opts.Project
-	if project == "" {
-		project = "default"
+	compareOpts.ThresholdAbs = thresholdAbs
+	if opts.IgnoreScrollbar < 0 {
+		return compareOpts, fmt.Errorf("--ignore-scrollbar must not be negative")
+	}
+	if opts.IgnoreScrollbarBottom && opts.IgnoreScrollbar == 0 {
+		return compareOpts, fmt.Errorf("--ignore-scrollbar-bottom requires --ignore-scrollbar")
+	}
+	if opts.MinRegionPixels < 0 {
+		return compareOpts, fmt.Errorf("--min-region-pixels must not be negative")
+	}
+	if opts.Concurrency < 0 {
+		return compareOpts, fmt.Errorf("--concurrency must not be negative")
 	}
 
-	// Track temp dirs for cleanup
-	var tempDirs []string
-	defer func() {
-		for _, d := range tempDirs {
-			_ = os.RemoveAll(d)
+	if opts.Crop != "" && opts.CropTop > 0 {
+		return compareOpts, fmt.Errorf("--crop and --crop-top cannot be used together")
+	}
+	if opts.Crop != "" {
+		crop, err := imgdiff.ParseCrop(opts.Crop)
+		if err != nil {
+			return compareOpts, err
 		}
-	}()
-
-	// Resolve baseline directory
-	baselineDir := opts.Baseline
-	if strings.HasPrefix(opts.Baseline, "s3://") {
-		dir, err := downloadS3Dir(opts.Baseline, "screenshot-baseline-*")
+		compareOpts.Crop = crop
+	}
+	if opts.CropTop > 0 {
+		compareOpts.Crop = imgdiff.CropTop(opts.CropTop)
+	}
+	overlayBase, err := imgdiff.ParseOverlayBase(opts.OverlayBase)
+	if err != nil {
+		return compareOpts, err
+	}
+	compareOpts.OverlayBase = overlayBase
+	onTraverseError, err := imgdiff.ParseTraverseErrorPolicy(opts.OnTraverseError)
+	if err != nil {
+		return compareOpts, err
+	}
+	compareOpts.OnTraverseError = onTraverseError
+	palette, err := imgdiff.ParsePalette(opts.Palette)
+	if err != nil {
+		return compareOpts, err
+	}
+	compareOpts.Palette = palette
+	if opts.FailFast {
+		for _, name := range opts.FailFastOn {
+			status, err := imgdiff.ParseStatus(name)
+			if err != nil {
+				return compareOpts, fmt.Errorf("--fail-fast-on: %w", err)
+			}
+			compareOpts.FailFast = append(compareOpts.FailFast, status)
+		}
+		if len(compareOpts.FailFast) == 0 {
+			return compareOpts, fmt.Errorf("--fail-fast-on must list at least one status")
+		}
+	}
+	if opts.Mask != "" {
+		regions, err := imgdiff.LoadMask(opts.Mask)
 		if err != nil {
-			log.Fatalf("Failed to download baselines: %v", err)
+			return compareOpts, err
 		}
-		tempDirs = append(tempDirs, dir)
-		baselineDir = dir
+		compareOpts.Regions = regions
 	}
-
-	// Resolve current directory (may also be S3 in cross-revision mode)
-	currentDir := opts.Current
-	if strings.HasPrefix(opts.Current, "s3://") {
-		dir, err := downloadS3Dir(opts.Current, "screenshot-current-*")
+	if opts.RenameMap != "" {
+		renames, err := imgdiff.LoadRenameMap(opts.RenameMap)
 		if err != nil {
-			log.Fatalf("Failed to download current screenshots: %v", err)
+			return compareOpts, err
 		}
-		tempDirs = append(tempDirs, dir)
-		currentDir = dir
+		compareOpts.RenameMap = renames
 	}
-
-	// Verify baseline directory exists
-	if _, err := os.Stat(baselineDir); os.IsNotExist(err) {
-		log.Warnf("Baseline directory does not exist: %s", baselineDir)
-		log.Warn("This may be the first run -- no baselines to compare against.")
-		// Create an empty dir so CompareDirectories works (all files will be "added")
-		if err := os.MkdirAll(baselineDir, 0755); err != nil {
-			log.Fatalf("Failed to create baseline directory: %v", err)
+	if opts.FromList != "" {
+		only, err := readNameList(opts.FromList, opts.Nested)
+		if err != nil {
+			return compareOpts, err
 		}
+		source := opts.FromList
+		if source == "-" {
+			source = "stdin"
+		}
+		log.Infof("Comparing only the %d screenshot(s) listed in %s", len(only), source)
+		compareOpts.Only = only
 	}
-
-	// Resolve the output path
-	outputPath := opts.Output
-	if !filepath.IsAbs(outputPath) {
-		cwd, err := os.Getwd()
+	if (opts.ChangedInGit == "") != (opts.SourceMap == "") {
+		return compareOpts, fmt.Errorf("--compare-only-changed-in-git and --source-map must be used together")
+	}
+	if opts.ChangedInGit != "" {
+		if opts.FromList != "" {
+			return compareOpts, fmt.Errorf("--compare-only-changed-in-git and --from-list cannot be used together")
+		}
+		only, err := changedScreenshots(opts.ChangedInGit, opts.SourceMap)
 		if err != nil {
-			log.Fatalf("Failed to get working directory: %v", err)
+			return compareOpts, err
 		}
-		outputPath = filepath.Join(cwd, outputPath)
+		compareOpts.Only = only
 	}
-	summaryPath := filepath.Join(filepath.Dir(outputPath), "summary.json")
 
-	// If the current screenshots directory doesn't exist, write an empty summary and exit
-	if _, err := os.Stat(currentDir); os.IsNotExist(err) {
-		log.Warnf("Current screenshots directory does not exist: %s", currentDir)
-		log.Warn("No screenshots captured for this project — writing empty summary.")
+	return compareOpts, nil
+}
 
-		summary := imgdiff.Summary{Project: project}
-		if err := imgdiff.WriteSummary(summary, summaryPath); err != nil {
-			log.Fatalf("Failed to write summary: %v", err)
-		}
-		log.Infof("Summary written to: %s", summaryPath)
-		return
+// readNameList reads a --from-list file, or stdin for "-".
+func readNameList(path string, nested bool) (map[string]bool, error) {
+	if path == "-" {
+		return imgdiff.ReadNameList(os.Stdin, nested)
+	}
+
+	f, err := os.Open(expandEnvPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open name list: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return imgdiff.ReadNameList(f, nested)
+}
+
+// reportOptions builds the HTML report options from the compare flags.
+func reportOptions(opts *ScreenshotDiffCompareOptions, compareOpts imgdiff.CompareOptions) imgdiff.ReportOptions {
+	return imgdiff.ReportOptions{
+		UnchangedThumbnails: opts.UnchangedThumbnails,
+		Crop:                compareOpts.Crop,
+		MaxWidth:            opts.ReportMaxWidth,
+		MaxHeight:           opts.ReportMaxHeight,
+		PNGCompression:      imgdiff.PNGCompression(opts.PNGCompression),
+		ImageFormat:         imgdiff.ImageFormat(opts.ReportImageFormat),
+		Mode:                imgdiff.ReportMode(opts.ReportMode),
+		StatusFavicon:       opts.ReportFavicon,
+		CollapseBelow:       opts.ReportCollapseBelow,
+		OmitBaseline:        !opts.ReportEmbedBaseline,
+		SVGDPI:              compareOpts.SVGDPI,
+		Palette:             compareOpts.Palette,
+		Concurrency:         opts.Concurrency,
+	}
+}
+
+// isRemoteURL reports whether u points at remote object storage (S3 or
+// Azure Blob Storage) rather than a local directory.
+func isRemoteURL(u string) bool {
+	return strings.HasPrefix(u, "s3://") || azure.IsBlobURL(u)
+}
+
+// syncDown downloads a remote prefix into a local directory, dispatching on
+// the URL scheme.
+func syncDown(remoteURL, destDir string) error {
+	if azure.IsBlobURL(remoteURL) {
+		return azure.SyncDown(remoteURL, destDir)
+	}
+	return s3.SyncDown(remoteURL, destDir)
+}
+
+// syncUp uploads a local directory to a remote prefix, dispatching on the URL scheme.
+func syncUp(srcDir, remoteURL string, delete bool) error {
+	if azure.IsBlobURL(remoteURL) {
+		return azure.SyncUp(srcDir, remoteURL, delete)
+	}
+	return s3.SyncUp(srcDir, remoteURL, delete)
+}
+
+// copyUp uploads a single file to a remote object URL, dispatching on the URL scheme.
+func copyUp(srcPath, remoteURL string) error {
+	if azure.IsBlobURL(remoteURL) {
+		return azure.CopyUp(srcPath, remoteURL)
+	}
+	return s3.CopyUp(srcPath, remoteURL)
+}
+
+// downloadRemoteDir downloads a remote URL (S3 or Azure Blob) into a local
+// temporary directory and returns the path. The caller is responsible for
+// cleaning up the directory.
+func downloadRemoteDir(remoteURL string, prefix string) (string, error) {
+	tmpDir, err := makeTempDir(prefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	err = syncDown(remoteURL, tmpDir)
+	if s3.IsAuthError(err) && promptAWSLogin() {
+		err = syncDown(remoteURL, tmpDir)
+	}
+	if err != nil {
+		removeTempDir(tmpDir)
+		return "", fmt.Errorf("failed to download from %s: %w", remoteURL, err)
+	}
+
+	return tmpDir, nil
+}
+
+// verifyDownload checks a directory downloaded by downloadRemoteDir against
+// the remote listing, re-fetching mismatched files. Only S3 is supported.
+func verifyDownload(remoteURL, dir string) error {
+	if !strings.HasPrefix(remoteURL, "s3://") {
+		log.Warnf("--verify only supports S3; not verifying %s", remoteURL)
+		return nil
+	}
+	failed, err := s3.VerifyDown(remoteURL, dir)
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d object(s) still failed verification after retries: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// promptAWSLogin offers to run "aws sso login" after an authentication
+// failure. It only prompts when stdin is a terminal and returns true if the
+// login succeeded and the operation should be retried.
+func promptAWSLogin() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+
+	if !prompt.Confirm("AWS credentials are missing or expired. Run 'aws sso login' now? (yes/no): ") {
+		return false
+	}
+
+	loginCmd := exec.Command("aws", s3.LoginArgs()...)
+	loginCmd.Stdin = os.Stdin
+	loginCmd.Stdout = os.Stdout
+	loginCmd.Stderr = os.Stderr
+	if err := loginCmd.Run(); err != nil {
+		log.Warnf("aws sso login failed: %v", err)
+		return false
+	}
+	return true
+}
+
+func runCompare(opts *ScreenshotDiffCompareOptions) {
+	// Validate cross-revision flags are used together
+	if (opts.FromRev != "") != (opts.ToRev != "") {
+		log.Fatal("--from-rev and --to-rev must be used together")
+	}
+
+	sortKey, err := imgdiff.ParseSortKey(opts.SortBy)
+	if err != nil {
+		log.Fatalf("Invalid --sort-by: %v", err)
+	}
+
+	if _, err := imgdiff.ParsePNGCompression(opts.PNGCompression); err != nil {
+		log.Fatalf("Invalid --png-compression: %v", err)
+	}
+	if _, err := imgdiff.ParseImageFormat(opts.ReportImageFormat); err != nil {
+		log.Fatalf("Invalid --report-image-format: %v", err)
+	}
+	if _, err := imgdiff.ParseReportMode(opts.ReportMode); err != nil {
+		log.Fatalf("Invalid --report-mode: %v", err)
+	}
+
+	if opts.ReportMaxWidth < 0 || opts.ReportMaxHeight < 0 {
+		log.Fatal("--report-max-width and --report-max-height must not be negative")
+	}
+	if opts.ReportCollapseBelow < 0 {
+		log.Fatal("--report-collapse-below must not be negative")
+	}
+
+	compareOpts, err := compareOptions(opts)
+	if err != nil {
+		log.Fatalf("Invalid comparison options: %v", err)
+	}
+
+	if opts.Append && opts.ImagesDir == "" {
+		log.Fatal("--append needs --images-dir, where every shard saves the images the combined report is built from")
+	}
+	if opts.Append && (opts.ReportOnly || opts.FailFast || opts.CleanOutput) {
+		log.Fatal("--append cannot be combined with --report-only, --fail-fast, or --clean-output")
+	}
+
+	if opts.ReportOnly {
+		if len(opts.Projects) > 1 {
+			log.Fatal("--report-only takes a single --project")
+		}
+		if len(opts.Projects) == 1 {
+			opts.Project = opts.Projects[0]
+		}
+		if err := reportFromSummary(opts, sortKey, compareOpts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(opts.Projects) <= 1 {
+		if len(opts.Projects) == 1 {
+			opts.Project = opts.Projects[0]
+		}
+		if _, err := compareProject(opts, sortKey, compareOpts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Multi-project mode: every project must resolve its own paths
+	if (opts.Baseline != "" && opts.Baseline != baselineCacheRef && !isGitBaseline(opts.Baseline)) || opts.Current != "" || opts.Output != "" {
+		log.Fatal("--baseline (other than @cache or git:<ref>), --current, and --output cannot be combined with multiple --project values")
+	}
+
+	var failed, withDifferences []string
+	for _, project := range opts.Projects {
+		log.Infof("=== Project: %s ===", project)
+
+		projectOpts := *opts
+		projectOpts.Project = project
+		if opts.GIFDir != "" {
+			projectOpts.GIFDir = filepath.Join(opts.GIFDir, project)
+		}
+		if opts.ImagesDir != "" {
+			projectOpts.ImagesDir = filepath.Join(opts.ImagesDir, project)
+		}
+		if opts.CSV != "" {
+			projectOpts.CSV = filepath.Join(filepath.Dir(opts.CSV), project, filepath.Base(opts.CSV))
+		}
+
+		summary, err := compareProject(&projectOpts, sortKey, compareOpts)
+		if errors.Is(err, imgdiff.ErrStoppedEarly) {
+			log.Fatalf("Project %s: %v", project, err)
+		}
+		if err != nil {
+			log.Errorf("Project %s failed: %v", project, err)
+			failed = append(failed, project)
+			continue
+		}
+		if summary.HasDifferences {
+			withDifferences = append(withDifferences, project)
+		}
+	}
+
+	log.Infof("Compared %d projects: %d with differences, %d failed",
+		len(opts.Projects), len(withDifferences), len(failed))
+	if len(withDifferences) > 0 {
+		log.Infof("  Projects with differences: %s", strings.Join(withDifferences, ", "))
+	}
+	if len(failed) > 0 {
+		log.Fatalf("  Failed projects: %s", strings.Join(failed, ", "))
+	}
+}
+
+// compareProject runs a single comparison, writing the summary and (when
+// there are differences) the HTML report, and returns the summary.
+func compareProject(opts *ScreenshotDiffCompareOptions, sortKey imgdiff.SortKey, compareOpts imgdiff.CompareOptions) (imgdiff.Summary, error) {
+	start := time.Now()
+	resolveCompareDefaults(opts)
+
+	// Validate required fields
+	if opts.Baseline == "" {
+		return imgdiff.Summary{}, fmt.Errorf("--baseline is required (or use --project to set defaults)")
+	}
+	if opts.Current == "" {
+		return imgdiff.Summary{}, fmt.Errorf("--current is required (or use --project to set defaults)")
+	}
+	if isGitWorktree(opts.Current) {
+		dir, err := resolveGitWorktree(opts.Current, opts.Baseline)
+		if err != nil {
+			return imgdiff.Summary{}, err
+		}
+		opts.Current = dir
+	}
+
+	acceptCmd := acceptCommand(opts)
+
+	// Determine the project name for the summary (use flag or derive from path)
+	project := opts.Project
+	if project == "" {
+		project = "default"
+	}
+
+	// Track temp dirs for cleanup (interrupts are handled by the registry)
+	var downloaded []string
+	defer func() {
+		for _, d := range downloaded {
+			if opts.KeepTemp {
+				keepTempDir(d)
+				log.Infof("Keeping temp directory: %s", d)
+				continue
+			}
+			removeTempDir(d)
+		}
+	}()
+
+	var timings *imgdiff.Timings
+	if opts.ProfileTimings {
+		timings = &imgdiff.Timings{}
+		compareOpts.Timings = timings
+	}
+
+	// Resolve baseline directory
+	downloadStart := time.Now()
+	if opts.FromRev != "" && opts.Project != "" {
+		if err := requireRevBaselines(opts); err != nil {
+			return imgdiff.Summary{}, err
+		}
+	}
+	rev := compareBaselineRev(opts)
+	var reportNote string
+	if len(opts.RevFallback) > 0 && opts.Project != "" && opts.Baseline == baselineS3URL(getS3Bucket(), opts.Project, rev) {
+		resolved, err := resolveRevFallback(opts.Project, rev, opts.RevFallback)
+		if err != nil {
+			return imgdiff.Summary{}, err
+		}
+		if resolved != rev {
+			log.Warnf("No baselines found for %s@%s; falling back to %s", opts.Project, rev, resolved)
+			reportNote = fmt.Sprintf("Baseline: %s (fallback — no baselines stored for %s)", resolved, rev)
+			rev = resolved
+			opts.Baseline = baselineS3URL(getS3Bucket(), opts.Project, rev)
+		}
+	}
+	baselineDir := opts.Baseline
+	if opts.Baseline == baselineCacheRef {
+		dir, err := cachedBaselineDir(opts.CacheDir, opts.Project, rev)
+		if err != nil {
+			return imgdiff.Summary{}, err
+		}
+		log.Infof("Using cached baseline: %s", dir)
+		baselineDir = dir
+	} else if isGitBaseline(opts.Baseline) {
+		dir, err := exportGitBaseline(opts.Baseline, opts.Current)
+		if err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to read baselines from git: %w", err)
+		}
+		downloaded = append(downloaded, dir)
+		baselineDir = dir
+	} else if isArchiveURL(opts.Baseline) {
+		dir, tmpDir, err := downloadArchiveDir(opts.Baseline, "screenshot-baseline-archive-*")
+		if err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to read baseline archive: %w", err)
+		}
+		downloaded = append(downloaded, tmpDir)
+		baselineDir = dir
+	} else if isRemoteURL(opts.Baseline) {
+		if opts.StaleAfter > 0 && strings.HasPrefix(opts.Baseline, "s3://") {
+			warnIfStale(opts.Baseline, opts.Project, rev, opts.StaleAfter)
+		}
+
+		dir, err := downloadRemoteDir(opts.Baseline, baselineTempPattern)
+		if err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to download baselines: %w", err)
+		}
+		downloaded = append(downloaded, dir)
+		baselineDir = dir
+		if opts.Verify {
+			if err := verifyDownload(opts.Baseline, dir); err != nil {
+				return imgdiff.Summary{}, fmt.Errorf("failed to verify baselines: %w", err)
+			}
+		}
+
+		// Only the project's default baseline is cached, so @cache always means the same thing
+		if opts.Project != "" && opts.CacheDir != "" && opts.Baseline == baselineS3URL(getS3Bucket(), opts.Project, rev) {
+			if err := updateBaselineCache(opts.CacheDir, opts.Project, rev, dir); err != nil {
+				log.Warnf("Failed to cache baseline: %v", err)
+			}
+		}
+	}
+
+	// Resolve current directory (may also be S3 in cross-revision mode)
+	currentDir := opts.Current
+	if isRemoteURL(opts.Current) {
+		dir, err := downloadRemoteDir(opts.Current, currentTempPattern)
+		if err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to download current screenshots: %w", err)
+		}
+		downloaded = append(downloaded, dir)
+		currentDir = dir
+		if opts.Verify {
+			if err := verifyDownload(opts.Current, dir); err != nil {
+				return imgdiff.Summary{}, fmt.Errorf("failed to verify current screenshots: %w", err)
+			}
+		}
+	}
+	if timings != nil {
+		timings.Download = time.Since(downloadStart)
+	}
+
+	// Comparing a directory against itself makes every screenshot trivially unchanged
+	if same, err := imgdiff.SameDirectory(baselineDir, currentDir); err != nil {
+		log.Debugf("Could not resolve baseline/current paths: %v", err)
+	} else if same {
+		if opts.Strict {
+			return imgdiff.Summary{}, fmt.Errorf("--baseline and --current resolve to the same directory: %s", baselineDir)
+		}
+		log.Warn("!!! --baseline and --current resolve to the same directory !!!")
+		log.Warnf("    %s", baselineDir)
+		log.Warn("    Every screenshot will be reported as unchanged. Check your flags and environment variables.")
+	}
+
+	// Verify baseline directory exists (a dangling symlink is an error, not a first run)
+	if exists, err := imgdiff.StatDir(baselineDir); err != nil {
+		return imgdiff.Summary{}, fmt.Errorf("baseline directory: %w", err)
+	} else if !exists {
+		log.Warnf("Baseline directory does not exist: %s", baselineDir)
+		log.Warn("This may be the first run -- no baselines to compare against.")
+		// Create an empty dir so CompareDirectories works (all files will be "added")
+		if err := os.MkdirAll(baselineDir, 0755); err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to create baseline directory: %w", err)
+		}
+	}
+
+	// Resolve the output path
+	outputPath := opts.Output
+	if !filepath.IsAbs(outputPath) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to get working directory: %w", err)
+		}
+		outputPath = filepath.Join(cwd, outputPath)
+	}
+	summaryPath := filepath.Join(filepath.Dir(outputPath), "summary.json")
+
+	// Remove an earlier run's results so files from a run with more
+	// differences don't linger next to this run's results
+	if opts.CleanOutput {
+		outputRoot := filepath.Dir(outputPath)
+		entries := outputEntries(outputRoot, filepath.Base(outputPath), opts)
+		if err := imgdiff.CleanOutputDir(outputRoot, entries); err != nil {
+			return imgdiff.Summary{}, fmt.Errorf("failed to clean output directory: %w", err)
+		}
+		log.Infof("Cleaned earlier results from output directory: %s", outputRoot)
+		defer func() {
+			if err := imgdiff.PruneOutputDirs(outputRoot, entries); err != nil {
+				log.Warnf("Failed to prune empty output directories: %v", err)
+			}
+		}()
+	}
+
+	// A dangling symlink must not pass for "no screenshots captured"
+	currentExists, err := imgdiff.StatDir(currentDir)
+	if err != nil {
+		return imgdiff.Summary{}, fmt.Errorf("current screenshots directory: %w", err)
+	}
+
+	hasScreenshots := imgdiff.HasScreenshots
+	if opts.Nested {
+		hasScreenshots = imgdiff.HasNestedScreenshots
+	}
+	hasCurrent, err := hasScreenshots(currentDir)
+	if err != nil {
+		return imgdiff.Summary{}, fmt.Errorf("failed to list current screenshots: %w", err)
+	}
+	if !hasCurrent && opts.RequireCurrent {
+		return imgdiff.Summary{}, fmt.Errorf("no screenshots found in %s (--require-current): check that the capture step ran", opts.Current)
+	}
+
+	// If the current screenshots directory doesn't exist, write an empty summary and exit
+	if !currentExists {
+		log.Warnf("Current screenshots directory does not exist: %s", currentDir)
+		summary := imgdiff.Summary{Project: project, NoScreenshots: true}
+		if _, err := os.Stat(summaryPath); err == nil && opts.Append {
+			log.Warn("No screenshots captured by this shard — leaving the existing summary as is.")
+			return summary, nil
+		}
+		log.Warn("No screenshots captured for this project — writing empty summary.")
+
+		if err := imgdiff.WriteSummary(summary, summaryPath); err != nil {
+			return summary, fmt.Errorf("failed to write summary: %w", err)
+		}
+		log.Infof("Summary written to: %s", summaryPath)
+		return summary, nil
+	}
+	if !hasCurrent {
+		log.Warnf("Current screenshots directory is empty: %s", currentDir)
 	}
 
 	log.Infof("Comparing screenshots...")
 	log.Infof("  Baseline: %s", opts.Baseline)
 	log.Infof("  Current:  %s", opts.Current)
-	log.Infof("  Threshold: %.2f", opts.Threshold)
-
-	results, err := imgdiff.CompareDirectories(baselineDir, currentDir, opts.Threshold)
-	if err != nil {
-		log.Fatalf("Comparison failed: %v", err)
+	if opts.ThresholdAbs >= 0 {
+		log.Infof("  Threshold: %g/255", opts.ThresholdAbs)
+	} else {
+		log.Infof("  Threshold: %.2f", opts.Threshold)
 	}
 
-	// Print terminal summary
-	printSummary(results)
+	if opts.NDJSON {
+		compareOpts.OnResult = newResultEventWriter(os.Stdout, project)
+	}
+	compareDirs := imgdiff.CompareDirectoriesWithOptions
+	if opts.Nested {
+		compareDirs = imgdiff.CompareNestedDirectories
+	}
+	results, err := compareDirs(baselineDir, currentDir, compareOpts)
+	stoppedEarly := errors.Is(err, imgdiff.ErrStoppedEarly)
+	if err != nil && !stoppedEarly {
+		return imgdiff.Summary{}, fmt.Errorf("comparison failed: %w", err)
+	}
+	imgdiff.SortResults(results, sortKey)
+	logRenames(results)
+	warnDPRChanges(results, opts.NormalizeDPR)
+	warnBlankScreenshots(results)
+
+	// Print terminal summary (the NDJSON stream replaces it)
+	if !opts.NDJSON {
+		printSummary(results)
+	}
 
 	// Build and write JSON summary (always)
 	summary := imgdiff.BuildSummary(project, results)
+	summary.NoScreenshots = !hasCurrent
+	summary.Partial = stoppedEarly
+	if opts.Append {
+		// Hold the lock until the merged summary and report are written, so
+		// shards finishing together on a shared output directory take turns
+		unlock, err := lockSummary(summaryPath)
+		if err != nil {
+			return summary, err
+		}
+		defer unlock()
+		summary, results, err = appendToSummary(summary, results, summaryPath, expandEnvPath(opts.ImagesDir), imgdiff.PNGCompression(opts.PNGCompression))
+		if err != nil {
+			return summary, err
+		}
+		imgdiff.SortResults(results, sortKey)
+	}
+	if len(summary.Projects) > 0 && !opts.NDJSON {
+		printProjectSummaries(summary.Projects)
+	}
+	if opts.BaselineSummary != "" && !stoppedEarly {
+		previous, err := loadBaselineSummary(strings.ReplaceAll(opts.BaselineSummary, "{project}", project))
+		if err != nil {
+			log.Warnf("Skipping delta against previous run: %v", err)
+		} else {
+			delta := imgdiff.DiffSummaries(previous, summary)
+			summary.Delta = &delta
+			if !opts.NDJSON {
+				printSummaryDelta(delta)
+			}
+		}
+	}
 	if err := imgdiff.WriteSummary(summary, summaryPath); err != nil {
-		log.Fatalf("Failed to write summary: %v", err)
+		return summary, fmt.Errorf("failed to write summary: %w", err)
 	}
 	log.Infof("Summary written to: %s", summaryPath)
 
+	if stoppedEarly {
+		for _, r := range results {
+			if slices.Contains(compareOpts.FailFast, r.Status) {
+				return summary, fmt.Errorf("%w: %s is %s after %d screenshot(s) (--fail-fast; partial results, report skipped)",
+					imgdiff.ErrStoppedEarly, r.Name, r.Status, len(results))
+			}
+		}
+	}
+
+	if opts.CSV != "" {
+		if err := imgdiff.WriteCSV(results, opts.CSV); err != nil {
+			return summary, err
+		}
+		log.Infof("CSV written to: %s", opts.CSV)
+	}
+
+	// With --append, the images were saved before merging
+	if opts.ImagesDir != "" && !opts.Append {
+		imagesDir := expandEnvPath(opts.ImagesDir)
+		if err := imgdiff.SaveResultImages(results, imagesDir, imgdiff.PNGCompression(opts.PNGCompression)); err != nil {
+			return summary, fmt.Errorf("failed to save images: %w", err)
+		}
+		log.Infof("Images saved to: %s", imagesDir)
+	}
+
 	// Generate HTML report only if there are differences
 	if summary.HasDifferences {
 		log.Infof("Generating report: %s", outputPath)
-		if err := imgdiff.GenerateReport(results, outputPath); err != nil {
-			log.Fatalf("Failed to generate report: %v", err)
+		reportOpts := reportOptions(opts, compareOpts)
+		reportOpts.Note = reportNote
+		reportOpts.Title = strings.ReplaceAll(opts.ReportTitle, "{project}", project)
+		reportOpts.AcceptCommand = acceptCmd
+		reportOpts.Timings = timings
+		reportStart := time.Now()
+		if err := imgdiff.GenerateReport(results, outputPath, reportOpts); err != nil {
+			return summary, fmt.Errorf("failed to generate report: %w", err)
+		}
+		if timings != nil {
+			timings.Report = time.Since(reportStart) - timings.Encode
 		}
 		log.Infof("Report generated successfully: %s", outputPath)
 	} else {
 		log.Infof("No visual differences detected — skipping report generation.")
 	}
+
+	// Export blink GIFs for changed screenshots if requested
+	if opts.GIFDir != "" && summary.Changed > 0 {
+		gifs, err := imgdiff.WriteBlinkGIFs(results, opts.GIFDir, opts.GIFDelay, opts.SVGDPI)
+		if err != nil {
+			return summary, fmt.Errorf("failed to write GIFs: %w", err)
+		}
+		log.Infof("Wrote %d blink GIF(s) to: %s", len(gifs), opts.GIFDir)
+	}
+
+	if timings != nil {
+		timings.Total = time.Since(start)
+		logTimings(timings)
+
+		// The summary was written before the report, so rewrite it with
+		// the complete breakdown
+		summary.Timings = timings
+		if err := imgdiff.WriteSummary(summary, summaryPath); err != nil {
+			return summary, fmt.Errorf("failed to write summary: %w", err)
+		}
+	}
+
+	return summary, nil
+}
+
+// lockSummary takes an exclusive lock on summaryPath for an --append
+// read-merge-write cycle, waiting for other shards holding it.
+func lockSummary(summaryPath string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(summaryPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	lockPath := summaryPath + ".lock"
+	log.Debugf("Waiting for %s...", lockPath)
+	unlock, err := filelock.Lock(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock summary for --append: %w", err)
+	}
+	return unlock, nil
+}
+
+// appendToSummary saves this run's images to imagesDir and merges its
+// summary into the one already at summaryPath, if any, for sharded runs. It
+// returns the combined summary and the results rebuilt from it and the
+// images every shard saved, for the report.
+func appendToSummary(summary imgdiff.Summary, results []imgdiff.Result, summaryPath, imagesDir string, compression imgdiff.PNGCompression) (imgdiff.Summary, []imgdiff.Result, error) {
+	if err := imgdiff.SaveResultImages(results, imagesDir, compression); err != nil {
+		return summary, nil, fmt.Errorf("failed to save images: %w", err)
+	}
+
+	if _, err := os.Stat(summaryPath); os.IsNotExist(err) {
+		log.Infof("No summary to append to yet; starting %s", summaryPath)
+		return summary, results, nil
+	}
+	existing, err := imgdiff.LoadSummary(summaryPath)
+	if err != nil {
+		return summary, nil, err
+	}
+
+	merged, conflicts, err := imgdiff.MergeSummaries(existing, summary)
+	if err != nil {
+		return summary, nil, fmt.Errorf("failed to merge into %s: %w", summaryPath, err)
+	}
+	if len(conflicts) > 0 {
+		log.Warnf("%d screenshot(s) were already in %s and were replaced by this run: %s",
+			len(conflicts), summaryPath, strings.Join(conflicts, ", "))
+	}
+	log.Infof("Appended %d result(s) to %s (%d in total)", len(results), summaryPath, merged.Total)
+
+	combined, err := imgdiff.ResultsFromSummary(merged, imagesDir)
+	if err != nil {
+		return merged, nil, fmt.Errorf("failed to rebuild combined results: %w", err)
+	}
+	return merged, combined, nil
+}
+
+// reportFromSummary regenerates the HTML report from an earlier run's
+// summary.json and the images it saved with --images-dir, without decoding
+// or comparing any screenshots.
+func reportFromSummary(opts *ScreenshotDiffCompareOptions, sortKey imgdiff.SortKey, compareOpts imgdiff.CompareOptions) error {
+	if opts.Summary == "" || opts.ImagesDir == "" {
+		return fmt.Errorf("--report-only requires --summary and --images-dir")
+	}
+	resolveCompareDefaults(opts)
+
+	summary, err := loadBaselineSummary(expandEnvPath(opts.Summary))
+	if err != nil {
+		return err
+	}
+	results, err := imgdiff.ResultsFromSummary(summary, expandEnvPath(opts.ImagesDir))
+	if err != nil {
+		return fmt.Errorf("failed to rebuild results: %w", err)
+	}
+	imgdiff.SortResults(results, sortKey)
+
+	if !summary.HasDifferences {
+		log.Infof("No visual differences in %s — skipping report generation.", opts.Summary)
+		return nil
+	}
+
+	log.Infof("Generating report: %s", opts.Output)
+	reportOpts := reportOptions(opts, compareOpts)
+	reportOpts.Title = strings.ReplaceAll(opts.ReportTitle, "{project}", summary.Project)
+	if err := imgdiff.GenerateReport(results, opts.Output, reportOpts); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	log.Infof("Report generated successfully: %s", opts.Output)
+	return nil
 }
 
 func runUploadBaselines(opts *ScreenshotDiffUploadOptions) {
@@ -438,27 +1736,418 @@ func runUploadBaselines(opts *ScreenshotDiffUploadOptions) {
 		log.Fatal("--dest is required (or use --project to set defaults)")
 	}
 
-	if _, err := os.Stat(opts.Dir); os.IsNotExist(err) {
+	if exists, err := imgdiff.StatDir(opts.Dir); err != nil {
+		log.Fatalf("Screenshots directory: %v", err)
+	} else if !exists {
 		log.Fatalf("Screenshots directory does not exist: %s", opts.Dir)
 	}
 
-	if !strings.HasPrefix(opts.Dest, "s3://") {
-		log.Fatalf("Destination must be an S3 URL (s3://...): %s", opts.Dest)
+	if !isRemoteURL(opts.Dest) {
+		log.Fatalf("Destination must be an S3 URL (s3://...) or Azure Blob URL (az://...): %s", opts.Dest)
 	}
 
 	log.Infof("Uploading baselines...")
 	log.Infof("  Source: %s", opts.Dir)
 	log.Infof("  Dest:   %s", opts.Dest)
 
-	if err := s3.SyncUp(opts.Dir, opts.Dest, opts.Delete); err != nil {
-		log.Fatalf("Failed to upload baselines: %v", err)
+	// Both backends sync incrementally, so re-running after a failure only
+	// uploads what is still missing or different
+	if err := syncUp(opts.Dir, opts.Dest, opts.Delete); err != nil {
+		log.Fatalf("Failed to upload baselines: %v (re-run the same command to resume; files already uploaded are skipped)", err)
 	}
 
 	log.Info("Baselines uploaded successfully.")
+
+	rev := uploadRev(opts)
+	if opts.Project != "" && opts.CacheDir != "" && opts.Dest == baselineS3URL(getS3Bucket(), opts.Project, rev) {
+		if err := updateBaselineCache(opts.CacheDir, opts.Project, rev, opts.Dir); err != nil {
+			log.Warnf("Failed to cache baseline: %v", err)
+		}
+	}
+}
+
+func runAccept(opts *ScreenshotDiffAcceptOptions) {
+	resolveAcceptDefaults(opts)
+
+	// Validate required fields
+	if opts.Baseline == "" {
+		log.Fatal("--baseline is required (or use --project to set defaults)")
+	}
+	if opts.Current == "" {
+		log.Fatal("--current is required (or use --project to set defaults)")
+	}
+	if exists, err := imgdiff.StatDir(opts.Current); err != nil {
+		log.Fatalf("Current screenshots directory: %v", err)
+	} else if !exists {
+		log.Fatalf("Current screenshots directory does not exist: %s", opts.Current)
+	}
+	// Compare exactly as compare would, so the same screenshots are changed
+	compareOpts, err := compareOptions(&ScreenshotDiffCompareOptions{
+		Threshold:             opts.Threshold,
+		ThresholdAbs:          opts.ThresholdAbs,
+		Mask:                  expandEnvPath(opts.Mask),
+		RenameMap:             expandEnvPath(opts.RenameMap),
+		Crop:                  opts.Crop,
+		CropTop:               opts.CropTop,
+		IgnoreAlpha:           opts.IgnoreAlpha,
+		ComparePremultiplied:  opts.ComparePremultiplied,
+		SVGDPI:                opts.SVGDPI,
+		IgnoreScrollbar:       opts.IgnoreScrollbar,
+		IgnoreScrollbarBottom: opts.IgnoreScrollbarBottom,
+		MinRegionPixels:       opts.MinRegionPixels,
+		NormalizeDPR:          opts.NormalizeDPR,
+		FailOnBlank:           opts.FailOnBlank,
+	})
+	if err != nil {
+		log.Fatalf("Invalid comparison options: %v", err)
+	}
+
+	remote := isRemoteURL(opts.Baseline)
+
+	baselineDir := opts.Baseline
+	if remote {
+		dir, err := downloadRemoteDir(opts.Baseline, baselineTempPattern)
+		if err != nil {
+			log.Fatalf("Failed to download baselines: %v", err)
+		}
+		defer removeTempDir(dir)
+		baselineDir = dir
+	}
+
+	results, err := imgdiff.CompareDirectoriesWithOptions(baselineDir, opts.Current, compareOpts)
+	if err != nil {
+		log.Fatalf("Comparison failed: %v", err)
+	}
+
+	printSummary(results)
+
+	accepted, err := imgdiff.SelectAccepted(results, opts.Names)
+	if err != nil {
+		log.Fatalf("Invalid --name: %v", err)
+	}
+
+	if len(accepted) == 0 {
+		log.Info("No changed or added screenshots — baseline is already up to date.")
+		return
+	}
+
+	if !opts.Yes {
+		msg := fmt.Sprintf("This will overwrite %d baseline screenshot(s) in %s. Continue? (yes/no): ",
+			len(accepted), opts.Baseline)
+		if !prompt.Confirm(msg) {
+			log.Info("Aborted.")
+			return
+		}
+	}
+
+	for _, r := range accepted {
+		if remote {
+			err = copyUp(r.CurrentPath, strings.TrimSuffix(opts.Baseline, "/")+"/"+r.Name)
+		} else {
+			err = copyFile(r.CurrentPath, filepath.Join(baselineDir, r.Name))
+		}
+		if err != nil {
+			log.Fatalf("Failed to accept %s: %v", r.Name, err)
+		}
+		log.Infof("  Accepted %s (%s)", r.Name, r.Status)
+	}
+
+	log.Infof("Accepted %d screenshot(s) into %s", len(accepted), opts.Baseline)
+}
+
+// parseThresholdAbs validates a --threshold-abs value, returning nil when
+// the flag was not set (-1).
+func parseThresholdAbs(v float64) (*float64, error) {
+	if v == -1 {
+		return nil, nil
+	}
+	if v < 0 || v > 255 {
+		return nil, fmt.Errorf("--threshold-abs must be between 0 and 255")
+	}
+	return &v, nil
+}
+
+// acceptCommand returns a function building the "ods screenshot-diff accept"
+// command that updates the baseline this comparison used with a single
+// screenshot, or nil when the comparison can't be accepted from (remote
+// current screenshots, a git or archive baseline, or --nested). It must be
+// called before the baseline is rewritten by --rev-fallback.
+func acceptCommand(opts *ScreenshotDiffCompareOptions) func(name string) string {
+	if opts.Nested || isRemoteURL(opts.Current) || isGitBaseline(opts.Baseline) || isArchiveURL(opts.Baseline) {
+		return nil
+	}
+
+	args := []string{"ods", "screenshot-diff", "accept"}
+	rev := compareBaselineRev(opts)
+	if opts.Project != "" && (opts.Baseline == baselineCacheRef || opts.Baseline == baselineS3URL(getS3Bucket(), opts.Project, rev)) {
+		args = append(args, "--project", opts.Project)
+		if rev != DefaultRev {
+			args = append(args, "--rev", rev)
+		}
+		if opts.Current != DefaultScreenshotDir {
+			args = append(args, "--current", opts.Current)
+		}
+	} else {
+		args = append(args, "--baseline", opts.Baseline, "--current", opts.Current)
+	}
+	if opts.ThresholdAbs >= 0 {
+		args = append(args, "--threshold-abs", strconv.FormatFloat(opts.ThresholdAbs, 'g', -1, 64))
+	} else if opts.Threshold != DefaultThreshold {
+		args = append(args, "--threshold", strconv.FormatFloat(opts.Threshold, 'g', -1, 64))
+	}
+	args = append(args, acceptCompareArgs(opts)...)
+
+	for i, a := range args {
+		args[i] = shellQuote(a)
+	}
+	base := strings.Join(args, " ")
+	return func(name string) string {
+		return base + " --name " + shellQuote(name)
+	}
+}
+
+// shellQuote quotes s for a POSIX shell if it contains anything other than
+// characters that are always safe unquoted.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// acceptCompareArgs returns the accept flags reproducing the comparison
+// options compare ran with, besides the threshold, so an accept command
+// judges the screenshot the same way the report did.
+func acceptCompareArgs(opts *ScreenshotDiffCompareOptions) []string {
+	var args []string
+	if opts.Mask != "" {
+		args = append(args, "--mask", opts.Mask)
+	}
+	if opts.RenameMap != "" {
+		args = append(args, "--rename-map", opts.RenameMap)
+	}
+	if opts.Crop != "" {
+		args = append(args, "--crop", opts.Crop)
+	}
+	if opts.CropTop > 0 {
+		args = append(args, "--crop-top", strconv.Itoa(opts.CropTop))
+	}
+	if opts.IgnoreAlpha {
+		args = append(args, "--ignore-alpha")
+	}
+	if !opts.ComparePremultiplied {
+		args = append(args, "--compare-alpha-premultiplied=false")
+	}
+	if opts.SVGDPI != imgdiff.DefaultSVGDPI {
+		args = append(args, "--svg-dpi", strconv.FormatFloat(opts.SVGDPI, 'g', -1, 64))
+	}
+	if opts.IgnoreScrollbar > 0 {
+		args = append(args, "--ignore-scrollbar", strconv.Itoa(opts.IgnoreScrollbar))
+		if opts.IgnoreScrollbarBottom {
+			args = append(args, "--ignore-scrollbar-bottom")
+		}
+	}
+	if opts.MinRegionPixels > 0 {
+		args = append(args, "--min-region-pixels", strconv.Itoa(opts.MinRegionPixels))
+	}
+	if opts.NormalizeDPR {
+		args = append(args, "--normalize-dpr")
+	}
+	if opts.FailOnBlank {
+		args = append(args, "--fail-on-blank")
+	}
+	return args
+}
+
+// copyFile copies a single file from src to dst, creating parent directories as needed.
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+
+	return nil
+}
+
+func runCleanup(opts *ScreenshotDiffCleanupOptions) {
+	// Validate required fields
+	if opts.Project == "" {
+		log.Fatal("--project is required")
+	}
+	if opts.Rev == "" {
+		log.Fatal("--rev is required")
+	}
+
+	if isProtectedRev(opts.Rev) && !opts.Force {
+		log.Fatalf("Refusing to delete baselines for protected revision %q (use --force to override)", opts.Rev)
+	}
+
+	target := baselineS3URL(getS3Bucket(), opts.Project, opts.Rev)
+
+	if !opts.Yes {
+		msg := fmt.Sprintf("This will DELETE all baselines under %s. Continue? (yes/no): ", target)
+		if !prompt.Confirm(msg) {
+			log.Info("Aborted.")
+			return
+		}
+	}
+
+	if err := s3.RemovePrefix(target); err != nil {
+		log.Fatalf("Failed to delete baselines: %v", err)
+	}
+
+	log.Info("Baselines deleted successfully.")
+}
+
+// loadBaselineSummary loads a previous run's summary from a local path or S3 URL.
+func loadBaselineSummary(path string) (imgdiff.Summary, error) {
+	if !strings.HasPrefix(path, "s3://") {
+		return imgdiff.LoadSummary(path)
+	}
+
+	tmpDir, err := makeTempDir("screenshot-summary-*")
+	if err != nil {
+		return imgdiff.Summary{}, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer removeTempDir(tmpDir)
+
+	localPath := filepath.Join(tmpDir, "summary.json")
+	if err := s3.FetchToFile(path, localPath); err != nil {
+		return imgdiff.Summary{}, err
+	}
+	return imgdiff.LoadSummary(localPath)
+}
+
+// resultEvent is one line of compare --ndjson output.
+type resultEvent struct {
+	Project     string   `json:"project,omitempty"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	DiffPercent float64  `json:"diff_percent"`
+	RenamedFrom string   `json:"renamed_from,omitempty"`
+	Duplicates  []string `json:"duplicates,omitempty"`
+	Warning     string   `json:"warning,omitempty"`
+}
+
+// newResultEventWriter returns a comparison callback that writes each result
+// to w as a single JSON line, as soon as it is known.
+func newResultEventWriter(w io.Writer, project string) func(imgdiff.Result) {
+	enc := json.NewEncoder(w)
+	return func(r imgdiff.Result) {
+		event := resultEvent{
+			Project:     project,
+			Name:        r.Name,
+			Status:      r.Status.String(),
+			DiffPercent: r.DiffPercent,
+			RenamedFrom: r.RenamedFrom,
+			Duplicates:  r.Duplicates,
+			Warning:     r.Warning,
+		}
+		if err := enc.Encode(event); err != nil {
+			log.Warnf("Failed to write result event for %s: %v", r.Name, err)
+		}
+	}
+}
+
+// logTimings logs the --profile-timings breakdown. It goes to the log
+// (stderr) rather than stdout so it does not interleave with --ndjson output.
+func logTimings(timings *imgdiff.Timings) {
+	log.Info("Timings:")
+	for _, p := range timings.Phases() {
+		share := 0.0
+		if timings.Total > 0 {
+			share = 100 * float64(p.Duration) / float64(timings.Total)
+		}
+		log.Infof("  %-9s %10.1fms  %5.1f%%", p.Name, p.Duration.Seconds()*1000, share)
+	}
+	log.Infof("  %-9s %10.1fms", "total", timings.Total.Seconds()*1000)
+}
+
+// printSummaryDelta prints how this run differs from the previous run.
+func printSummaryDelta(delta imgdiff.SummaryDelta) {
+	fmt.Printf("Compared to previous run: %+d changed\n", delta.ChangedDelta)
+	for _, group := range []struct {
+		label string
+		names []string
+	}{
+		{"Newly changed", delta.NewlyChanged},
+		{"Newly added", delta.NewlyAdded},
+		{"Newly removed", delta.NewlyRemoved},
+		{"Newly fixed", delta.NewlyFixed},
+	} {
+		if len(group.names) == 0 {
+			continue
+		}
+		fmt.Printf("  %s (%d):\n", group.label, len(group.names))
+		for _, name := range group.names {
+			fmt.Printf("    %s\n", name)
+		}
+	}
+	fmt.Println()
+}
+
+// printProjectSummaries prints one line of counts per project of a nested
+// comparison.
+func printProjectSummaries(projects []imgdiff.ProjectSummary) {
+	fmt.Println("By project:")
+	for _, p := range projects {
+		fmt.Printf("  %-24s %d changed, %d added, %d removed, %d unchanged\n", p.Project, p.Changed, p.Added, p.Removed, p.Unchanged)
+	}
+	fmt.Println()
+}
+
+// logRenames reports the screenshots that were paired via --rename-map and
+// those collapsed by --dedupe.
+func logRenames(results []imgdiff.Result) {
+	for _, r := range results {
+		if r.RenamedFrom != "" {
+			log.Infof("Compared %s against renamed baseline %s", r.Name, r.RenamedFrom)
+		}
+		if len(r.Duplicates) > 0 {
+			log.Infof("Collapsed identical screenshots into %s: %s", r.Name, strings.Join(r.Duplicates, ", "))
+		}
+	}
+}
+
+// warnDPRChanges sums up the screenshots whose sizes differ by an integer
+// factor, which points at a capture config change rather than a regression.
+func warnDPRChanges(results []imgdiff.Result, normalized bool) {
+	scales := make(map[float64]int)
+	for _, r := range results {
+		if r.DPRScale != 0 {
+			scales[r.DPRScale]++
+		}
+	}
+	if len(scales) == 0 || normalized {
+		return
+	}
+	for _, scale := range slices.Sorted(maps.Keys(scales)) {
+		log.Warnf("%d screenshot(s) are %gx the baseline's size: the device pixel ratio likely changed between captures. Check the capture config, or re-run with --normalize-dpr", scales[scale], scale)
+	}
+}
+
+// warnBlankScreenshots logs the comparisons flagged with a warning, such as
+// a blank capture that would otherwise pass for a dramatic regression.
+func warnBlankScreenshots(results []imgdiff.Result) {
+	for _, r := range results {
+		if r.Warning != "" {
+			log.Warnf("%s: %s (re-run with --fail-on-blank to treat this as an error)", r.Name, r.Warning)
+		}
+	}
 }
 
 func printSummary(results []imgdiff.Result) {
-	changed, added, removed, unchanged := 0, 0, 0, 0
+	changed, added, removed, unchanged, errored := 0, 0, 0, 0, 0
 	for _, r := range results {
 		switch r.Status {
 		case imgdiff.StatusChanged:
@@ -469,9 +2158,16 @@ func printSummary(results []imgdiff.Result) {
 			removed++
 		case imgdiff.StatusUnchanged:
 			unchanged++
+		case imgdiff.StatusError:
+			errored++
 		}
 	}
 
+	if !colorOutput {
+		printPlainSummary(results, changed, added, removed, unchanged, errored)
+		return
+	}
+
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════╗")
 	fmt.Println("║          Visual Regression Summary           ║")
@@ -480,13 +2176,18 @@ func printSummary(results []imgdiff.Result) {
 	fmt.Printf("║  Added:     %-32d ║\n", added)
 	fmt.Printf("║  Removed:   %-32d ║\n", removed)
 	fmt.Printf("║  Unchanged: %-32d ║\n", unchanged)
-	fmt.Printf("║  Total:     %-32d ║\n", len(results))
+	if errored > 0 {
+		fmt.Printf("║  Errored:   %-32d ║\n", errored)
+	}
+	fmt.Printf("║  Total:     %-32d ║\n", changed+added+removed+unchanged+errored)
 	fmt.Println("╚══════════════════════════════════════════════╝")
 	fmt.Println()
 
-	if changed > 0 || added > 0 || removed > 0 {
+	if changed > 0 || added > 0 || removed > 0 || errored > 0 {
 		for _, r := range results {
 			switch r.Status {
+			case imgdiff.StatusError:
+				fmt.Printf("  ✘ ERROR    %s: %s\n", r.Name, r.Error)
 			case imgdiff.StatusChanged:
 				fmt.Printf("  ⚠ CHANGED  %s (%.2f%% diff)\n", r.Name, r.DiffPercent)
 			case imgdiff.StatusAdded:
@@ -498,3 +2199,38 @@ func printSummary(results []imgdiff.Result) {
 		fmt.Println()
 	}
 }
+
+// printPlainSummary prints the summary using ASCII only, for non-interactive
+// output such as CI logs or when colors are disabled.
+func printPlainSummary(results []imgdiff.Result, changed, added, removed, unchanged, errored int) {
+	fmt.Println()
+	fmt.Println("+----------------------------------------------+")
+	fmt.Println("|          Visual Regression Summary           |")
+	fmt.Println("+----------------------------------------------+")
+	fmt.Printf("|  Changed:   %-32d |\n", changed)
+	fmt.Printf("|  Added:     %-32d |\n", added)
+	fmt.Printf("|  Removed:   %-32d |\n", removed)
+	fmt.Printf("|  Unchanged: %-32d |\n", unchanged)
+	if errored > 0 {
+		fmt.Printf("|  Errored:   %-32d |\n", errored)
+	}
+	fmt.Printf("|  Total:     %-32d |\n", changed+added+removed+unchanged+errored)
+	fmt.Println("+----------------------------------------------+")
+	fmt.Println()
+
+	if changed > 0 || added > 0 || removed > 0 || errored > 0 {
+		for _, r := range results {
+			switch r.Status {
+			case imgdiff.StatusError:
+				fmt.Printf("  x ERROR    %s: %s\n", r.Name, r.Error)
+			case imgdiff.StatusChanged:
+				fmt.Printf("  ! CHANGED  %s (%.2f%% diff)\n", r.Name, r.DiffPercent)
+			case imgdiff.StatusAdded:
+				fmt.Printf("  + ADDED    %s\n", r.Name)
+			case imgdiff.StatusRemoved:
+				fmt.Printf("  - REMOVED  %s\n", r.Name)
+			}
+		}
+		fmt.Println()
+	}
+}
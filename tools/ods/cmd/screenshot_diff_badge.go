@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/s3"
+)
+
+// ScreenshotDiffBadgeOptions holds options for the badge subcommand.
+type ScreenshotDiffBadgeOptions struct {
+	Summary string // summary.json written by compare (local path or s3://)
+	Out     string
+	Dest    string // optional S3 object URL to upload the badge to
+}
+
+func newBadgeCommand() *cobra.Command {
+	opts := &ScreenshotDiffBadgeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "badge",
+		Short: "Generate a README status badge from a comparison summary",
+		Long: `Generate a shields.io-style SVG badge from the summary.json written by
+compare: a green "passing" badge when nothing differs, or a red "N changed"
+badge counting changed, added, and removed screenshots.
+
+Examples:
+
+  # Write badge.svg from the last local run
+  ods screenshot-diff badge --summary web/output/screenshot-diff/admin/summary.json
+
+  # Publish the badge next to the report for a README to link to
+  ods screenshot-diff badge --summary summary.json \
+    --dest s3://onyx-playwright-artifacts/reports/admin/badge.svg`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runBadge(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Summary, "summary", "", "summary.json written by compare (path or s3://...)")
+	cmd.Flags().StringVar(&opts.Out, "out", "badge.svg", "Output path for the SVG badge")
+	cmd.Flags().StringVar(&opts.Dest, "dest", "", "Also upload the badge to this S3 object URL (s3://...)")
+
+	return cmd
+}
+
+func runBadge(opts *ScreenshotDiffBadgeOptions) {
+	if opts.Summary == "" {
+		log.Fatal("--summary is required")
+	}
+	if opts.Dest != "" && !strings.HasPrefix(opts.Dest, "s3://") {
+		log.Fatalf("--dest must be an S3 URL (s3://...): %s", opts.Dest)
+	}
+	if opts.Out == "" {
+		log.Fatal("--out must not be empty")
+	}
+
+	summary, err := loadBaselineSummary(expandEnvPath(opts.Summary))
+	if err != nil {
+		log.Fatalf("Failed to load summary: %v", err)
+	}
+
+	out := expandEnvPath(opts.Out)
+	if err := imgdiff.GenerateBadge(summary, out); err != nil {
+		log.Fatalf("Failed to generate badge: %v", err)
+	}
+	log.Infof("Badge written to: %s", filepath.Clean(out))
+
+	if opts.Dest == "" {
+		return
+	}
+	err = s3.CopyUp(out, opts.Dest)
+	if s3.IsAuthError(err) && promptAWSLogin() {
+		err = s3.CopyUp(out, opts.Dest)
+	}
+	if err != nil {
+		log.Fatalf("Failed to upload badge: %v", err)
+	}
+	log.Infof("Badge uploaded to: %s", opts.Dest)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+)
+
+// ScreenshotDiffCalibrateOptions holds options for the calibrate subcommand.
+type ScreenshotDiffCalibrateOptions struct {
+	Project     string
+	Rev         string // revision whose baseline to calibrate against (default: "main")
+	Baseline    string
+	Current     string
+	Min         float64
+	Max         float64
+	Step        float64
+	IgnoreAlpha bool
+	Mask        string
+}
+
+func newCalibrateCommand() *cobra.Command {
+	opts := &ScreenshotDiffCalibrateOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "calibrate",
+		Short: "Show how many screenshots change at a range of thresholds",
+		Long: `Compare baseline and current screenshots at a range of --threshold values
+and print how many would be classified as changed at each, to help pick a
+threshold that separates real changes from rendering noise.
+
+Look for a plateau: the point where raising the threshold stops reducing
+the changed count is usually just above the noise floor.
+
+Only screenshots present on both sides are counted (added and removed
+screenshots are unaffected by the threshold).
+
+When --project is specified, the following defaults are applied:
+  --baseline  → s3://<bucket>/baselines/<project>/<rev>/
+  --current   → web/output/screenshots/
+  --rev       → main
+
+Examples:
+
+  # Calibrate against the main baseline
+  ods screenshot-diff calibrate --project admin
+
+  # Calibrate two local directories with a finer range
+  ods screenshot-diff calibrate --baseline ./baselines --current ./screenshots --max 0.2 --step 0.01`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runCalibrate(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin); sets sensible defaults for baseline and current")
+	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision baseline to compare against (default: main)")
+	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "Baseline directory, S3 URL (s3://...), or Azure Blob URL (az://...)")
+	cmd.Flags().StringVar(&opts.Current, "current", "", "Current screenshots directory")
+	cmd.Flags().Float64Var(&opts.Min, "min", 0.0, "Lowest threshold to try")
+	cmd.Flags().Float64Var(&opts.Max, "max", 0.5, "Highest threshold to try")
+	cmd.Flags().Float64Var(&opts.Step, "step", 0.05, "Threshold increment")
+	cmd.Flags().BoolVar(&opts.IgnoreAlpha, "ignore-alpha", false, "Compare RGB channels only, ignoring differences in alpha")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", "JSON file of regions to ignore, or to compare with a per-region threshold")
+
+	return cmd
+}
+
+func runCalibrate(opts *ScreenshotDiffCalibrateOptions) {
+	thresholds, err := imgdiff.ThresholdRange(opts.Min, opts.Max, opts.Step)
+	if err != nil {
+		log.Fatalf("Invalid threshold range: %v", err)
+	}
+
+	resolveCalibrateDefaults(opts)
+	if opts.Baseline == "" {
+		log.Fatal("--baseline is required (or use --project to set defaults)")
+	}
+	if opts.Current == "" {
+		log.Fatal("--current is required (or use --project to set defaults)")
+	}
+
+	compareOpts := imgdiff.CompareOptions{IgnoreAlpha: opts.IgnoreAlpha}
+	if opts.Mask != "" {
+		regions, err := imgdiff.LoadMask(expandEnvPath(opts.Mask))
+		if err != nil {
+			log.Fatalf("Invalid --mask: %v", err)
+		}
+		compareOpts.Regions = regions
+	}
+
+	baselineDir := opts.Baseline
+	if isRemoteURL(opts.Baseline) {
+		dir, err := downloadRemoteDir(opts.Baseline, baselineTempPattern)
+		if err != nil {
+			log.Fatalf("Failed to download baselines: %v", err)
+		}
+		defer removeTempDir(dir)
+		baselineDir = dir
+	}
+
+	log.Infof("Calibrating %d thresholds from %.2f to %.2f...", len(thresholds), opts.Min, opts.Max)
+	points, err := imgdiff.Calibrate(baselineDir, opts.Current, thresholds, compareOpts)
+	if err != nil {
+		log.Fatalf("Calibration failed: %v", err)
+	}
+
+	printCalibration(points)
+}
+
+// resolveCalibrateDefaults fills in missing flags from the --project default when set.
+func resolveCalibrateDefaults(opts *ScreenshotDiffCalibrateOptions) {
+	opts.Baseline = expandEnvPath(opts.Baseline)
+	opts.Current = expandEnvPath(opts.Current)
+
+	if opts.Project != "" {
+		rev := opts.Rev
+		if rev == "" {
+			rev = DefaultRev
+		}
+		if opts.Baseline == "" {
+			opts.Baseline = baselineS3URL(getS3Bucket(), opts.Project, rev)
+		}
+		if opts.Current == "" {
+			opts.Current = DefaultScreenshotDir
+		}
+	}
+}
+
+// printCalibration prints the calibration table with a bar per threshold.
+func printCalibration(points []imgdiff.CalibrationPoint) {
+	total := 0
+	if len(points) > 0 {
+		total = points[0].Changed + points[0].Unchanged
+	}
+	if total == 0 {
+		log.Warn("No screenshots exist in both directories; nothing to calibrate.")
+		return
+	}
+
+	const barWidth = 30
+	fmt.Println()
+	fmt.Printf("%-9s  %7s  %9s  %9s\n", "THRESHOLD", "CHANGED", "UNCHANGED", "MAX DIFF")
+	for _, p := range points {
+		bar := strings.Repeat("#", p.Changed*barWidth/total)
+		fmt.Printf("%-9.2f  %7d  %9d  %8.2f%%  %s\n", p.Threshold, p.Changed, p.Unchanged, p.MaxDiffPercent, bar)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+)
+
+// ScreenshotDiffCompare3Options holds options for the compare3 subcommand.
+type ScreenshotDiffCompare3Options struct {
+	Project     string
+	Rev         string // revision whose baseline to compare against (default: "main")
+	Baseline    string
+	Current     string
+	Reference   string
+	Output      string
+	Threshold   float64
+	IgnoreAlpha bool
+	Mask        string
+}
+
+func newCompare3Command() *cobra.Command {
+	opts := &ScreenshotDiffCompare3Options{}
+
+	cmd := &cobra.Command{
+		Use:   "compare3",
+		Short: "Compare current screenshots against a baseline and a reference",
+		Long: `Compare current screenshots against two other sets at once: the baseline
+and a reference. A typical use is PR head (--current) against the PR's base
+(--baseline) and against main's head (--reference), to tell regressions the
+PR introduced from changes it merely inherited.
+
+Statuses and the summary are those of the baseline comparison. Each changed
+card in the report additionally shows the reference image and the diff of
+current against the reference.
+
+--baseline and --reference may be local directories, S3 URLs (s3://...),
+or Azure Blob URLs (az://...).
+
+When --project is specified, the following defaults are applied:
+  --baseline  → s3://<bucket>/baselines/<project>/<rev>/
+  --current   → web/output/screenshots/
+  --output    → web/output/screenshot-diff/<project>/index.html
+  --rev       → main
+
+Examples:
+
+  # PR head against its base revision and against main
+  ods screenshot-diff compare3 --project admin --rev my-base-sha \
+    --reference s3://onyx-playwright-artifacts/baselines/admin/main/
+
+  # Three local directories
+  ods screenshot-diff compare3 --baseline ./base --current ./head --reference ./main`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runCompare3(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin); sets sensible defaults for baseline, current, and output")
+	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision baseline to compare against (default: main)")
+	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "Baseline directory, S3 URL (s3://...), or Azure Blob URL (az://...)")
+	cmd.Flags().StringVar(&opts.Current, "current", "", "Current screenshots directory")
+	cmd.Flags().StringVar(&opts.Reference, "reference", "", "Reference directory, S3 URL (s3://...), or Azure Blob URL (az://...)")
+	cmd.Flags().StringVar(&opts.Output, "output", "", "Output path for the HTML report")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", DefaultThreshold, "Per-channel pixel difference threshold (0.0-1.0)")
+	cmd.Flags().BoolVar(&opts.IgnoreAlpha, "ignore-alpha", false, "Compare RGB channels only, ignoring differences in alpha")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", "JSON file of regions to ignore, or to compare with a per-region threshold")
+
+	return cmd
+}
+
+func runCompare3(opts *ScreenshotDiffCompare3Options) {
+	resolveCompare3Defaults(opts)
+	if opts.Baseline == "" {
+		log.Fatal("--baseline is required (or use --project to set defaults)")
+	}
+	if opts.Current == "" {
+		log.Fatal("--current is required (or use --project to set defaults)")
+	}
+	if opts.Reference == "" {
+		log.Fatal("--reference is required")
+	}
+
+	compareOpts := imgdiff.CompareOptions{Threshold: opts.Threshold, IgnoreAlpha: opts.IgnoreAlpha}
+	if opts.Mask != "" {
+		regions, err := imgdiff.LoadMask(expandEnvPath(opts.Mask))
+		if err != nil {
+			log.Fatalf("Invalid --mask: %v", err)
+		}
+		compareOpts.Regions = regions
+	}
+
+	baselineDir := opts.Baseline
+	if isRemoteURL(opts.Baseline) {
+		dir, err := downloadRemoteDir(opts.Baseline, baselineTempPattern)
+		if err != nil {
+			log.Fatalf("Failed to download baselines: %v", err)
+		}
+		defer removeTempDir(dir)
+		baselineDir = dir
+	}
+
+	referenceDir := opts.Reference
+	if isRemoteURL(opts.Reference) {
+		dir, err := downloadRemoteDir(opts.Reference, referenceTempPattern)
+		if err != nil {
+			log.Fatalf("Failed to download reference: %v", err)
+		}
+		defer removeTempDir(dir)
+		referenceDir = dir
+	}
+
+	log.Infof("Comparing screenshots against baseline and reference...")
+	results, err := imgdiff.CompareThreeWay(baselineDir, opts.Current, referenceDir, compareOpts)
+	if err != nil {
+		log.Fatalf("Comparison failed: %v", err)
+	}
+	imgdiff.SortResults(results, imgdiff.SortByDiffPercent)
+
+	printSummary(results)
+
+	project := opts.Project
+	if project == "" {
+		project = "default"
+	}
+	summary := imgdiff.BuildSummary(project, results)
+	summaryPath := filepath.Join(filepath.Dir(opts.Output), "summary.json")
+	if err := imgdiff.WriteSummary(summary, summaryPath); err != nil {
+		log.Fatalf("Failed to write summary: %v", err)
+	}
+	log.Infof("Summary written to: %s", summaryPath)
+
+	if !summary.HasDifferences {
+		log.Infof("No visual differences detected — skipping report generation.")
+		return
+	}
+
+	log.Infof("Generating report: %s", opts.Output)
+	reportOpts := imgdiff.ReportOptions{Crop: compareOpts.Crop}
+	if err := imgdiff.GenerateReport(results, opts.Output, reportOpts); err != nil {
+		log.Fatalf("Failed to generate report: %v", err)
+	}
+	log.Infof("Report generated successfully: %s", opts.Output)
+}
+
+// resolveCompare3Defaults fills in missing flags from the --project default when set.
+func resolveCompare3Defaults(opts *ScreenshotDiffCompare3Options) {
+	opts.Baseline = expandEnvPath(opts.Baseline)
+	opts.Current = expandEnvPath(opts.Current)
+	opts.Reference = expandEnvPath(opts.Reference)
+	opts.Output = expandEnvPath(opts.Output)
+
+	if opts.Project != "" {
+		rev := opts.Rev
+		if rev == "" {
+			rev = DefaultRev
+		}
+		if opts.Baseline == "" {
+			opts.Baseline = baselineS3URL(getS3Bucket(), opts.Project, rev)
+		}
+		if opts.Current == "" {
+			opts.Current = DefaultScreenshotDir
+		}
+		if opts.Output == "" {
+			opts.Output = filepath.Join(DefaultOutputDir, opts.Project, "index.html")
+		}
+	}
+
+	if opts.Output == "" {
+		opts.Output = "screenshot-diff/index.html"
+	}
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/prompt"
+)
+
+// ScreenshotDiffGCOptions holds options for the gc subcommand.
+type ScreenshotDiffGCOptions struct {
+	OlderThan time.Duration // only remove temp directories last modified longer ago than this
+	DryRun    bool
+	Yes       bool
+}
+
+// staleTempDir is a leftover download directory found by gc.
+type staleTempDir struct {
+	Path string
+	Age  time.Duration
+	Size int64
+}
+
+func newGCCommand() *cobra.Command {
+	opts := &ScreenshotDiffGCOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove stale screenshot download directories from the temp directory",
+		Long: `Remove the screenshot-baseline-*, screenshot-current-*, and
+screenshot-reference-* directories that downloads leave in $TMPDIR when a
+run is killed too hard to clean up after itself (e.g. SIGKILL or an OOM).
+
+Only directories last modified more than --older-than ago are removed, so
+the downloads of runs still in progress are left alone.
+
+Examples:
+
+  # Preview what would be removed
+  ods screenshot-diff gc --dry-run
+
+  # Remove directories older than a week without prompting
+  ods screenshot-diff gc --older-than 168h --yes`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runGC(opts)
+		},
+	}
+
+	cmd.Flags().DurationVar(&opts.OlderThan, "older-than", 24*time.Hour, "Only remove directories last modified longer ago than this")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "List the directories that would be removed without removing them")
+	cmd.Flags().BoolVar(&opts.Yes, "yes", false, "Skip confirmation prompt")
+
+	return cmd
+}
+
+func runGC(opts *ScreenshotDiffGCOptions) {
+	if opts.OlderThan < 0 {
+		log.Fatal("--older-than must not be negative")
+	}
+
+	root := os.TempDir()
+	stale, err := findStaleTempDirs(root, opts.OlderThan, time.Now())
+	if err != nil {
+		log.Fatalf("Failed to scan %s: %v", root, err)
+	}
+	if len(stale) == 0 {
+		log.Infof("No screenshot temp directories older than %s in %s", opts.OlderThan, root)
+		return
+	}
+
+	var total int64
+	for _, d := range stale {
+		fmt.Printf("  %s  (%s, %s old)\n", d.Path, humanizeBytes(d.Size), d.Age.Round(time.Minute))
+		total += d.Size
+	}
+	if opts.DryRun {
+		log.Infof("Would remove %d director(ies), freeing %s", len(stale), humanizeBytes(total))
+		return
+	}
+
+	if !opts.Yes {
+		msg := fmt.Sprintf("This will DELETE %d director(ies) (%s). Continue? (yes/no): ", len(stale), humanizeBytes(total))
+		if !prompt.Confirm(msg) {
+			log.Info("Aborted.")
+			return
+		}
+	}
+
+	var freed int64
+	removed := 0
+	for _, d := range stale {
+		if err := os.RemoveAll(d.Path); err != nil {
+			log.Warnf("Failed to remove %s: %v", d.Path, err)
+			continue
+		}
+		freed += d.Size
+		removed++
+	}
+	log.Infof("Removed %d director(ies), freed %s", removed, humanizeBytes(freed))
+	if removed < len(stale) {
+		log.Fatalf("%d director(ies) could not be removed", len(stale)-removed)
+	}
+}
+
+// findStaleTempDirs returns the directories directly inside root that match
+// a download temp pattern and were last modified more than olderThan before
+// now, sorted by name.
+func findStaleTempDirs(root string, olderThan time.Duration, now time.Time) ([]staleTempDir, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []staleTempDir
+	for _, e := range entries {
+		if !e.IsDir() || !isDownloadTempDir(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // removed since the listing
+		}
+		age := now.Sub(info.ModTime())
+		if age <= olderThan {
+			continue
+		}
+		path := filepath.Join(root, e.Name())
+		stale = append(stale, staleTempDir{Path: path, Age: age, Size: dirSize(path)})
+	}
+	return stale, nil
+}
+
+// isDownloadTempDir reports whether name matches a pattern downloadRemoteDir
+// creates temp directories with.
+func isDownloadTempDir(name string) bool {
+	for _, pattern := range []string{baselineTempPattern, currentTempPattern, referenceTempPattern} {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize returns the total size of the regular files under dir, skipping
+// anything it cannot read.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/s3"
+)
+
+// ScreenshotDiffHistoryOptions holds options for the history subcommand.
+type ScreenshotDiffHistoryOptions struct {
+	Project     string
+	Name        string // screenshot path relative to the revision prefix, e.g. documents/list.png
+	Filmstrip   string // optional output path for a filmstrip PNG of every revision
+	FrameHeight int    // filmstrip frame height in pixels (0 = full size)
+}
+
+// screenshotRevision is one stored revision of a screenshot.
+type screenshotRevision struct {
+	Rev          string
+	URL          string
+	LastModified time.Time
+	Size         int64
+}
+
+func newHistoryCommand() *cobra.Command {
+	opts := &ScreenshotDiffHistoryOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List the stored revisions of a single screenshot",
+		Long: `List every revision under s3://<bucket>/baselines/<project>/ (or the
+layout set by --path-template) that contains a given screenshot, oldest
+first, with its last-modified time.
+
+With --filmstrip, each revision is downloaded and stitched left to right
+into a single PNG: a visual changelog for one page.
+
+Examples:
+
+  # List revisions of a screenshot
+  ods screenshot-diff history --project admin --name documents/list.png
+
+  # Also write a filmstrip image
+  ods screenshot-diff history --project admin --name documents/list.png --filmstrip list-history.png`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runHistory(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin)")
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Screenshot path within a revision (e.g. documents/list.png)")
+	cmd.Flags().StringVar(&opts.Filmstrip, "filmstrip", "", "Write a filmstrip PNG of every revision to this path")
+	cmd.Flags().IntVar(&opts.FrameHeight, "frame-height", 400, "Height of each filmstrip frame in pixels (0 = full size)")
+
+	return cmd
+}
+
+func runHistory(opts *ScreenshotDiffHistoryOptions) {
+	if opts.Project == "" {
+		log.Fatal("--project is required")
+	}
+	if opts.Name == "" {
+		log.Fatal("--name is required")
+	}
+	if opts.FrameHeight < 0 {
+		log.Fatal("--frame-height must not be negative")
+	}
+	opts.Filmstrip = expandEnvPath(opts.Filmstrip)
+
+	prefix, suffix := baselineRevisionPrefix(getS3Bucket(), opts.Project)
+	objects, err := s3.ListObjects(prefix)
+	if s3.IsAuthError(err) && promptAWSLogin() {
+		objects, err = s3.ListObjects(prefix)
+	}
+	if err != nil {
+		log.Fatalf("Failed to list %s: %v", prefix, err)
+	}
+
+	revisions := screenshotRevisions(objects, prefix, suffix, opts.Name)
+	if len(revisions) == 0 {
+		log.Fatalf("No revisions of %s found under %s", opts.Name, prefix)
+	}
+
+	fmt.Printf("%-30s  %-20s  %s\n", "REVISION", "LAST MODIFIED", "SIZE")
+	for _, r := range revisions {
+		fmt.Printf("%-30s  %-20s  %d\n", r.Rev, r.LastModified.Local().Format("2006-01-02 15:04:05"), r.Size)
+	}
+
+	if opts.Filmstrip == "" {
+		return
+	}
+
+	tmpDir, err := makeTempDir("screenshot-history-*")
+	if err != nil {
+		log.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer removeTempDir(tmpDir)
+
+	var paths []string
+	for i, r := range revisions {
+		local := filepath.Join(tmpDir, fmt.Sprintf("%03d.png", i))
+		if err := s3.FetchToFile(r.URL, local); err != nil {
+			log.Fatalf("Failed to download %s: %v", r.URL, err)
+		}
+		paths = append(paths, local)
+	}
+
+	if err := imgdiff.WriteFilmstrip(paths, opts.Filmstrip, opts.FrameHeight); err != nil {
+		log.Fatalf("Failed to write filmstrip: %v", err)
+	}
+	log.Infof("Filmstrip of %d revisions (oldest first) written to: %s", len(paths), opts.Filmstrip)
+}
+
+// screenshotRevisions picks out the objects that are the named screenshot
+// in some revision (<prefix><rev>/<suffix><name>), sorted oldest first.
+func screenshotRevisions(objects []s3.Object, prefix, suffix, name string) []screenshotRevision {
+	parsed, err := s3.ParseS3URL(prefix)
+	if err != nil {
+		return nil
+	}
+	keyPrefix := parsed.Key
+	name = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+
+	var revisions []screenshotRevision
+	for _, obj := range objects {
+		rest, ok := strings.CutPrefix(obj.Key, keyPrefix)
+		if !ok {
+			continue
+		}
+		rev, file, ok := strings.Cut(rest, "/")
+		if !ok || file != suffix+name {
+			continue
+		}
+		revisions = append(revisions, screenshotRevision{
+			Rev:          rev,
+			URL:          fmt.Sprintf("s3://%s/%s", parsed.Bucket, obj.Key),
+			LastModified: obj.LastModified,
+			Size:         obj.Size,
+		})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].LastModified.Before(revisions[j].LastModified)
+	})
+	return revisions
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+)
+
+// ScreenshotDiffOneOptions holds options for the diff-one subcommand.
+type ScreenshotDiffOneOptions struct {
+	Out             string // where to write the diff overlay PNG (empty = don't write one)
+	Threshold       float64
+	IgnoreAlpha     bool
+	Mask            string
+	OverlayBase     string
+	Palette         string
+	Crop            string
+	CropTop         int
+	IgnoreScrollbar int
+	MinRegionPixels int
+	SVGDPI          float64
+	PNGCompression  string // compression for the --out PNG: default, fast, or best
+
+	ComparePremultiplied bool
+	ThresholdAbs         float64 // -1 = use Threshold
+	NormalizeDPR         bool
+}
+
+func newDiffOneCommand() *cobra.Command {
+	opts := &ScreenshotDiffOneOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff-one <baseline> <current>",
+		Short: "Compare two image files and write their diff overlay",
+		Long: `Compare two specific screenshot files (PNG or SVG) with the same pixel
+comparison as compare, print the diff percentage and pixel count, and
+optionally write the diff overlay. Useful for one-off investigations
+without setting up baseline and current directories.
+
+Examples:
+
+  # Print how much two screenshots differ
+  ods screenshot-diff diff-one before.png after.png
+
+  # Also write the diff overlay, comparing more strictly
+  ods screenshot-diff diff-one before.png after.png --out diff.png --threshold 0.05`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			runDiffOne(args[0], args[1], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Out, "out", "", "Write the diff overlay PNG to this path")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", DefaultThreshold, "Per-channel pixel difference threshold (0.0-1.0)")
+	cmd.Flags().Float64Var(&opts.ThresholdAbs, "threshold-abs", -1, "Per-channel pixel difference threshold in 8-bit units (0-255), instead of --threshold; --threshold T equals --threshold-abs T*255 (-1 = use --threshold)")
+	cmd.MarkFlagsMutuallyExclusive("threshold", "threshold-abs")
+	cmd.Flags().BoolVar(&opts.IgnoreAlpha, "ignore-alpha", false, "Compare RGB channels only, ignoring differences in alpha")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", "JSON file of regions to ignore, or to compare with a per-region threshold")
+	cmd.Flags().StringVar(&opts.OverlayBase, "overlay-base", string(imgdiff.OverlayBaseCurrent), "What unchanged pixels show in the diff overlay: current or baseline (dimmed), or white or black")
+	cmd.Flags().StringVar(&opts.Palette, "palette", string(imgdiff.PaletteDefault), "Diff highlight colors: default (magenta), deuteranopia (orange), protanopia (sky blue), or high-contrast (cyan)")
+	cmd.Flags().StringVar(&opts.Crop, "crop", "", "Only compare this region, as x,y,w,h in pixels")
+	cmd.Flags().IntVar(&opts.CropTop, "crop-top", 0, "Ignore the top N pixels (e.g. a fixed header)")
+	cmd.Flags().IntVar(&opts.IgnoreScrollbar, "ignore-scrollbar", 0, "Ignore the rightmost N pixels, where scrollbars render differently across platforms")
+	cmd.Flags().IntVar(&opts.MinRegionPixels, "min-region-pixels", 0, "Only report a change when a connected cluster of differing pixels has at least this many pixels")
+	cmd.Flags().BoolVar(&opts.ComparePremultiplied, "compare-alpha-premultiplied", true, "Compare alpha-premultiplied channels; set to false to compare true colors of translucent pixels")
+	cmd.Flags().Float64Var(&opts.SVGDPI, "svg-dpi", imgdiff.DefaultSVGDPI, "Resolution .svg files are rasterized at before comparing (needs rsvg-convert or resvg)")
+	cmd.Flags().StringVar(&opts.PNGCompression, "png-compression", string(imgdiff.PNGCompressionDefault), "Compression for the --out PNG: default, fast, or best")
+	cmd.Flags().BoolVar(&opts.NormalizeDPR, "normalize-dpr", false, "When one file is an integer multiple of the other's size (a device pixel ratio change), scale the larger down before comparing")
+
+	return cmd
+}
+
+func runDiffOne(baselinePath, currentPath string, opts *ScreenshotDiffOneOptions) {
+	// Share flag validation with compare
+	compareOpts, err := compareOptions(&ScreenshotDiffCompareOptions{
+		Threshold:       opts.Threshold,
+		IgnoreAlpha:     opts.IgnoreAlpha,
+		Mask:            expandEnvPath(opts.Mask),
+		OverlayBase:     opts.OverlayBase,
+		Palette:         opts.Palette,
+		Crop:            opts.Crop,
+		CropTop:         opts.CropTop,
+		IgnoreScrollbar: opts.IgnoreScrollbar,
+		MinRegionPixels: opts.MinRegionPixels,
+		SVGDPI:          opts.SVGDPI,
+
+		ComparePremultiplied: opts.ComparePremultiplied,
+		ThresholdAbs:         opts.ThresholdAbs,
+		NormalizeDPR:         opts.NormalizeDPR,
+	})
+	if err != nil {
+		log.Fatalf("Invalid comparison options: %v", err)
+	}
+	compression, err := imgdiff.ParsePNGCompression(opts.PNGCompression)
+	if err != nil {
+		log.Fatalf("Invalid --png-compression: %v", err)
+	}
+
+	result, err := imgdiff.CompareFiles(expandEnvPath(baselinePath), expandEnvPath(currentPath), compareOpts)
+	if err != nil {
+		log.Fatalf("Comparison failed: %v", err)
+	}
+
+	fmt.Printf("%s: %.2f%% of pixels differ (%d of %d", result.Status, result.DiffPercent, result.DiffPixels, result.TotalPixels)
+	if result.Regions > 0 {
+		fmt.Printf(", in %d region(s)", result.Regions)
+	}
+	fmt.Println(")")
+	warnDPRChanges([]imgdiff.Result{*result}, opts.NormalizeDPR)
+	if result.Warning != "" {
+		log.Warn(result.Warning)
+	}
+
+	if opts.Out == "" {
+		return
+	}
+	if result.DiffImage == nil {
+		log.Warn("Both images are empty; no diff overlay to write")
+		return
+	}
+	out := expandEnvPath(opts.Out)
+	if err := imgdiff.SaveDiffImageWithCompression(result.DiffImage, out, compression); err != nil {
+		log.Fatalf("Failed to write diff image: %v", err)
+	}
+	log.Infof("Diff overlay written to: %s", out)
+}
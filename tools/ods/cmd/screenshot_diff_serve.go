@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+)
+
+// ScreenshotDiffServeOptions holds options for the serve subcommand.
+type ScreenshotDiffServeOptions struct {
+	Project     string
+	Rev         string // revision whose baseline to compare against (default: "main")
+	Baseline    string
+	Current     string
+	Port        int
+	Threshold   float64
+	IgnoreAlpha bool
+	Mask        string
+}
+
+func newServeCommand() *cobra.Command {
+	opts := &ScreenshotDiffServeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Compare screenshots and serve the report from a local web server",
+		Long: `Compare baseline and current screenshots, then serve the report over HTTP
+on localhost. Unlike the self-contained report written by compare, images
+are fetched lazily as cards scroll into view, so the page opens instantly
+even for suites with hundreds of changes.
+
+Press Ctrl-C to stop the server.
+
+When --project is specified, the following defaults are applied:
+  --baseline  → s3://<bucket>/baselines/<project>/<rev>/
+  --current   → web/output/screenshots/
+  --rev       → main
+
+Examples:
+
+  # Review local screenshots against the main baseline
+  ods screenshot-diff serve --project admin
+
+  # Serve a comparison of two local directories on another port
+  ods screenshot-diff serve --baseline ./baselines --current ./screenshots --port 9000`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runServe(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Project, "project", "", "Project name (e.g. admin); sets sensible defaults for baseline and current")
+	cmd.Flags().StringVar(&opts.Rev, "rev", "", "Revision baseline to compare against (default: main)")
+	cmd.Flags().StringVar(&opts.Baseline, "baseline", "", "Baseline directory, S3 URL (s3://...), or Azure Blob URL (az://...)")
+	cmd.Flags().StringVar(&opts.Current, "current", "", "Current screenshots directory")
+	cmd.Flags().IntVar(&opts.Port, "port", 8080, "Port to listen on (localhost only)")
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", DefaultThreshold, "Per-channel pixel difference threshold (0.0-1.0)")
+	cmd.Flags().BoolVar(&opts.IgnoreAlpha, "ignore-alpha", false, "Compare RGB channels only, ignoring differences in alpha")
+	cmd.Flags().StringVar(&opts.Mask, "mask", "", "JSON file of regions to ignore, or to compare with a per-region threshold")
+
+	return cmd
+}
+
+func runServe(opts *ScreenshotDiffServeOptions) {
+	resolveServeDefaults(opts)
+	if opts.Baseline == "" {
+		log.Fatal("--baseline is required (or use --project to set defaults)")
+	}
+	if opts.Current == "" {
+		log.Fatal("--current is required (or use --project to set defaults)")
+	}
+
+	compareOpts := imgdiff.CompareOptions{Threshold: opts.Threshold, IgnoreAlpha: opts.IgnoreAlpha}
+	if opts.Mask != "" {
+		regions, err := imgdiff.LoadMask(expandEnvPath(opts.Mask))
+		if err != nil {
+			log.Fatalf("Invalid --mask: %v", err)
+		}
+		compareOpts.Regions = regions
+	}
+
+	// The server reads baseline images on demand, so a downloaded baseline
+	// lives until the process exits (Ctrl-C removes it).
+	baselineDir := opts.Baseline
+	if isRemoteURL(opts.Baseline) {
+		dir, err := downloadRemoteDir(opts.Baseline, baselineTempPattern)
+		if err != nil {
+			log.Fatalf("Failed to download baselines: %v", err)
+		}
+		defer removeTempDir(dir)
+		baselineDir = dir
+	}
+
+	log.Infof("Comparing screenshots...")
+	results, err := imgdiff.CompareDirectoriesWithOptions(baselineDir, opts.Current, compareOpts)
+	if err != nil {
+		log.Fatalf("Comparison failed: %v", err)
+	}
+	imgdiff.SortResults(results, imgdiff.SortByDiffPercent)
+	printSummary(results)
+
+	handler, err := imgdiff.NewReportHandler(results, imgdiff.ReportOptions{Crop: compareOpts.Crop})
+	if err != nil {
+		log.Fatalf("Failed to render report: %v", err)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", opts.Port)
+	log.Infof("Serving report at http://%s/ (press Ctrl-C to stop)", addr)
+	if err := http.ListenAndServe(addr, handler); err != nil {
+		log.Fatalf("Server failed: %v", err)
+	}
+}
+
+// resolveServeDefaults fills in missing flags from the --project default when set.
+func resolveServeDefaults(opts *ScreenshotDiffServeOptions) {
+	opts.Baseline = expandEnvPath(opts.Baseline)
+	opts.Current = expandEnvPath(opts.Current)
+
+	if opts.Project != "" {
+		rev := opts.Rev
+		if rev == "" {
+			rev = DefaultRev
+		}
+		if opts.Baseline == "" {
+			opts.Baseline = baselineS3URL(getS3Bucket(), opts.Project, rev)
+		}
+		if opts.Current == "" {
+			opts.Current = DefaultScreenshotDir
+		}
+	}
+}
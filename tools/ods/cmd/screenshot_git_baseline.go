@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/git"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/imgdiff"
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/paths"
+)
+
+// gitBaselinePrefix marks a --baseline value of the form git:<ref> or
+// git:<ref>:<dir>, which reads the baseline from committed screenshots.
+const gitBaselinePrefix = "git:"
+
+// gitWorktreeRefs are the refs that, in a --current value of the form
+// git:worktree[:<dir>], name the working tree rather than a commit.
+var gitWorktreeRefs = []string{"worktree", "working-tree"}
+
+// isGitBaseline reports whether baseline refers to a git ref.
+func isGitBaseline(baseline string) bool {
+	return strings.HasPrefix(baseline, gitBaselinePrefix)
+}
+
+// parseGitBaseline splits git:<ref>[:<dir>] into its ref and directory. An
+// omitted directory is returned as "".
+func parseGitBaseline(baseline string) (ref, dir string, err error) {
+	ref, dir, _ = strings.Cut(strings.TrimPrefix(baseline, gitBaselinePrefix), ":")
+	if ref == "" {
+		return "", "", fmt.Errorf("invalid --baseline %q: expected git:<ref> or git:<ref>:<dir>", baseline)
+	}
+	return ref, dir, nil
+}
+
+// isGitWorktree reports whether current refers to the git working tree.
+func isGitWorktree(current string) bool {
+	if !strings.HasPrefix(current, gitBaselinePrefix) {
+		return false
+	}
+	ref, _, _ := strings.Cut(strings.TrimPrefix(current, gitBaselinePrefix), ":")
+	return slices.Contains(gitWorktreeRefs, ref)
+}
+
+// resolveGitWorktree returns the working-tree directory named by a --current
+// value of git:worktree[:<dir>]. Without a directory, it uses the one from a
+// git:<ref>:<dir> baseline, or DefaultScreenshotDir. Screenshots only in the
+// working tree are reported as added, and ones deleted from it as removed.
+func resolveGitWorktree(current, baseline string) (string, error) {
+	_, dir, _ := strings.Cut(strings.TrimPrefix(current, gitBaselinePrefix), ":")
+	if dir == "" && isGitBaseline(baseline) {
+		if _, baselineDir, err := parseGitBaseline(baseline); err == nil {
+			dir = baselineDir
+		}
+	}
+	if dir == "" {
+		dir = DefaultScreenshotDir
+	}
+
+	gitRoot, err := paths.GitRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to find git root: %w", err)
+	}
+	if git.HasUncommittedChanges() {
+		log.Infof("Comparing the working tree under %s, including uncommitted changes", dir)
+	} else {
+		log.Infof("No uncommitted changes to tracked files; comparing the working tree under %s", dir)
+	}
+	return filepath.Join(gitRoot, filepath.FromSlash(dir)), nil
+}
+
+// exportGitBaseline writes the screenshots committed at the ref named by
+// baseline into a temp directory and returns it. Without an explicit
+// directory, the screenshots are read from the same repository path as
+// currentDir. Screenshots missing at the ref are simply absent, so they are
+// reported as added.
+func exportGitBaseline(baseline, currentDir string) (string, error) {
+	ref, dir, err := parseGitBaseline(baseline)
+	if err != nil {
+		return "", err
+	}
+
+	if dir == "" {
+		if isRemoteURL(currentDir) {
+			return "", fmt.Errorf("--baseline %s needs a directory (git:%s:<dir>) when --current is remote", baseline, ref)
+		}
+		dir, err = repoRelativePath(currentDir)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	tmpDir, err := makeTempDir("screenshot-baseline-git-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	n, err := git.ExportDir(ref, dir, imgdiff.IsScreenshotFile, tmpDir)
+	if err != nil {
+		removeTempDir(tmpDir)
+		return "", err
+	}
+	if n == 0 {
+		log.Warnf("No screenshots committed under %s at %s", dir, ref)
+	}
+	log.Infof("Read %d baseline screenshot(s) from %s:%s", n, ref, dir)
+
+	return tmpDir, nil
+}
+
+// repoRelativePath returns path relative to the root of the git repository.
+func repoRelativePath(path string) (string, error) {
+	gitRoot, err := paths.GitRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to find git root: %w", err)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	// Resolve symlinks on both sides (e.g. /tmp on macOS) where possible
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(gitRoot); err == nil {
+		gitRoot = resolved
+	}
+
+	rel, err := filepath.Rel(gitRoot, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the git repository %s", path, gitRoot)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// changedScreenshots returns the screenshots whose sources changed since
+// base, per the --source-map file, for CompareOptions.Only. It returns nil
+// when a change maps to every screenshot, so the comparison is unrestricted.
+func changedScreenshots(base, sourceMapPath string) (map[string]bool, error) {
+	sourceMap, err := imgdiff.LoadSourceMap(expandEnvPath(sourceMapPath))
+	if err != nil {
+		return nil, err
+	}
+	changed, err := git.GetChangedFiles(base)
+	if err != nil {
+		return nil, err
+	}
+
+	names, all, unmapped := sourceMap.Screenshots(changed)
+	if len(unmapped) > 0 {
+		log.Debugf("Changed files not in the source map: %s", strings.Join(unmapped, ", "))
+	}
+	if all {
+		log.Infof("A change since %s affects every screenshot; comparing all of them", base)
+		return nil, nil
+	}
+	log.Infof("%d file(s) changed since %s (%d not in the source map); comparing only the %d screenshot(s) they map to",
+		len(changed), base, len(unmapped), len(names))
+	return names, nil
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Patterns of the temp directories screenshots are downloaded into (see
+// downloadRemoteDir). screenshot-diff gc removes stale ones matching them.
+const (
+	baselineTempPattern  = "screenshot-baseline-*"
+	currentTempPattern   = "screenshot-current-*"
+	referenceTempPattern = "screenshot-reference-*"
+)
+
+// tempDirRegistry tracks temp directories created by a command so they can
+// be removed even when the command is interrupted or exits via log.Fatal,
+// both of which skip deferred cleanup.
+type tempDirRegistry struct {
+	mu          sync.Mutex
+	dirs        map[string]struct{}
+	installOnce sync.Once
+}
+
+var tempDirs = &tempDirRegistry{dirs: make(map[string]struct{})}
+
+// makeTempDir creates a temp directory (see os.MkdirTemp) and registers it
+// for cleanup. Callers should defer removeTempDir for the normal exit path.
+func makeTempDir(pattern string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+
+	tempDirs.installOnce.Do(installTempDirCleanup)
+
+	tempDirs.mu.Lock()
+	tempDirs.dirs[dir] = struct{}{}
+	tempDirs.mu.Unlock()
+
+	return dir, nil
+}
+
+// removeTempDir deletes a registered temp directory. It is a no-op if the
+// directory was already cleaned up (e.g. by the signal handler), so the
+// deferred and interrupted paths never both remove the same directory.
+func removeTempDir(dir string) {
+	tempDirs.mu.Lock()
+	defer tempDirs.mu.Unlock()
+
+	if _, ok := tempDirs.dirs[dir]; !ok {
+		return
+	}
+	delete(tempDirs.dirs, dir)
+	_ = os.RemoveAll(dir)
+}
+
+// keepTempDir unregisters a temp directory without deleting it, for
+// debugging flags such as --keep-temp.
+func keepTempDir(dir string) {
+	tempDirs.mu.Lock()
+	defer tempDirs.mu.Unlock()
+
+	delete(tempDirs.dirs, dir)
+}
+
+// cleanupTempDirs removes every registered temp directory. The lock is held
+// while removing so a concurrent removeTempDir waits rather than racing.
+func cleanupTempDirs() {
+	tempDirs.mu.Lock()
+	defer tempDirs.mu.Unlock()
+
+	for dir := range tempDirs.dirs {
+		_ = os.RemoveAll(dir)
+		delete(tempDirs.dirs, dir)
+	}
+}
+
+// installTempDirCleanup removes registered temp directories on SIGINT or
+// SIGTERM (then exits with the conventional 128+signal status) and before
+// log.Fatal exits.
+func installTempDirCleanup() {
+	log.RegisterExitHandler(cleanupTempDirs)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		log.Warnf("Received %s, removing temp directories...", sig)
+		cleanupTempDirs()
+
+		code := 130
+		if sig == syscall.SIGTERM {
+			code = 143
+		}
+		os.Exit(code)
+	}()
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// NewVersionCommand creates the version command.
+func NewVersionCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Print ods build information",
+		Long: `Print the ods version, commit, Go version, and platform.
+
+The version and commit are injected at build time via -ldflags -X and
+default to "dev" and "none" for local builds. Include this output when
+reporting ods issues.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Print(versionInfo())
+		},
+	}
+
+	return cmd
+}
+
+// versionInfo returns the multi-line build information printed by `ods version`.
+func versionInfo() string {
+	return fmt.Sprintf("ods %s\ncommit: %s\ngo: %s\nplatform: %s/%s\n",
+		Version, Commit, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
@@ -3,6 +3,7 @@ module github.com/onyx-dot-app/onyx/tools/ods
 go 1.24.11
 
 require (
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/sirupsen/logrus v1.9.3
 	github.com/spf13/cobra v1.10.1
 )
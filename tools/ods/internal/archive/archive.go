@@ -0,0 +1,156 @@
+// Package archive extracts .zip and .tar.gz archives, such as CI build
+// artifacts, into a directory.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a supported archive format.
+type Format string
+
+const (
+	// FormatZip is a .zip archive.
+	FormatZip Format = "zip"
+	// FormatTarGz is a gzip-compressed tarball (.tar.gz or .tgz).
+	FormatTarGz Format = "tar.gz"
+)
+
+// FormatOf returns the archive format implied by a file name or URL path,
+// or false if it has no supported archive extension.
+func FormatOf(name string) (Format, bool) {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return FormatZip, true
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return FormatTarGz, true
+	}
+	return "", false
+}
+
+// ExtractFile extracts the archive at path into destDir. Entries that would
+// land outside destDir are rejected; symlinks and other special files are
+// skipped.
+func ExtractFile(path string, format Format, destDir string) error {
+	switch format {
+	case FormatZip:
+		return extractZip(path, destDir)
+	case FormatTarGz:
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		return extractTarGz(f, destDir)
+	}
+	return fmt.Errorf("unsupported archive format %q", format)
+}
+
+// ContentRoot returns the directory screenshots should be read from: dir
+// itself, or, when dir holds nothing but a single subdirectory (as when an
+// archive wraps its files in a top-level folder), that subdirectory,
+// repeatedly.
+func ContentRoot(dir string) (string, error) {
+	for {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return "", err
+		}
+		if len(entries) != 1 || !entries[0].IsDir() {
+			return dir, nil
+		}
+		dir = filepath.Join(dir, entries[0].Name())
+	}
+}
+
+func extractZip(path, destDir string) error {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open zip: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	for _, f := range r.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err := extractZipEntry(f, destDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipEntry(f *zip.File, destDir string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s from zip: %w", f.Name, err)
+	}
+	defer func() { _ = rc.Close() }()
+	return writeEntry(destDir, f.Name, rc)
+}
+
+func extractTarGz(r io.Reader, destDir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := writeEntry(destDir, hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// writeEntry writes one archive entry below destDir.
+func writeEntry(destDir, name string, r io.Reader) error {
+	target, err := entryPath(destDir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	return out.Close()
+}
+
+// entryPath returns where an archive entry is extracted, rejecting names
+// that escape destDir (e.g. "../../etc/passwd").
+func entryPath(destDir, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q is outside the destination directory", name)
+	}
+	return filepath.Join(destDir, clean), nil
+}
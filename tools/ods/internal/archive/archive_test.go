@@ -0,0 +1,131 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for name, want := range map[string]Format{
+		"artifact.zip":                        FormatZip,
+		"https://ci.example.com/a/BASE.ZIP":   FormatZip,
+		"baselines.tar.gz":                    FormatTarGz,
+		"baselines.tgz":                       FormatTarGz,
+		"https://ci.example.com/a/screenshot": "",
+	} {
+		got, ok := FormatOf(name)
+		if got != want || ok != (want != "") {
+			t.Errorf("FormatOf(%q) = %q, %v; want %q", name, got, ok, want)
+		}
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	files := map[string]string{
+		"screenshots/a.png":        "a",
+		"screenshots/nested/b.png": "b",
+	}
+
+	for _, tt := range []struct {
+		format Format
+		write  func(*testing.T, string, map[string]string)
+	}{
+		{FormatZip, writeZip},
+		{FormatTarGz, writeTarGz},
+	} {
+		t.Run(string(tt.format), func(t *testing.T) {
+			dir := t.TempDir()
+			archivePath := filepath.Join(dir, "archive")
+			tt.write(t, archivePath, files)
+
+			dest := filepath.Join(dir, "out")
+			if err := ExtractFile(archivePath, tt.format, dest); err != nil {
+				t.Fatalf("ExtractFile failed: %v", err)
+			}
+
+			for name, want := range files {
+				got, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+				if err != nil || string(got) != want {
+					t.Errorf("%s: expected %q, got %q (%v)", name, want, got, err)
+				}
+			}
+
+			root, err := ContentRoot(dest)
+			if err != nil {
+				t.Fatalf("ContentRoot failed: %v", err)
+			}
+			if root != filepath.Join(dest, "screenshots") {
+				t.Errorf("expected content root to descend into screenshots/, got %s", root)
+			}
+		})
+	}
+}
+
+func TestExtractFile_RejectsEscapingEntries(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "evil.zip")
+	writeZip(t, archivePath, map[string]string{"../escaped.png": "x"})
+
+	if err := ExtractFile(archivePath, FormatZip, filepath.Join(dir, "out")); err == nil {
+		t.Error("expected an error for an entry outside the destination")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escaped.png")); err == nil {
+		t.Error("escaping entry was written")
+	}
+}
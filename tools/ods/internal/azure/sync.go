@@ -0,0 +1,118 @@
+package azure
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// authHint is appended to azcopy failures to point users at the login flow.
+const authHint = "\n\nTo authenticate, run:\n  azcopy login\n\nOr set a SAS token on the URL / AZCOPY_* credentials in the environment"
+
+// IsBlobURL reports whether u refers to Azure Blob Storage, either as
+// az://<account>/<container>/<path> or https://<account>.blob.core.windows.net/...
+func IsBlobURL(u string) bool {
+	if strings.HasPrefix(u, "az://") {
+		return true
+	}
+	if !strings.HasPrefix(u, "https://") {
+		return false
+	}
+	host := strings.SplitN(strings.TrimPrefix(u, "https://"), "/", 2)[0]
+	return strings.HasSuffix(host, ".blob.core.windows.net")
+}
+
+// HTTPSURL converts an az://<account>/<container>/<path> URL into the
+// https://<account>.blob.core.windows.net/<container>/<path> form that
+// azcopy understands. HTTPS blob URLs are returned unchanged.
+func HTTPSURL(u string) (string, error) {
+	if !strings.HasPrefix(u, "az://") {
+		if IsBlobURL(u) {
+			return u, nil
+		}
+		return "", fmt.Errorf("invalid Azure Blob URL: must start with az:// or https://<account>.blob.core.windows.net/")
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(u, "az://"), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid Azure Blob URL: must be az://account/container[/path]")
+	}
+
+	https := fmt.Sprintf("https://%s.blob.core.windows.net/%s", parts[0], parts[1])
+	if len(parts) == 3 {
+		https += "/" + parts[2]
+	}
+	return https, nil
+}
+
+// SyncDown downloads a blob prefix to a local directory using azcopy.
+// This is equivalent to: azcopy sync <url> <destDir> --recursive
+func SyncDown(blobURL string, destDir string) error {
+	src, err := HTTPSURL(blobURL)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	log.Infof("Downloading from %s to %s ...", blobURL, destDir)
+	if err := runAzCopy("sync", src, destDir, "--recursive"); err != nil {
+		return fmt.Errorf("azcopy sync failed: %w%s", err, authHint)
+	}
+
+	return nil
+}
+
+// SyncUp uploads a local directory to a blob prefix using azcopy.
+// If delete is true, blobs that don't exist locally are removed.
+// This is equivalent to: azcopy sync <srcDir> <url> --recursive [--delete-destination=true]
+func SyncUp(srcDir string, blobURL string, delete bool) error {
+	dest, err := HTTPSURL(blobURL)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"sync", srcDir, dest, "--recursive"}
+	if delete {
+		args = append(args, "--delete-destination=true")
+	}
+
+	log.Infof("Uploading from %s to %s ...", srcDir, blobURL)
+	if err := runAzCopy(args...); err != nil {
+		return fmt.Errorf("azcopy sync failed: %w%s", err, authHint)
+	}
+
+	return nil
+}
+
+// CopyUp uploads a single local file to a blob URL using azcopy.
+// This is equivalent to: azcopy copy <srcPath> <url>
+func CopyUp(srcPath string, blobURL string) error {
+	dest, err := HTTPSURL(blobURL)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Uploading %s to %s", srcPath, blobURL)
+	if err := runAzCopy("copy", srcPath, dest); err != nil {
+		return fmt.Errorf("azcopy copy failed: %w%s", err, authHint)
+	}
+
+	return nil
+}
+
+// runAzCopy runs azcopy with the given arguments, streaming its output to
+// stderr so stdout stays clean for machine-readable output such as compare
+// --ndjson.
+func runAzCopy(args ...string) error {
+	log.Debugf("Running: azcopy %s", strings.Join(args, " "))
+	cmd := exec.Command("azcopy", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,29 @@
+package azure
+
+import "testing"
+
+func TestHTTPSURL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"az://acct/baselines/admin/main/", "https://acct.blob.core.windows.net/baselines/admin/main/", false},
+		{"az://acct/baselines", "https://acct.blob.core.windows.net/baselines", false},
+		{"https://acct.blob.core.windows.net/c/p", "https://acct.blob.core.windows.net/c/p", false},
+		{"az://acct", "", true},
+		{"s3://bucket/key", "", true},
+		{"https://example.com/c/p", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := HTTPSURL(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("HTTPSURL(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("HTTPSURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
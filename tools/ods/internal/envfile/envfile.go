@@ -0,0 +1,184 @@
+// Package envfile reads and edits docker compose style .env files while
+// leaving comments, blank lines, and unrelated entries untouched.
+package envfile
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Entry is a single KEY=VALUE assignment, with quotes already removed from
+// the value.
+type Entry struct {
+	Key   string
+	Value string
+}
+
+// keyPattern matches valid variable names.
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// secretMarkers are substrings of key names whose values should not be
+// printed in listings.
+var secretMarkers = []string{"PASSWORD", "SECRET", "TOKEN", "API_KEY", "PRIVATE_KEY", "CREDENTIAL", "LICENSE_KEY"}
+
+// ValidKey reports whether key is a valid variable name.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// LooksSecret reports whether key's value is likely a secret, based on its name.
+func LooksSecret(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse returns the entries in data, in file order. Comments, blank lines,
+// and malformed lines are skipped.
+func Parse(data []byte) []Entry {
+	var entries []Entry
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, raw, ok := splitLine(line); ok {
+			entries = append(entries, Entry{Key: key, Value: unquote(raw)})
+		}
+	}
+	return entries
+}
+
+// Get returns the value of key in data. If the key is assigned more than
+// once, the last assignment wins, as in docker compose.
+func Get(data []byte, key string) (string, bool) {
+	value, found := "", false
+	for _, e := range Parse(data) {
+		if e.Key == key {
+			value, found = e.Value, true
+		}
+	}
+	return value, found
+}
+
+// Set returns data with key set to value. Existing assignments are updated
+// in place, keeping any "export " prefix, the quote style of the old value,
+// and any inline "# comment" after it; otherwise the entry is appended.
+// Values that need quoting are double-quoted.
+func Set(data []byte, key, value string) []byte {
+	if len(data) == 0 {
+		return []byte(key + "=" + quote(value, 0) + "\n")
+	}
+
+	lines := strings.Split(string(data), "\n")
+	found := false
+	for i, line := range lines {
+		lineKey, raw, ok := splitLine(line)
+		if !ok || lineKey != key {
+			continue
+		}
+		prefix := line[:strings.Index(line, "=")+1]
+		old, comment := splitComment(raw)
+		lines[i] = prefix + quote(value, quoteChar(old)) + comment
+		found = true
+	}
+
+	if !found {
+		entry := key + "=" + quote(value, 0)
+		// Insert before the trailing empty line (if the file ended with \n)
+		// so we don't accumulate blank lines.
+		if lines[len(lines)-1] == "" {
+			lines = append(lines[:len(lines)-1], entry, "")
+		} else {
+			lines = append(lines, entry)
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// splitLine parses an assignment line into its key and raw (still quoted)
+// value.
+func splitLine(line string) (key, raw string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", "", false
+	}
+	trimmed = strings.TrimPrefix(trimmed, "export ")
+
+	key, raw, ok = strings.Cut(trimmed, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !ValidKey(key) {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(raw), true
+}
+
+// quoteChar returns the quote character wrapping raw, or 0 if it is unquoted.
+func quoteChar(raw string) byte {
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return raw[0]
+	}
+	return 0
+}
+
+// splitComment splits raw into the value itself and a trailing inline
+// comment with the whitespace before it, e.g. `"a" # note` into `"a"` and
+// ` # note`. A # inside quotes, or not preceded by whitespace, is part of
+// the value.
+func splitComment(raw string) (value, comment string) {
+	if len(raw) > 0 && (raw[0] == '"' || raw[0] == '\'') {
+		for i := 1; i < len(raw); i++ {
+			if raw[0] == '"' && raw[i] == '\\' {
+				i++ // skip the escaped character
+				continue
+			}
+			if raw[i] == raw[0] {
+				if rest := raw[i+1:]; strings.HasPrefix(strings.TrimLeft(rest, " \t"), "#") {
+					return raw[:i+1], rest
+				}
+				break
+			}
+		}
+		return raw, ""
+	}
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			end := len(strings.TrimRight(raw[:i], " \t"))
+			return raw[:end], raw[end:]
+		}
+	}
+	return raw, ""
+}
+
+// unquote strips any inline comment and surrounding quotes from raw.
+func unquote(raw string) string {
+	raw, _ = splitComment(raw)
+	switch quoteChar(raw) {
+	case '"':
+		inner := raw[1 : len(raw)-1]
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n").Replace(inner)
+	case '\'':
+		return raw[1 : len(raw)-1]
+	}
+	return raw
+}
+
+// quote formats value for writing, using the given quote character, or
+// double quotes when an unquoted value would be misread.
+func quote(value string, char byte) string {
+	if char == 0 && strings.ContainsAny(value, " \t#\"'\\\n") {
+		char = '"'
+	}
+	switch char {
+	case '"':
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+		return `"` + escaped + `"`
+	case '\'':
+		if !strings.Contains(value, "'") {
+			return "'" + value + "'"
+		}
+		return quote(value, '"')
+	}
+	return value
+}
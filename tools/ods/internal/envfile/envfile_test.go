@@ -0,0 +1,108 @@
+package envfile
+
+import "testing"
+
+const sample = `# Onyx compose settings
+IMAGE_TAG=edge
+export AUTH_TYPE=disabled # local only
+POSTGRES_PASSWORD="p@ss word"
+GREETING='hello'
+
+IMAGE_TAG=latest
+QUOTED_NOTE="x # y" # a comment
+`
+
+func TestParse(t *testing.T) {
+	entries := Parse([]byte(sample))
+	want := []Entry{
+		{"IMAGE_TAG", "edge"},
+		{"AUTH_TYPE", "disabled"},
+		{"POSTGRES_PASSWORD", "p@ss word"},
+		{"GREETING", "hello"},
+		{"IMAGE_TAG", "latest"},
+		{"QUOTED_NOTE", "x # y"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %d: %+v", len(want), len(entries), entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want[i], entries[i])
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if v, ok := Get([]byte(sample), "IMAGE_TAG"); !ok || v != "latest" {
+		t.Errorf("expected last assignment \"latest\", got %q (found=%v)", v, ok)
+	}
+	if _, ok := Get([]byte(sample), "MISSING"); ok {
+		t.Error("expected MISSING to be absent")
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		key   string
+		value string
+		want  string
+	}{
+		{"empty file", "", "A", "1", "A=1\n"},
+		{"append keeps trailing newline", "# c\nA=1\n", "B", "2", "# c\nA=1\nB=2\n"},
+		{"append without trailing newline", "A=1", "B", "2", "A=1\nB=2"},
+		{"update in place", "# c\nA=1\nB=2\n", "A", "3", "# c\nA=3\nB=2\n"},
+		{"keeps export prefix", "export A=1\n", "A", "2", "export A=2\n"},
+		{"keeps double quotes", `A="x"` + "\n", "A", "y", `A="y"` + "\n"},
+		{"keeps single quotes", "A='x'\n", "A", "y", "A='y'\n"},
+		{"quotes when needed", "", "A", "two words", `A="two words"` + "\n"},
+		{"escapes quotes", "", "A", `say "hi"`, `A="say \"hi\""` + "\n"},
+		{"updates every assignment", "A=1\nA=2\n", "A", "3", "A=3\nA=3\n"},
+		{"keeps inline comment", "A=1 # note\n", "A", "2", "A=2 # note\n"},
+		{"keeps quotes and inline comment", `A="a" # note` + "\n", "A", "b", `A="b" # note` + "\n"},
+		{"keeps single quotes and inline comment", "A='a'\t# note\n", "A", "b", "A='b'\t# note\n"},
+		{"# inside quotes is not a comment", `A="a # b"` + "\n", "A", "c", `A="c"` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(Set([]byte(tt.data), tt.key, tt.value))
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+			if v, _ := Get([]byte(got), tt.key); v != tt.value {
+				t.Errorf("round trip: expected %q, got %q", tt.value, v)
+			}
+		})
+	}
+}
+
+func TestLooksSecret(t *testing.T) {
+	for key, want := range map[string]bool{
+		"POSTGRES_PASSWORD":     true,
+		"OPENAI_API_KEY":        true,
+		"USER_AUTH_SECRET":      true,
+		"IMAGE_TAG":             false,
+		"AUTH_TYPE":             false,
+		"LICENSE_ENFORCEMENT_X": false,
+	} {
+		if got := LooksSecret(key); got != want {
+			t.Errorf("LooksSecret(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"IMAGE_TAG": true,
+		"_X1":       true,
+		"1X":        false,
+		"A-B":       false,
+		"":          false,
+	} {
+		if got := ValidKey(key); got != want {
+			t.Errorf("ValidKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+// Package filelock provides exclusive locks on files, shared between
+// processes, for serializing read-modify-write cycles on shared outputs and
+// for capping work across concurrent ods processes.
+package filelock
+
+import "time"
+
+// pollInterval is how often Lock retries where the platform cannot block.
+var pollInterval = 100 * time.Millisecond
@@ -0,0 +1,37 @@
+//go:build !unix
+
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Lock blocks until it holds an exclusive lock on path, by creating it
+// exclusively, and returns a func that releases it by removing the file. A
+// process killed while holding the lock leaves the file behind until it is
+// deleted by hand.
+func Lock(path string) (unlock func(), err error) {
+	for {
+		unlock, ok, err := TryLock(path)
+		if err != nil || ok {
+			return unlock, err
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// TryLock is Lock without blocking: ok is false when path already exists.
+func TryLock(path string) (unlock func(), ok bool, err error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	_ = f.Close()
+	return func() { _ = os.Remove(path) }, true, nil
+}
@@ -0,0 +1,59 @@
+package filelock
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json.lock")
+
+	unlock, err := Lock(path)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := Lock(path)
+		if err != nil {
+			t.Errorf("second Lock failed: %v", err)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Lock should block while the lock is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case second := <-acquired:
+		second()
+	case <-time.After(5 * time.Second):
+		t.Fatal("second Lock should proceed once the lock is released")
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slot-0.lock")
+
+	unlock, ok, err := TryLock(path)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v; want the lock", ok, err)
+	}
+	if _, ok, err := TryLock(path); err != nil || ok {
+		t.Errorf("second TryLock = %v, %v; want not ok while the lock is held", ok, err)
+	}
+
+	unlock()
+	second, ok, err := TryLock(path)
+	if err != nil || !ok {
+		t.Fatalf("TryLock after unlock = %v, %v; want the lock", ok, err)
+	}
+	second()
+}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -72,6 +73,45 @@ func GetCommitMessage(commitSHA string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// GetCommitBody gets the body of a commit message (everything after the
+// subject line), or "" if there is none
+func GetCommitBody(commitSHA string) (string, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%b", commitSHA)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetCommitMessageFull gets the full commit message: the subject, then the
+// body (if any) after a blank line
+func GetCommitMessageFull(commitSHA string) (string, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%B", commitSHA)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetChangedFiles lists the files changed on HEAD since it diverged from
+// base (git diff --name-only base...HEAD), relative to the repository root
+func GetChangedFiles(base string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", base+"...HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff --name-only %s...HEAD failed: %w", base, err)
+	}
+	var files []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
 // BranchExists checks if a local git branch exists
 func BranchExists(branchName string) bool {
 	cmd := exec.Command("git", "show-ref", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branchName))
@@ -105,16 +145,29 @@ func StashChanges() (*StashResult, error) {
 	return result, nil
 }
 
-// RestoreStash restores previously stashed changes
-func RestoreStash(result *StashResult) {
+// ErrStashConflict is returned by RestoreStash when the stashed changes
+// conflict with the checked-out branch.
+var ErrStashConflict = errors.New("stashed changes conflict with the current branch")
+
+// RestoreStash restores previously stashed changes. It returns
+// ErrStashConflict if popping the stash left conflicts in the worktree; the
+// stash entry is then kept, as git does.
+func RestoreStash(result *StashResult) error {
 	if result == nil || !result.Stashed {
-		return
+		return nil
 	}
 	log.Info("Restoring stashed changes...")
 	if err := RunCommand("stash", "pop"); err != nil {
-		log.Warnf("Failed to restore stashed changes (may have conflicts): %v", err)
+		if HasMergeConflict() {
+			log.Warn("Restoring stashed changes left conflicts in the worktree.")
+			log.Info("Resolve them, then run 'git stash drop' (the stash entry is kept until you do).")
+			return ErrStashConflict
+		}
+		log.Warnf("Failed to restore stashed changes: %v", err)
 		log.Info("Your changes are still in the stash. Run 'git stash pop' to restore them manually.")
+		return fmt.Errorf("failed to restore stashed changes: %w", err)
 	}
+	return nil
 }
 
 // CommitExistsOnBranch checks if a commit exists on a branch
@@ -144,18 +197,25 @@ func FetchCommits(commitSHAs []string) error {
 		log.Infof("Fetching %d commits from origin", len(commitSHAs))
 	}
 
-	// Try to fetch all specific commits at once - this works if the remote allows it
+	// Try to fetch all specific commits at once - this works if the remote allows it.
+	// Transient network errors are retried; "commit not found" style errors are not.
 	args := append([]string{"fetch", "--quiet", "origin"}, commitSHAs...)
-	if err := RunCommand(args...); err != nil {
+	if err := WithRetry(func() error { return RunCommandVerboseOnError(args...) }); err != nil {
 		// Fall back to fetching all refs if specific commit fetch fails
 		log.Debugf("Specific commit fetch failed, fetching all: %v", err)
-		if err := RunCommand("fetch", "--quiet", "origin"); err != nil {
+		if err := FetchWithRetry("--quiet", "origin"); err != nil {
 			return fmt.Errorf("failed to fetch from origin: %w", err)
 		}
 	}
 	return nil
 }
 
+// FetchWithRetry runs "git fetch <args>", retrying transient network failures.
+func FetchWithRetry(args ...string) error {
+	fetchArgs := append([]string{"fetch"}, args...)
+	return WithRetry(func() error { return RunCommandVerboseOnError(fetchArgs...) })
+}
+
 // HasMergeConflict checks if the repository is in a merge conflict state
 func HasMergeConflict() bool {
 	// Check if there are unmerged files (indicates merge conflict)
@@ -189,6 +249,47 @@ func GetGitDir() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// ExportDir writes the files directly inside dir (relative to the repository
+// root) as of ref into destDir, keeping their base names. Only files whose
+// names keep accepts are written; a nil keep writes every file. It returns
+// the number of files written, which is zero when dir does not exist at ref.
+func ExportDir(ref, dir string, keep func(name string) bool, destDir string) (int, error) {
+	pathspec := strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+	if dir == "" || dir == "." {
+		pathspec = "."
+	}
+
+	cmd := exec.Command("git", "ls-tree", "-z", "--full-tree", ref, "--", pathspec)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("git ls-tree %s failed: %w", ref, err)
+	}
+
+	written := 0
+	// Entries are "<mode> <type> <object>\t<path>", NUL-terminated
+	for _, entry := range strings.Split(string(output), "\x00") {
+		meta, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		if keep != nil && !keep(filepath.Base(path)) {
+			continue
+		}
+
+		data, err := exec.Command("git", "cat-file", "blob", fields[2]).Output()
+		if err != nil {
+			return written, fmt.Errorf("git cat-file %s:%s failed: %w", ref, path, err)
+		}
+		if err := os.WriteFile(filepath.Join(destDir, filepath.Base(path)), data, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written++
+	}
+
+	return written, nil
+}
+
 // IsCommitAppliedOnBranch checks if a commit (or its cherry-picked equivalent) exists on a branch.
 // First tries exact SHA match, then falls back to matching by commit subject line.
 func IsCommitAppliedOnBranch(commitSHA, branchName string) bool {
@@ -1,6 +1,7 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -178,3 +179,174 @@ func TestIsCommitAppliedOnBranch_NoFalsePositiveFromBody(t *testing.T) {
 		t.Error("should NOT match when subject only appears in body of another commit")
 	}
 }
+
+// --- Commit message tests ---
+
+func TestGetCommitMessageFull(t *testing.T) {
+	repo := newTestRepo(t)
+	sha := repo.Commit("fix: handle empty input\n\nThe parser crashed on empty files.\n\nCloses #42", "f.txt", "f")
+
+	subject, err := GetCommitMessage(sha)
+	if err != nil || subject != "fix: handle empty input" {
+		t.Errorf("GetCommitMessage = %q, %v", subject, err)
+	}
+
+	body, err := GetCommitBody(sha)
+	if err != nil || body != "The parser crashed on empty files.\n\nCloses #42" {
+		t.Errorf("GetCommitBody = %q, %v", body, err)
+	}
+
+	full, err := GetCommitMessageFull(sha)
+	want := "fix: handle empty input\n\nThe parser crashed on empty files.\n\nCloses #42"
+	if err != nil || full != want {
+		t.Errorf("GetCommitMessageFull = %q, %v; want %q", full, err, want)
+	}
+}
+
+func TestGetCommitBody_SubjectOnly(t *testing.T) {
+	repo := newTestRepo(t)
+	sha := repo.Commit("chore: bump version", "v.txt", "1")
+
+	if body, err := GetCommitBody(sha); err != nil || body != "" {
+		t.Errorf("GetCommitBody = %q, %v; want empty", body, err)
+	}
+	if full, err := GetCommitMessageFull(sha); err != nil || full != "chore: bump version" {
+		t.Errorf("GetCommitMessageFull = %q, %v", full, err)
+	}
+}
+
+func TestGetCommitMessageFull_UnknownCommit(t *testing.T) {
+	newTestRepo(t)
+	if _, err := GetCommitMessageFull("0000000000000000000000000000000000000000"); err == nil {
+		t.Error("expected an error for an unknown commit")
+	}
+}
+
+// --- Stash tests ---
+
+func TestGetChangedFiles(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.Git("checkout", "-b", "feature")
+	repo.Commit("add page", "page.tsx", "page")
+	repo.Commit("edit readme", "README.md", "edited")
+	// Commits on main after the branch point are not part of the PR
+	repo.Git("checkout", "main")
+	repo.Commit("unrelated", "other.tsx", "other")
+	repo.Git("checkout", "feature")
+
+	files, err := GetChangedFiles("main")
+	if err != nil {
+		t.Fatalf("GetChangedFiles: %v", err)
+	}
+	if strings.Join(files, ",") != "README.md,page.tsx" {
+		t.Errorf("GetChangedFiles = %v, want [README.md page.tsx]", files)
+	}
+}
+
+func TestGetChangedFiles_UnknownBase(t *testing.T) {
+	newTestRepo(t)
+	if _, err := GetChangedFiles("no-such-branch"); err == nil {
+		t.Error("expected an error for an unknown base")
+	}
+}
+
+func TestStashAndRestore(t *testing.T) {
+	repo := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repo.Dir, "README.md"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := StashChanges()
+	if err != nil || !result.Stashed {
+		t.Fatalf("StashChanges = %+v, %v; want stashed", result, err)
+	}
+	if HasUncommittedChanges() {
+		t.Fatal("expected a clean worktree after stashing")
+	}
+
+	if err := RestoreStash(result); err != nil {
+		t.Fatalf("RestoreStash failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo.Dir, "README.md")); string(data) != "edited" {
+		t.Errorf("README.md = %q after restore, want %q", data, "edited")
+	}
+}
+
+func TestRestoreStash_Conflict(t *testing.T) {
+	repo := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(repo.Dir, "README.md"), []byte("local edit"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := StashChanges()
+	if err != nil || !result.Stashed {
+		t.Fatalf("StashChanges = %+v, %v; want stashed", result, err)
+	}
+
+	// A commit touching the same lines makes the pop conflict
+	repo.Commit("conflicting change", "README.md", "committed edit")
+
+	if err := RestoreStash(result); !errors.Is(err, ErrStashConflict) {
+		t.Errorf("RestoreStash = %v, want ErrStashConflict", err)
+	}
+	if repo.Git("stash", "list") == "" {
+		t.Error("expected the stash entry to be kept after a conflict")
+	}
+}
+
+func TestRestoreStash_NothingStashed(t *testing.T) {
+	newTestRepo(t)
+	if err := RestoreStash(&StashResult{}); err != nil {
+		t.Errorf("RestoreStash with nothing stashed = %v, want nil", err)
+	}
+}
+
+func TestExportDir(t *testing.T) {
+	r := newTestRepo(t)
+	if err := os.MkdirAll(filepath.Join(r.Dir, "shots", "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r.Commit("add shots", "shots/a.png", "a-v1")
+	r.Commit("add icon", "shots/icon.SVG", "<svg/>")
+	r.Commit("add notes", "shots/notes.txt", "notes")
+	r.Commit("add nested", "shots/nested/b.png", "b")
+	ref := r.HEAD()
+	r.Commit("update a", "shots/a.png", "a-v2")
+
+	isImage := func(name string) bool {
+		ext := strings.ToLower(filepath.Ext(name))
+		return ext == ".png" || ext == ".svg"
+	}
+
+	dest := t.TempDir()
+	n, err := ExportDir(ref, "shots", isImage, dest)
+	if err != nil {
+		t.Fatalf("ExportDir: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 files exported, got %d", n)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "a.png"))
+	if err != nil || string(data) != "a-v1" {
+		t.Errorf("expected a.png as of ref (a-v1), got %q (err %v)", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "icon.SVG")); err != nil || string(data) != "<svg/>" {
+		t.Errorf("expected the committed SVG to be exported, got %q (err %v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected notes.txt to be filtered out, got err %v", err)
+	}
+
+	all := t.TempDir()
+	if n, err := ExportDir(ref, "shots", nil, all); err != nil || n != 3 {
+		t.Errorf("expected a nil filter to export all 3 files, got %d (err %v)", n, err)
+	}
+
+	n, err = ExportDir(ref, "missing", isImage, t.TempDir())
+	if err != nil || n != 0 {
+		t.Errorf("expected no files for a missing directory, got %d (err %v)", n, err)
+	}
+
+	if _, err := ExportDir("no-such-ref", "shots", isImage, t.TempDir()); err == nil {
+		t.Error("expected error for an unknown ref")
+	}
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RetryAttempts is the total number of attempts made for git network
+// operations wrapped with WithRetry. Values below 1 are treated as 1.
+var RetryAttempts = 3
+
+// retryBaseDelay is the wait before the first retry; it doubles after each
+// failed attempt. It is a variable so tests can shorten it.
+var retryBaseDelay = 2 * time.Second
+
+// transientErrorMarkers are substrings of git error output that indicate a
+// network or server hiccup worth retrying.
+var transientErrorMarkers = []string{
+	"could not resolve host",
+	"connection reset",
+	"connection refused",
+	"timed out",
+	"early eof",
+	"the remote end hung up unexpectedly",
+	"rpc failed",
+	"unable to access",
+	"gnutls_handshake",
+	"ssl_read",
+	"temporary failure",
+	"returned error: 5",
+}
+
+// permanentErrorMarkers are substrings of git error output that indicate the
+// request itself is bad (e.g. the commit does not exist), so retrying is pointless.
+var permanentErrorMarkers = []string{
+	"not our ref",
+	"couldn't find remote ref",
+	"no such remote ref",
+	"invalid refspec",
+	"bad object",
+	"unadvertised object",
+	"repository not found",
+	"authentication failed",
+	"permission denied",
+}
+
+// IsTransientError reports whether err looks like a transient network failure.
+// Errors that clearly indicate a missing commit or bad ref are never transient.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range permanentErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return false
+		}
+	}
+	for _, marker := range transientErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRetry runs fn up to RetryAttempts times with exponential backoff,
+// retrying only while the error is transient. The last error is returned.
+// fn should include git's stderr in its error (see RunCommandVerboseOnError)
+// so the failure can be classified.
+func WithRetry(fn func() error) error {
+	attempts := max(RetryAttempts, 1)
+	delay := retryBaseDelay
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = fn()
+		if err == nil || !IsTransientError(err) || attempt == attempts {
+			return err
+		}
+		log.Warnf("Git network operation failed (attempt %d/%d), retrying in %s: %v",
+			attempt, attempts, delay, firstLine(err.Error()))
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return err
+}
+
+// firstLine returns the first line of s, for compact log messages.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// installFakeGit puts a fake "git" script first on PATH. The script appends
+// its arguments to a log file and runs the given body, which can read the
+// invocation number from $n.
+func installFakeGit(t *testing.T, body string) (logPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	logPath = filepath.Join(dir, "calls.log")
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" >> " + logPath + "\n" +
+		"n=$(wc -l < " + logPath + " | tr -d ' ')\n" +
+		body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "git"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	origDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = origDelay })
+
+	return logPath
+}
+
+func readCalls(t *testing.T, logPath string) []string {
+	t.Helper()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestFetchCommits_RetriesTransientErrorThenSucceeds(t *testing.T) {
+	logPath := installFakeGit(t, `if [ "$n" -eq 1 ]; then
+  echo "fatal: unable to access 'https://github.com/x/y/': Could not resolve host: github.com" >&2
+  exit 128
+fi
+exit 0`)
+
+	if err := FetchCommits([]string{"abc123"}); err != nil {
+		t.Fatalf("FetchCommits failed: %v", err)
+	}
+
+	calls := readCalls(t, logPath)
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 git invocations (fail, retry), got %d: %v", len(calls), calls)
+	}
+	for _, c := range calls {
+		if c != "fetch --quiet origin abc123" {
+			t.Errorf("expected specific-commit fetch on every attempt, got %q", c)
+		}
+	}
+}
+
+func TestFetchCommits_DoesNotRetryMissingCommit(t *testing.T) {
+	logPath := installFakeGit(t, `if [ "$n" -eq 1 ]; then
+  echo "fatal: remote error: upload-pack: not our ref abc123" >&2
+  exit 128
+fi
+exit 0`)
+
+	if err := FetchCommits([]string{"abc123"}); err != nil {
+		t.Fatalf("FetchCommits failed: %v", err)
+	}
+
+	calls := readCalls(t, logPath)
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 git invocations (specific, fallback), got %d: %v", len(calls), calls)
+	}
+	if calls[1] != "fetch --quiet origin" {
+		t.Errorf("expected fallback to full fetch without retrying, got %q", calls[1])
+	}
+}
+
+func TestWithRetry_GivesUpAfterAttempts(t *testing.T) {
+	origDelay, origAttempts := retryBaseDelay, RetryAttempts
+	retryBaseDelay, RetryAttempts = time.Millisecond, 3
+	t.Cleanup(func() { retryBaseDelay, RetryAttempts = origDelay, origAttempts })
+
+	calls := 0
+	err := WithRetry(func() error {
+		calls++
+		return errors.New("exit status 128\nfatal: the remote end hung up unexpectedly")
+	})
+	if err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
@@ -0,0 +1,40 @@
+package imgdiff
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// SelectAccepted returns the results that accepting should copy into the
+// baseline: the changed and added ones, narrowed to those whose name matches
+// one of patterns (filepath.Match globs) when any are given. Unchanged,
+// removed, and errored results are never accepted.
+func SelectAccepted(results []Result, patterns []string) ([]Result, error) {
+	for _, p := range patterns {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	var accepted []Result
+	for _, r := range results {
+		if r.Status != StatusChanged && r.Status != StatusAdded {
+			continue
+		}
+		if len(patterns) > 0 && !matchesAny(patterns, r.Name) {
+			continue
+		}
+		accepted = append(accepted, r)
+	}
+	return accepted, nil
+}
+
+// matchesAny reports whether name matches any of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
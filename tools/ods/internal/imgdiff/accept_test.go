@@ -0,0 +1,78 @@
+package imgdiff
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSelectAccepted(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+	red := color.RGBA{R: 255, A: 255}
+
+	createTestPNG(t, filepath.Join(baselineDir, "changed.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "changed.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(baselineDir, "other-changed.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "other-changed.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(baselineDir, "same.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "same.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(baselineDir, "removed.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "added.png"), 10, 10, color.White)
+	// Only differs outside the crop, so it is unchanged for the comparison below
+	createTestPNG(t, filepath.Join(baselineDir, "cropped.png"), 10, 10, color.White)
+	createTestPNGWithBlock(t, filepath.Join(currentDir, "cropped.png"), 10, 10, color.White, red, 0, 0, 10, 2)
+
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		Crop:      image.Rect(0, 5, 10, 10),
+	})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+
+	names := func(rs []Result) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+
+	accepted, err := SelectAccepted(results, nil)
+	if err != nil {
+		t.Fatalf("SelectAccepted failed: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, name := range names(accepted) {
+		got[name] = true
+	}
+	want := map[string]bool{"changed.png": true, "other-changed.png": true, "added.png": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected exactly the changed and added screenshots %v, got %v", want, got)
+	}
+
+	accepted, err = SelectAccepted(results, []string{"changed.png", "add*"})
+	if err != nil {
+		t.Fatalf("SelectAccepted failed: %v", err)
+	}
+	got = make(map[string]bool)
+	for _, name := range names(accepted) {
+		got[name] = true
+	}
+	want = map[string]bool{"changed.png": true, "added.png": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the name patterns to narrow the selection to %v, got %v", want, got)
+	}
+
+	if accepted, err := SelectAccepted(results, []string{"same.png", "removed.png"}); err != nil || len(accepted) != 0 {
+		t.Errorf("expected unchanged and removed screenshots never to be accepted, got %v (err %v)", names(accepted), err)
+	}
+
+	if _, err := SelectAccepted(results, []string{"[bad"}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
@@ -0,0 +1,99 @@
+package imgdiff
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+)
+
+// badgeLabel is the left-hand text of every badge.
+const badgeLabel = "visual regression"
+
+// Badge colors, matching shields.io's "brightgreen", "red", and
+// "lightgrey".
+const (
+	badgeColorPassing = "#4c1"
+	badgeColorFailing = "#e05d44"
+	badgeColorUnknown = "#9f9f9f"
+)
+
+// badgeCharWidth approximates the average advance of an 11px Verdana glyph,
+// which is close enough to size the badge without measuring text.
+const badgeCharWidth = 7
+
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{.Label}}: {{.Message}}">
+<title>{{.Label}}: {{.Message}}</title>
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)"><rect width="{{.LabelWidth}}" height="20" fill="#555"/><rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Color}}"/><rect width="{{.Width}}" height="20" fill="url(#s)"/></g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelX}}" y="15" fill="#010101" fill-opacity=".3">{{.Label}}</text><text x="{{.LabelX}}" y="14">{{.Label}}</text>
+<text x="{{.MessageX}}" y="15" fill="#010101" fill-opacity=".3">{{.Message}}</text><text x="{{.MessageX}}" y="14">{{.Message}}</text>
+</g>
+</svg>
+`))
+
+// badgeData is what badgeTemplate renders.
+type badgeData struct {
+	Label, Message, Color    string
+	LabelWidth, MessageWidth int
+	Width, LabelX, MessageX  float64
+}
+
+// badgeMessage returns the right-hand text and color of a summary's badge:
+// "passing" when nothing differs, "N changed" counting changed, added, and
+// removed screenshots when something does, or "N errored" when only
+// unreadable screenshots failed the run.
+func badgeMessage(summary Summary) (string, string) {
+	changed := summary.Changed + summary.Added + summary.Removed
+	switch {
+	case summary.NoScreenshots:
+		return "no screenshots", badgeColorUnknown
+	case changed == 0 && summary.Errored > 0:
+		return fmt.Sprintf("%d errored", summary.Errored), badgeColorFailing
+	case summary.HasDifferences:
+		return fmt.Sprintf("%d changed", changed), badgeColorFailing
+	}
+	return "passing", badgeColorPassing
+}
+
+// renderBadge returns a shields.io-style SVG badge for a summary.
+func renderBadge(summary Summary) ([]byte, error) {
+	message, color := badgeMessage(summary)
+
+	d := badgeData{
+		Label:        badgeLabel,
+		Message:      message,
+		Color:        color,
+		LabelWidth:   len(badgeLabel)*badgeCharWidth + 10,
+		MessageWidth: len(message)*badgeCharWidth + 10,
+	}
+	d.Width = float64(d.LabelWidth + d.MessageWidth)
+	d.LabelX = float64(d.LabelWidth) / 2
+	d.MessageX = float64(d.LabelWidth) + float64(d.MessageWidth)/2
+
+	var buf bytes.Buffer
+	if err := badgeTemplate.Execute(&buf, d); err != nil {
+		return nil, fmt.Errorf("failed to render badge: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateBadge writes a "visual regression" status badge for a summary to
+// path, for embedding in a README.
+func GenerateBadge(summary Summary, path string) error {
+	data, err := renderBadge(summary)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for badge: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package imgdiff
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBadge(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary Summary
+		message string
+		color   string
+	}{
+		{"passing", BuildSummary("admin", []Result{{Name: "a.png", Status: StatusUnchanged}}), "passing", badgeColorPassing},
+		{"changed", BuildSummary("admin", []Result{
+			{Name: "a.png", Status: StatusChanged},
+			{Name: "b.png", Status: StatusAdded},
+			{Name: "c.png", Status: StatusUnchanged},
+		}), "2 changed", badgeColorFailing},
+		{"empty", Summary{NoScreenshots: true}, "no screenshots", badgeColorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nested", "badge.svg")
+			if err := GenerateBadge(tt.summary, path); err != nil {
+				t.Fatalf("GenerateBadge failed: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			svg := string(data)
+			if !strings.HasPrefix(svg, "<svg ") {
+				t.Errorf("expected an SVG document, got %q", svg)
+			}
+			if !strings.Contains(svg, ">"+tt.message+"<") {
+				t.Errorf("expected message %q in badge:\n%s", tt.message, svg)
+			}
+			if !strings.Contains(svg, `fill="`+tt.color+`"`) {
+				t.Errorf("expected color %s in badge:\n%s", tt.color, svg)
+			}
+		})
+	}
+}
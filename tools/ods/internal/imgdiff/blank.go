@@ -0,0 +1,56 @@
+package imgdiff
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// blankWarning returns a warning naming the images that are a single flat
+// color, which usually means the capture failed (e.g. an all-black or fully
+// transparent frame), or "" when neither is.
+func blankWarning(baseline, current image.Image) string {
+	var blank []string
+	if desc, ok := blankColor(baseline); ok {
+		blank = append(blank, fmt.Sprintf("baseline appears blank (%s)", desc))
+	}
+	if desc, ok := blankColor(current); ok {
+		blank = append(blank, fmt.Sprintf("current appears blank (%s)", desc))
+	}
+	if len(blank) == 0 {
+		return ""
+	}
+	return strings.Join(blank, ", ") + " — capture may have failed"
+}
+
+// blankColor reports whether every pixel of img has the same color, and
+// describes that color. Empty images are not considered blank.
+func blankColor(img image.Image) (string, bool) {
+	b := img.Bounds()
+	if b.Empty() {
+		return "", false
+	}
+	r0, g0, b0, a0 := img.At(b.Min.X, b.Min.Y).RGBA()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			if a0 == 0 && a == 0 {
+				continue // fully transparent pixels have no color
+			}
+			if r != r0 || g != g0 || bl != b0 || a != a0 {
+				return "", false
+			}
+		}
+	}
+
+	switch {
+	case a0 == 0:
+		return "fully transparent", true
+	case r0 == 0 && g0 == 0 && b0 == 0:
+		return "all black", true
+	default:
+		c := color.NRGBAModel.Convert(img.At(b.Min.X, b.Min.Y)).(color.NRGBA)
+		return fmt.Sprintf("all #%02x%02x%02x", c.R, c.G, c.B), true
+	}
+}
@@ -0,0 +1,86 @@
+package imgdiff
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func uniformImage(c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestBlankColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		img   image.Image
+		desc  string
+		blank bool
+	}{
+		{"black", uniformImage(color.RGBA{A: 255}), "all black", true},
+		{"transparent", uniformImage(color.RGBA{}), "fully transparent", true},
+		{"white", uniformImage(color.RGBA{R: 255, G: 255, B: 255, A: 255}), "all #ffffff", true},
+		{"empty", image.NewRGBA(image.Rect(0, 0, 0, 0)), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, blank := blankColor(tt.img)
+			if blank != tt.blank || desc != tt.desc {
+				t.Errorf("blankColor = (%q, %v), want (%q, %v)", desc, blank, tt.desc, tt.blank)
+			}
+		})
+	}
+
+	img := uniformImage(color.RGBA{A: 255})
+	img.Set(3, 3, color.RGBA{R: 1, A: 255})
+	if _, blank := blankColor(img); blank {
+		t.Error("expected an image with one differing pixel not to be blank")
+	}
+}
+
+func TestCompareImages_Blank(t *testing.T) {
+	baseline := uniformImage(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	baseline.Set(0, 0, color.RGBA{R: 255, A: 255})
+	current := uniformImage(color.RGBA{A: 255})
+
+	result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.1})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	want := "current appears blank (all black) — capture may have failed"
+	if result.Status != StatusChanged || result.Warning != want {
+		t.Errorf("got status %s, warning %q; want changed, %q", result.Status, result.Warning, want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, []Result{*result}, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<div class="card-warning">&#9888; `+want+`</div>`) {
+		t.Error("expected the warning on the report card")
+	}
+
+	result, err = CompareImages(baseline, current, CompareOptions{Threshold: 0.1, FailOnBlank: true})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusError || result.Error != want {
+		t.Errorf("got status %s, error %q; want error, %q", result.Status, result.Error, want)
+	}
+
+	result, err = CompareImages(baseline, baseline, CompareOptions{Threshold: 0.1, FailOnBlank: true})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusUnchanged || result.Warning != "" {
+		t.Errorf("expected a non-blank pair to compare normally, got %s %q", result.Status, result.Warning)
+	}
+}
@@ -0,0 +1,101 @@
+package imgdiff
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// CalibrationPoint summarises how a pair of directories classifies at one
+// threshold.
+type CalibrationPoint struct {
+	Threshold      float64
+	Changed        int
+	Unchanged      int
+	MaxDiffPercent float64 // largest diff percentage among compared pairs
+}
+
+// Calibrate compares every screenshot present in both directories at each
+// of the given thresholds (opts.Threshold is ignored) and reports how many
+// would be classified as changed. Each pair is decoded once and held only
+// while its thresholds are evaluated, so memory stays bounded on large suites.
+func Calibrate(baselineDir, currentDir string, thresholds []float64, opts CompareOptions) ([]CalibrationPoint, error) {
+	baselineFiles, err := listScreenshots(baselineDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list baseline directory: %w", err)
+	}
+	currentFiles, err := listScreenshots(currentDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list current directory: %w", err)
+	}
+
+	currentMap := make(map[string]string, len(currentFiles))
+	for _, f := range currentFiles {
+		currentMap[filepath.Base(f)] = f
+	}
+
+	points := make([]CalibrationPoint, len(thresholds))
+	for i, t := range thresholds {
+		points[i].Threshold = t
+	}
+
+	for _, baselinePath := range baselineFiles {
+		name := filepath.Base(baselinePath)
+		currentPath, ok := currentMap[name]
+		if !ok {
+			continue
+		}
+
+		baseline, err := decodeScreenshot(baselinePath, opts.SVGDPI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode baseline %s: %w", baselinePath, err)
+		}
+		current, err := decodeScreenshot(currentPath, opts.SVGDPI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode current %s: %w", currentPath, err)
+		}
+
+		pairOpts := opts
+		pairOpts.Regions = regionsFor(opts.Regions, name)
+		for i, t := range thresholds {
+			pairOpts.Threshold = t
+			result, err := CompareImages(baseline, current, pairOpts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compare %s: %w", name, err)
+			}
+			if result.Status == StatusChanged {
+				points[i].Changed++
+			} else {
+				points[i].Unchanged++
+			}
+			points[i].MaxDiffPercent = max(points[i].MaxDiffPercent, result.DiffPercent)
+		}
+	}
+
+	return points, nil
+}
+
+// ThresholdRange returns thresholds from lo to hi inclusive in steps of
+// step, rounded to avoid floating-point drift (0.1+0.2 != 0.3).
+func ThresholdRange(lo, hi, step float64) ([]float64, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if lo < 0 || hi > 1 || lo > hi {
+		return nil, fmt.Errorf("range must satisfy 0 <= min <= max <= 1")
+	}
+
+	var thresholds []float64
+	for i := 0; ; i++ {
+		t := roundThreshold(lo + float64(i)*step)
+		if t > hi+1e-9 {
+			break
+		}
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
+
+// roundThreshold rounds t to 4 decimal places.
+func roundThreshold(t float64) float64 {
+	return float64(int64(t*10000+0.5)) / 10000
+}
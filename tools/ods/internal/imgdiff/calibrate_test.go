@@ -0,0 +1,59 @@
+package imgdiff
+
+import (
+	"image/color"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCalibrate(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	noise := color.RGBA{R: 245, G: 245, B: 245, A: 255} // 10/255 off: anti-aliasing-like noise
+	redesign := color.RGBA{R: 0, G: 0, B: 255, A: 255}  // a real change
+
+	createTestPNG(t, filepath.Join(baselineDir, "same.png"), 20, 20, white)
+	createTestPNG(t, filepath.Join(currentDir, "same.png"), 20, 20, white)
+	createTestPNG(t, filepath.Join(baselineDir, "noisy.png"), 20, 20, white)
+	createTestPNGWithBlock(t, filepath.Join(currentDir, "noisy.png"), 20, 20, white, noise, 0, 0, 5, 5)
+	createTestPNG(t, filepath.Join(baselineDir, "real.png"), 20, 20, white)
+	createTestPNGWithBlock(t, filepath.Join(currentDir, "real.png"), 20, 20, white, redesign, 0, 0, 5, 5)
+	createTestPNG(t, filepath.Join(currentDir, "added.png"), 20, 20, white)
+
+	points, err := Calibrate(baselineDir, currentDir, []float64{0, 0.1, 0.5}, CompareOptions{})
+	if err != nil {
+		t.Fatalf("Calibrate failed: %v", err)
+	}
+
+	var changed []int
+	for _, p := range points {
+		changed = append(changed, p.Changed)
+		if p.Changed+p.Unchanged != 3 {
+			t.Errorf("threshold %.2f: expected 3 compared pairs, got %d", p.Threshold, p.Changed+p.Unchanged)
+		}
+	}
+	if !reflect.DeepEqual(changed, []int{2, 1, 1}) {
+		t.Errorf("unexpected changed counts: %v", changed)
+	}
+}
+
+func TestThresholdRange(t *testing.T) {
+	got, err := ThresholdRange(0, 0.3, 0.1)
+	if err != nil {
+		t.Fatalf("ThresholdRange failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, []float64{0, 0.1, 0.2, 0.3}) {
+		t.Errorf("unexpected range: %v", got)
+	}
+
+	if _, err := ThresholdRange(0, 0.5, 0); err == nil {
+		t.Error("expected error for zero step")
+	}
+	if _, err := ThresholdRange(0.6, 0.5, 0.1); err == nil {
+		t.Error("expected error for min > max")
+	}
+}
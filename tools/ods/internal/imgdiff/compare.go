@@ -1,15 +1,19 @@
 package imgdiff
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
-	"image/color"
 	"image/png"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
-	"sort"
-	"strings"
+	"slices"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 // Status represents the comparison status of a screenshot.
@@ -24,6 +28,9 @@ const (
 	StatusAdded
 	// StatusRemoved means the image exists only in the baseline directory (no current).
 	StatusRemoved
+	// StatusError means the screenshot (or project directory) could not be
+	// read; Result.Error says why. Only reported with TraverseErrorSkip.
+	StatusError
 )
 
 // String returns a human-readable string for the status.
@@ -37,16 +44,56 @@ func (s Status) String() string {
 		return "added"
 	case StatusRemoved:
 		return "removed"
+	case StatusError:
+		return "error"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseStatus parses a status name as returned by Status.String.
+func ParseStatus(s string) (Status, error) {
+	for _, status := range []Status{StatusUnchanged, StatusChanged, StatusAdded, StatusRemoved, StatusError} {
+		if status.String() == s {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid status %q (valid: unchanged, changed, added, removed, error)", s)
+}
+
+// MarshalJSON encodes the status as its name (e.g. "changed") rather than
+// its number, so JSON output doesn't depend on the order of the constants.
+func (s Status) MarshalJSON() ([]byte, error) {
+	if _, err := ParseStatus(s.String()); err != nil {
+		return nil, fmt.Errorf("cannot marshal unknown status %d", int(s))
+	}
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a status name as written by MarshalJSON.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("status must be a string: %w", err)
+	}
+	status, err := ParseStatus(name)
+	if err != nil {
+		return err
+	}
+	*s = status
+	return nil
+}
+
 // Result holds the comparison result for a single screenshot.
 type Result struct {
 	// Name is the filename of the screenshot (e.g. "admin-documents-explorer.png").
 	Name string
 
+	// Project is the project directory the screenshot was found in by
+	// CompareNestedDirectories, which also prefixes Name with it. Empty for
+	// flat comparisons.
+	Project string
+
 	// Status is the comparison status.
 	Status Status
 
@@ -59,30 +106,242 @@ type Result struct {
 	// TotalPixels is the total number of pixels compared.
 	TotalPixels int
 
+	// IgnoredPixels is the number of pixels excluded from the comparison by
+	// ignore regions or CompareOptions.IgnoreScrollbar. They count towards
+	// neither DiffPixels nor TotalPixels.
+	IgnoredPixels int
+
+	// Regions is the number of connected clusters of differing pixels.
+	Regions int
+
+	// LargestRegion is the pixel count of the largest such cluster.
+	LargestRegion int
+
+	// DPRScale is the current/baseline size ratio when one image is an
+	// integer multiple of the other (e.g. 2 for a 2x current against a 1x
+	// baseline), which usually means the device pixel ratio changed between
+	// captures. Zero otherwise.
+	DPRScale float64
+
 	// BaselinePath is the path to the baseline image (empty if added).
 	BaselinePath string
 
 	// CurrentPath is the path to the current image (empty if removed).
 	CurrentPath string
 
+	// RenamedFrom is the baseline filename this screenshot was paired with
+	// via CompareOptions.RenameMap (empty when matched by name).
+	RenamedFrom string
+
+	// Duplicates lists other current screenshots with identical pixels that
+	// were collapsed into this result (see CompareOptions.Dedupe).
+	Duplicates []string
+
+	// Context describes how the screenshot was captured, from its JSON
+	// sidecar file (see CompareOptions.Sidecars). Zero when unknown.
+	Context ScreenshotContext
+
+	// Error is why the screenshot could not be compared (StatusError only).
+	Error string
+
+	// Warning flags a comparison whose result is likely misleading, e.g.
+	// when an image is a single flat color because its capture failed.
+	Warning string
+
+	// Reference is the comparison of the same screenshot against a third,
+	// reference directory (see CompareThreeWay); its BaselinePath is the
+	// reference image. Nil outside three-way comparisons.
+	Reference *Result
+
 	// DiffImage is the generated diff overlay image (nil if unchanged, added, or removed).
 	DiffImage image.Image
 }
 
+// CompareOptions controls how two images are compared.
+type CompareOptions struct {
+	// Threshold (0.0 to 1.0) controls per-channel sensitivity: a pixel is
+	// considered different if any channel differs by more than Threshold * 255.
+	Threshold float64
+
+	// ThresholdAbs, if set, is the per-channel cutoff in 8-bit units
+	// (0-255), used as-is instead of Threshold * 255.
+	ThresholdAbs *float64
+
+	// Crop, if non-empty, restricts the comparison to this rectangle
+	// (relative to each image's top-left corner). Pixels outside it are
+	// ignored entirely and do not count towards TotalPixels.
+	Crop image.Rectangle
+
+	// IgnoreAlpha compares the RGB channels only, for capture pipelines
+	// where the alpha channel is noise.
+	IgnoreAlpha bool
+
+	// Regions are masked areas in screenshot coordinates (see MaskRegion).
+	// CompareFiles only applies the regions whose Name matches the file.
+	Regions []MaskRegion
+
+	// RenameMap maps current filenames to the baseline filenames they
+	// replace, so renamed screenshots are still compared pairwise by
+	// CompareDirectoriesWithOptions. Unmapped names are matched as-is.
+	RenameMap map[string]string
+
+	// Only, if non-nil, restricts a directory comparison to screenshots with
+	// these file names (see ReadNameList); the baselines they are renamed
+	// from via RenameMap are kept too. A listed name missing from the
+	// current directory is reported as removed, and one missing from the
+	// baseline as added.
+	Only map[string]bool
+
+	// OnResult, if set, is called by CompareDirectoriesWithOptions with each
+	// screenshot's result as soon as it is known, so callers can stream
+	// progress instead of waiting for the whole directory.
+	OnResult func(Result)
+
+	// Dedupe collapses current screenshots with identical pixels into a
+	// single result (e.g. a page captured twice under a retry suffix). Only
+	// the current set is deduplicated, never against the baseline.
+	Dedupe bool
+
+	// TrustMtime makes CompareDirectoriesWithOptions report a pair as
+	// unchanged without decoding it when both files have the same size and
+	// modification time (e.g. left alone by "aws s3 sync"). Files rewritten
+	// with different pixels but an identical size and mtime are missed, so
+	// this is off by default.
+	TrustMtime bool
+
+	// OverlayBase selects what unchanged pixels show in DiffImage. Empty
+	// means OverlayBaseCurrent.
+	OverlayBase OverlayBase
+
+	// Palette selects the color differing pixels are highlighted with in
+	// DiffImage. Empty means PaletteDefault (magenta).
+	Palette Palette
+
+	// OnTraverseError selects what happens when a screenshot or project
+	// directory cannot be read. Empty means TraverseErrorFail.
+	OnTraverseError TraverseErrorPolicy
+
+	// FailFast makes CompareDirectoriesWithOptions stop at the first result
+	// with one of these statuses, returning the results so far together with
+	// ErrStoppedEarly.
+	FailFast []Status
+
+	// SVGDPI is the resolution SVG screenshots are rasterized at before
+	// comparing. Zero means DefaultSVGDPI.
+	SVGDPI float64
+
+	// IgnoreScrollbar ignores the rightmost IgnoreScrollbar pixel columns of
+	// the compared area, where scrollbars render differently across
+	// platforms. With IgnoreScrollbarBottom, the bottom rows of the same
+	// height are ignored too (horizontal scrollbars).
+	IgnoreScrollbar       int
+	IgnoreScrollbarBottom bool
+
+	// MinRegionPixels, if positive, only marks a screenshot as changed when
+	// at least one connected cluster of differing pixels has this many
+	// pixels, so scattered single-pixel noise stays unchanged while a small
+	// but solid change (e.g. a new badge) does not. DiffPixels and Regions
+	// are recorded either way.
+	MinRegionPixels int
+
+	// Sidecars makes CompareDirectoriesWithOptions read each screenshot's
+	// JSON sidecar ("page.json" or "page.png.json" next to "page.png") into
+	// Result.Context.
+	Sidecars bool
+
+	// Timings, if set, accumulates the time CompareFiles spends decoding
+	// and comparing.
+	Timings *Timings
+
+	// UnpremultiplyAlpha compares straight (non-premultiplied) colors by
+	// dividing each channel by alpha first. By default channels are compared
+	// alpha-premultiplied, which scales color differences on translucent
+	// pixels down by their alpha and so under-reports them.
+	UnpremultiplyAlpha bool
+
+	// NormalizeDPR scales the larger image down to the other's size before
+	// comparing when their sizes differ by an integer factor (see
+	// Result.DPRScale), so a device pixel ratio change is not reported as
+	// every pixel differing.
+	NormalizeDPR bool
+
+	// FailOnBlank reports a pair as StatusError instead of comparing it
+	// when either image is a single flat color (see Result.Warning).
+	FailOnBlank bool
+
+	// Concurrency is the number of screenshot pairs
+	// CompareDirectoriesWithOptions compares at once. Results are still
+	// emitted (and OnResult called) one at a time in the sequential order.
+	// Zero or one compares sequentially.
+	Concurrency int
+}
+
+// ErrStoppedEarly is returned with partial results when a directory
+// comparison stops early because of CompareOptions.FailFast.
+var ErrStoppedEarly = errors.New("comparison stopped at the first difference")
+
 // Compare compares two PNG images pixel-by-pixel and returns the result.
 // The threshold parameter (0.0 to 1.0) controls per-channel sensitivity:
 // a pixel is considered different if any channel differs by more than threshold * 255.
 func Compare(baselinePath, currentPath string, threshold float64) (*Result, error) {
-	baseline, err := decodePNG(baselinePath)
+	return CompareFiles(baselinePath, currentPath, CompareOptions{Threshold: threshold})
+}
+
+// CompareFiles decodes two screenshot files (PNG, or SVG rasterized at
+// opts.SVGDPI) and compares them with the given options.
+func CompareFiles(baselinePath, currentPath string, opts CompareOptions) (*Result, error) {
+	start := time.Now()
+	baseline, err := decodeScreenshot(baselinePath, opts.SVGDPI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode baseline %s: %w", baselinePath, err)
 	}
 
-	current, err := decodePNG(currentPath)
+	current, err := decodeScreenshot(currentPath, opts.SVGDPI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode current %s: %w", currentPath, err)
 	}
 
+	name := filepath.Base(currentPath)
+	opts.Regions = regionsFor(opts.Regions, name)
+
+	opts.Timings.addDecode(time.Since(start))
+	start = time.Now()
+	result, err := CompareImages(baseline, current, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.Timings.addCompare(time.Since(start))
+
+	result.Name = name
+	result.BaselinePath = baselinePath
+	result.CurrentPath = currentPath
+	return result, nil
+}
+
+// CompareImages compares two in-memory images pixel-by-pixel and returns the
+// result. Images of different sizes are compared over the larger area, with
+// out-of-bounds pixels treated as transparent. The returned Result has no
+// Name, BaselinePath, or CurrentPath; callers may fill them in as needed.
+func CompareImages(baseline, current image.Image, opts CompareOptions) (*Result, error) {
+	if baseline == nil || current == nil {
+		return nil, fmt.Errorf("both baseline and current images are required")
+	}
+
+	warning := blankWarning(baseline, current)
+	if warning != "" && opts.FailOnBlank {
+		return &Result{Status: StatusError, Error: warning}, nil
+	}
+
+	scale := dprScale(baseline.Bounds(), current.Bounds())
+	if opts.NormalizeDPR {
+		baseline, current = normalizeDPR(baseline, current, scale)
+	}
+
+	if !opts.Crop.Empty() {
+		baseline = CropImage(baseline, opts.Crop)
+		current = CropImage(current, opts.Crop)
+	}
+
 	baselineBounds := baseline.Bounds()
 	currentBounds := current.Bounds()
 
@@ -92,17 +351,32 @@ func Compare(baselinePath, currentPath string, threshold float64) (*Result, erro
 	totalPixels := width * height
 
 	if totalPixels == 0 {
-		return &Result{
-			Name:         filepath.Base(currentPath),
-			Status:       StatusUnchanged,
-			BaselinePath: baselinePath,
-			CurrentPath:  currentPath,
-		}, nil
+		return &Result{Status: StatusUnchanged, DPRScale: scale, Warning: warning}, nil
 	}
 
 	diffImage := image.NewRGBA(image.Rect(0, 0, width, height))
+	highlight := opts.Palette.Highlight()
+	diffMask := make([]bool, totalPixels)
 	diffPixels := 0
-	thresholdValue := threshold * 255.0
+	ignoredPixels := 0
+	globalThreshold := opts.Threshold * 255.0
+	if opts.ThresholdAbs != nil {
+		globalThreshold = *opts.ThresholdAbs
+	}
+
+	// Per-pixel thresholds from mask regions (nil when there are none)
+	var offset image.Point
+	if !opts.Crop.Empty() {
+		offset = opts.Crop.Min
+	}
+	thresholds := thresholdMap(opts.Regions, width, height, offset, globalThreshold)
+	scrollbarX, scrollbarY := width, height
+	if opts.IgnoreScrollbar > 0 {
+		scrollbarX = width - opts.IgnoreScrollbar
+		if opts.IgnoreScrollbarBottom {
+			scrollbarY = height - opts.IgnoreScrollbar
+		}
+	}
 
 	for y := 0; y < height; y++ {
 		for x := 0; x < width; x++ {
@@ -116,6 +390,10 @@ func Compare(baselinePath, currentPath string, threshold float64) (*Result, erro
 			if x < currentBounds.Dx() && y < currentBounds.Dy() {
 				cr, cg, cb, ca = current.At(currentBounds.Min.X+x, currentBounds.Min.Y+y).RGBA()
 			}
+			if opts.UnpremultiplyAlpha {
+				br, bg, bb = unpremultiply(br, bg, bb, ba)
+				cr, cg, cb = unpremultiply(cr, cg, cb, ca)
+			}
 
 			// Convert from 16-bit to 8-bit
 			br8 := float64(br >> 8)
@@ -127,44 +405,69 @@ func Compare(baselinePath, currentPath string, threshold float64) (*Result, erro
 			cb8 := float64(cb >> 8)
 			ca8 := float64(ca >> 8)
 
-			// Check if channels differ beyond threshold
-			isDiff := math.Abs(br8-cr8) > thresholdValue ||
+			thresholdValue := globalThreshold
+			if thresholds != nil {
+				thresholdValue = thresholds[y*width+x]
+			}
+
+			// Check if channels differ beyond threshold (never inside ignore
+			// regions or the scrollbar strip)
+			ignored := thresholdValue == ignoredThreshold || x >= scrollbarX || y >= scrollbarY
+			isDiff := !ignored && (math.Abs(br8-cr8) > thresholdValue ||
 				math.Abs(bg8-cg8) > thresholdValue ||
 				math.Abs(bb8-cb8) > thresholdValue ||
-				math.Abs(ba8-ca8) > thresholdValue
-
-			if isDiff {
+				(!opts.IgnoreAlpha && math.Abs(ba8-ca8) > thresholdValue))
+
+			if ignored {
+				ignoredPixels++
+				diffImage.Set(x, y, opts.OverlayBase.ignored(
+					[4]float64{br8, bg8, bb8, ba8},
+					[4]float64{cr8, cg8, cb8, ca8},
+				))
+			} else if isDiff {
 				diffPixels++
-				// Highlight in magenta for diff overlay
-				diffImage.Set(x, y, color.RGBA{R: 255, G: 0, B: 255, A: 255})
+				diffMask[y*width+x] = true
+				// Highlight in the palette color (magenta by default)
+				diffImage.Set(x, y, highlight)
 			} else {
-				// Dim the unchanged pixel (30% opacity of the current image)
-				diffImage.Set(x, y, color.RGBA{
-					R: uint8(cr8 * 0.3),
-					G: uint8(cg8 * 0.3),
-					B: uint8(cb8 * 0.3),
-					A: uint8(math.Max(ca8*0.3, 50)),
-				})
+				// Dim the unchanged pixel (or paint it flat, per OverlayBase)
+				diffImage.Set(x, y, opts.OverlayBase.background(
+					[4]float64{br8, bg8, bb8, ba8},
+					[4]float64{cr8, cg8, cb8, ca8},
+				))
 			}
 		}
 	}
 
-	diffPercent := float64(diffPixels) / float64(totalPixels) * 100.0
+	// Ignored pixels do not count towards the compared area
+	totalPixels -= ignoredPixels
+	diffPercent := 0.0
+	if totalPixels > 0 {
+		diffPercent = float64(diffPixels) / float64(totalPixels) * 100.0
+	}
+
+	sizes := regionSizes(diffMask, width, height)
+	largest := 0
+	for _, size := range sizes {
+		largest = max(largest, size)
+	}
 
 	status := StatusUnchanged
-	if diffPixels > 0 {
+	if diffPixels > 0 && largest >= opts.MinRegionPixels {
 		status = StatusChanged
 	}
 
 	return &Result{
-		Name:         filepath.Base(currentPath),
-		Status:       status,
-		DiffPercent:  diffPercent,
-		DiffPixels:   diffPixels,
-		TotalPixels:  totalPixels,
-		BaselinePath: baselinePath,
-		CurrentPath:  currentPath,
-		DiffImage:    diffImage,
+		Status:        status,
+		DiffPercent:   diffPercent,
+		DiffPixels:    diffPixels,
+		TotalPixels:   totalPixels,
+		IgnoredPixels: ignoredPixels,
+		Regions:       len(sizes),
+		LargestRegion: largest,
+		DPRScale:      scale,
+		Warning:       warning,
+		DiffImage:     diffImage,
 	}, nil
 }
 
@@ -172,14 +475,50 @@ func Compare(baselinePath, currentPath string, threshold float64) (*Result, erro
 // Files are matched by name. Files only in baseline are "removed",
 // files only in current are "added", and matching files are compared.
 func CompareDirectories(baselineDir, currentDir string, threshold float64) ([]Result, error) {
-	baselineFiles, err := listPNGs(baselineDir)
+	return CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{Threshold: threshold})
+}
+
+// CompareDirectoriesWithOptions is like CompareDirectories but accepts the
+// full set of comparison options.
+func CompareDirectoriesWithOptions(baselineDir, currentDir string, opts CompareOptions) ([]Result, error) {
+	var results []Result
+	err := CompareDirectoriesStream(baselineDir, currentDir, opts, func(r Result) error {
+		results = append(results, r)
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrStoppedEarly) {
+		return nil, err
+	}
+
+	// Sort: changed first (by diff % descending), then added, removed, unchanged
+	SortResults(results, SortByDiffPercent)
+	return results, err
+}
+
+// CompareDirectoriesStream compares two directories like
+// CompareDirectoriesWithOptions, but hands each result to fn as soon as it
+// is known instead of collecting them, so a large suite can be consumed
+// without holding every result (and its DiffImage) in memory.
+//
+// Results arrive in the same deterministic order whatever opts.Concurrency
+// is: added, then removed, then compared screenshots, each in file name
+// order. Sort them with SortResults to match CompareDirectoriesWithOptions.
+// fn is never called concurrently. Returning an error from fn stops the
+// comparison, and CompareDirectoriesStream returns that error; FailFast
+// stops it with ErrStoppedEarly.
+func CompareDirectoriesStream(baselineDir, currentDir string, opts CompareOptions, fn func(Result) error) error {
+	baselineFiles, err := listScreenshots(baselineDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list baseline directory: %w", err)
+		return fmt.Errorf("failed to list baseline directory: %w", err)
 	}
 
-	currentFiles, err := listPNGs(currentDir)
+	currentFiles, err := listScreenshots(currentDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list current directory: %w", err)
+		return fmt.Errorf("failed to list current directory: %w", err)
+	}
+
+	if opts.Only != nil {
+		baselineFiles, currentFiles = restrictToNames(baselineFiles, currentFiles, opts.Only, opts.RenameMap)
 	}
 
 	// Build maps for lookup
@@ -193,61 +532,182 @@ func CompareDirectories(baselineDir, currentDir string, threshold float64) ([]Re
 		currentMap[filepath.Base(f)] = f
 	}
 
-	// Collect all unique names
-	allNames := make(map[string]struct{})
-	for name := range baselineMap {
-		allNames[name] = struct{}{}
+	// emit hands a result to fn and reports whether comparison should stop,
+	// because fn failed (recorded in stopErr) or because of FailFast.
+	var stopErr error
+	emit := func(r Result) bool {
+		if opts.Sidecars {
+			r.Context = readContext(r)
+		}
+		if err := fn(r); err != nil {
+			stopErr = err
+			return true
+		}
+		if opts.OnResult != nil {
+			opts.OnResult(r)
+		}
+		if slices.Contains(opts.FailFast, r.Status) {
+			stopErr = ErrStoppedEarly
+			return true
+		}
+		return false
 	}
+
+	// Pair each current screenshot with its baseline. A rename takes
+	// precedence over a same-named screenshot when its baseline exists.
+	pairs := make(map[string]string, len(currentMap))
+	renamed := make(map[string]bool)
 	for name := range currentMap {
-		allNames[name] = struct{}{}
+		if target, ok := opts.RenameMap[name]; ok {
+			if _, exists := baselineMap[target]; exists {
+				pairs[name] = target
+				renamed[target] = true
+			}
+		}
+	}
+	for name := range currentMap {
+		if _, ok := pairs[name]; ok {
+			continue
+		}
+		if _, exists := baselineMap[name]; exists && !renamed[name] {
+			pairs[name] = name
+		}
 	}
 
-	var results []Result
+	var duplicates map[string][]string
+	if opts.Dedupe {
+		duplicates, err = dedupeCurrent(currentMap, pairs, opts.SVGDPI)
+		if err != nil {
+			return err
+		}
+	}
 
-	for name := range allNames {
-		baselinePath, inBaseline := baselineMap[name]
-		currentPath, inCurrent := currentMap[name]
+	paired := make(map[string]bool, len(pairs))
+	for _, baselineName := range pairs {
+		paired[baselineName] = true
+	}
 
-		switch {
-		case inBaseline && inCurrent:
-			result, err := Compare(baselinePath, currentPath, threshold)
-			if err != nil {
-				return nil, fmt.Errorf("failed to compare %s: %w", name, err)
-			}
-			results = append(results, *result)
+	// Added and removed screenshots need no decoding, so classify them
+	// before the pixel comparisons; FailFast can then stop early cheaply.
+	// Iterate the sorted file lists so the order is deterministic.
+	for _, f := range currentFiles {
+		name := filepath.Base(f)
+		if _, ok := currentMap[name]; !ok {
+			continue // collapsed by Dedupe
+		}
+		if _, ok := pairs[name]; ok {
+			continue
+		}
+		if emit(Result{
+			Name:        name,
+			Status:      StatusAdded,
+			CurrentPath: f,
+			Duplicates:  duplicates[name],
+		}) {
+			return stopErr
+		}
+	}
 
-		case inBaseline && !inCurrent:
-			results = append(results, Result{
-				Name:         name,
-				Status:       StatusRemoved,
-				BaselinePath: baselinePath,
-			})
-
-		case !inBaseline && inCurrent:
-			results = append(results, Result{
-				Name:        name,
-				Status:      StatusAdded,
-				CurrentPath: currentPath,
-			})
+	for _, f := range baselineFiles {
+		name := filepath.Base(f)
+		if paired[name] {
+			continue
+		}
+		if emit(Result{
+			Name:         name,
+			Status:       StatusRemoved,
+			BaselinePath: f,
+		}) {
+			return stopErr
 		}
 	}
 
-	// Sort: changed first (by diff % descending), then added, removed, unchanged
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].Status != results[j].Status {
-			return statusOrder(results[i].Status) < statusOrder(results[j].Status)
+	var toCompare []string
+	for _, f := range currentFiles {
+		if _, ok := pairs[filepath.Base(f)]; ok {
+			toCompare = append(toCompare, f)
+		}
+	}
+	compare := func(i int) (*Result, error) {
+		f := toCompare[i]
+		name := filepath.Base(f)
+		baselineName := pairs[name]
+
+		if opts.TrustMtime && sameSizeAndMtime(baselineMap[baselineName], f) {
+			result := &Result{
+				Name:         name,
+				Status:       StatusUnchanged,
+				BaselinePath: baselineMap[baselineName],
+				CurrentPath:  f,
+				Duplicates:   duplicates[name],
+			}
+			if baselineName != name {
+				result.RenamedFrom = baselineName
+			}
+			log.WithField("screenshot", name).Debug("unchanged (same size and modification time, not decoded)")
+			return result, nil
 		}
-		if results[i].Status == StatusChanged {
-			return results[i].DiffPercent > results[j].DiffPercent
+
+		result, err := CompareFiles(baselineMap[baselineName], f, opts)
+		if err != nil {
+			if opts.OnTraverseError == TraverseErrorSkip {
+				r := errorResult(name, err)
+				r.BaselinePath = baselineMap[baselineName]
+				r.CurrentPath = f
+				return &r, nil
+			}
+			return nil, fmt.Errorf("failed to compare %s: %w", name, err)
 		}
-		return results[i].Name < results[j].Name
-	})
+		if baselineName != name {
+			result.RenamedFrom = baselineName
+		}
+		result.Duplicates = duplicates[name]
+		log.WithField("screenshot", name).Debugf("%s (%.2f%% of pixels differ)", result.Status, result.DiffPercent)
+		return result, nil
+	}
+	stopped, err := runOrdered(len(toCompare), opts.Concurrency, compare, func(r *Result) bool { return emit(*r) })
+	if err != nil {
+		return err
+	}
+	if stopped {
+		return stopErr
+	}
+	return nil
+}
 
-	return results, nil
+// sameSizeAndMtime reports whether two files have the same size and
+// modification time. Either file failing to stat counts as a mismatch, so
+// the pair is decoded and any error surfaces there.
+func sameSizeAndMtime(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return ai.Size() == bi.Size() && ai.ModTime().Equal(bi.ModTime())
+}
+
+// unpremultiply converts 16-bit alpha-premultiplied color channels (as
+// returned by color.Color.RGBA) to straight color. Fully transparent pixels
+// have no color and are returned unchanged.
+func unpremultiply(r, g, b, a uint32) (uint32, uint32, uint32) {
+	if a == 0 || a == 0xffff {
+		return r, g, b
+	}
+	return r * 0xffff / a, g * 0xffff / a, b * 0xffff / a
 }
 
 // SaveDiffImage writes a diff overlay image to the specified path as PNG.
 func SaveDiffImage(img image.Image, path string) error {
+	return SaveDiffImageWithCompression(img, path, PNGCompressionDefault)
+}
+
+// SaveDiffImageWithCompression is like SaveDiffImage but encodes at the
+// given compression level.
+func SaveDiffImageWithCompression(img image.Image, path string, compression PNGCompression) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -258,7 +718,7 @@ func SaveDiffImage(img image.Image, path string) error {
 	}
 	defer func() { _ = f.Close() }()
 
-	if err := png.Encode(f, img); err != nil {
+	if err := encodePNG(f, img, compression); err != nil {
 		return fmt.Errorf("failed to encode PNG: %w", err)
 	}
 
@@ -281,41 +741,114 @@ func decodePNG(path string) (image.Image, error) {
 	return img, nil
 }
 
-// listPNGs returns all .png files in a directory (non-recursive).
-func listPNGs(dir string) ([]string, error) {
+// restrictToNames drops every screenshot not named in only from the
+// baseline and current file lists, keeping the baselines that listed current
+// screenshots are renamed from.
+func restrictToNames(baselineFiles, currentFiles []string, only map[string]bool, renames map[string]string) ([]string, []string) {
+	keepBaseline := maps.Clone(only)
+	for name := range only {
+		if target, ok := renames[name]; ok {
+			keepBaseline[target] = true
+		}
+	}
+
+	found := make(map[string]bool, len(only))
+	baselineFiles = slices.DeleteFunc(baselineFiles, func(f string) bool {
+		found[filepath.Base(f)] = true
+		return !keepBaseline[filepath.Base(f)]
+	})
+	currentFiles = slices.DeleteFunc(currentFiles, func(f string) bool {
+		found[filepath.Base(f)] = true
+		return !only[filepath.Base(f)]
+	})
+
+	for _, name := range slices.Sorted(maps.Keys(only)) {
+		if !found[name] {
+			log.Warnf("Listed screenshot %s is in neither the baseline nor the current directory", name)
+		}
+	}
+	return baselineFiles, currentFiles
+}
+
+// listScreenshots returns all .png and .svg files in a directory
+// (non-recursive). A missing directory has none, unless a dangling symlink
+// is why it is missing.
+func listScreenshots(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, nil
+			return nil, brokenSymlink(dir)
 		}
 		return nil, err
 	}
 
-	var pngs []string
+	var files []string
 	for _, entry := range entries {
-		if entry.IsDir() {
+		if isDirEntry(dir, entry) {
 			continue
 		}
-		if strings.HasSuffix(strings.ToLower(entry.Name()), ".png") {
-			pngs = append(pngs, filepath.Join(dir, entry.Name()))
+		if IsScreenshotFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
 		}
 	}
 
-	return pngs, nil
+	return files, nil
+}
+
+// SameDirectory reports whether a and b resolve to the same directory once
+// made absolute and symlinks are followed. Comparing a directory against
+// itself trivially reports every screenshot as unchanged.
+func SameDirectory(a, b string) (bool, error) {
+	ra, err := resolveDir(a)
+	if err != nil {
+		return false, err
+	}
+	rb, err := resolveDir(b)
+	if err != nil {
+		return false, err
+	}
+	return ra == rb, nil
+}
+
+// resolveDir returns the absolute, symlink-free form of dir. Directories that
+// do not exist yet are only made absolute.
+func resolveDir(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if os.IsNotExist(err) {
+		return abs, nil
+	}
+	return resolved, err
+}
+
+// HasScreenshots reports whether dir contains at least one screenshot. A
+// missing directory has none, but one behind a dangling symlink is an error
+// (see StatDir).
+func HasScreenshots(dir string) (bool, error) {
+	files, err := listScreenshots(dir)
+	if err != nil {
+		return false, err
+	}
+	return len(files) > 0, nil
 }
 
 // statusOrder returns a sort priority for each status.
 func statusOrder(s Status) int {
 	switch s {
-	case StatusChanged:
+	case StatusError:
 		return 0
-	case StatusAdded:
+	case StatusChanged:
 		return 1
-	case StatusRemoved:
+	case StatusAdded:
 		return 2
-	case StatusUnchanged:
+	case StatusRemoved:
 		return 3
-	default:
+	case StatusUnchanged:
 		return 4
+	default:
+		return 5
 	}
 }
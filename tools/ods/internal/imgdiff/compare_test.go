@@ -1,12 +1,19 @@
 package imgdiff
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"image"
 	"image/color"
 	"image/png"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // createTestPNG creates a solid-color PNG file at the given path.
@@ -166,6 +173,489 @@ func TestCompare_DifferentSizes(t *testing.T) {
 	}
 }
 
+func TestCompareImages_InMemory(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	baseline := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	current := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			baseline.Set(x, y, white)
+			current.Set(x, y, white)
+		}
+	}
+	current.Set(3, 4, red)
+
+	result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.2})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+
+	if result.Status != StatusChanged {
+		t.Errorf("expected StatusChanged, got %s", result.Status)
+	}
+	if result.DiffPixels != 1 {
+		t.Errorf("expected 1 diff pixel, got %d", result.DiffPixels)
+	}
+	if result.BaselinePath != "" || result.CurrentPath != "" {
+		t.Errorf("expected empty paths for in-memory comparison, got %q and %q",
+			result.BaselinePath, result.CurrentPath)
+	}
+
+	if _, err := CompareImages(nil, current, CompareOptions{}); err == nil {
+		t.Error("expected error for nil baseline")
+	}
+}
+
+func TestCompareImages_IgnoreAlpha(t *testing.T) {
+	// Identical RGB, different alpha. Black is used so that the
+	// premultiplied RGB values returned by RGBA() are identical too.
+	baseline := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	current := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			baseline.Set(x, y, color.NRGBA{R: 0, G: 0, B: 0, A: 255})
+			current.Set(x, y, color.NRGBA{R: 0, G: 0, B: 0, A: 100})
+		}
+	}
+
+	result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.2})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusChanged {
+		t.Errorf("expected StatusChanged when alpha is compared, got %s", result.Status)
+	}
+
+	result, err = CompareImages(baseline, current, CompareOptions{Threshold: 0.2, IgnoreAlpha: true})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusUnchanged {
+		t.Errorf("expected StatusUnchanged with IgnoreAlpha, got %s", result.Status)
+	}
+}
+
+func TestCompareImages_UnpremultiplyAlpha(t *testing.T) {
+	// A translucent overlay (alpha 40) whose true color changes from red 200
+	// to red 100. Premultiplied, the channels are 31 and 16: a difference of
+	// 15, below the 0.2 threshold (51). The true colors differ by 100, so the
+	// pixel has really changed.
+	baseline := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	current := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			baseline.SetNRGBA(x, y, color.NRGBA{R: 200, A: 40})
+			current.SetNRGBA(x, y, color.NRGBA{R: 100, A: 40})
+		}
+	}
+
+	tests := []struct {
+		name          string
+		unpremultiply bool
+		wantStatus    Status
+	}{
+		{"premultiplied under-reports", false, StatusUnchanged},
+		{"straight color", true, StatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.2, UnpremultiplyAlpha: tt.unpremultiply})
+			if err != nil {
+				t.Fatalf("CompareImages failed: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected %s, got %s (%.2f%%)", tt.wantStatus, result.Status, result.DiffPercent)
+			}
+		})
+	}
+}
+
+func TestCompareImages_ThresholdAbs(t *testing.T) {
+	// The red channel differs by exactly 10: within an absolute cutoff of
+	// 10, but over one of 9.
+	baseline := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	current := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			baseline.SetRGBA(x, y, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+			current.SetRGBA(x, y, color.RGBA{R: 110, G: 100, B: 100, A: 255})
+		}
+	}
+
+	abs := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name       string
+		opts       CompareOptions
+		wantStatus Status
+	}{
+		{"at cutoff", CompareOptions{ThresholdAbs: abs(10)}, StatusUnchanged},
+		{"below cutoff", CompareOptions{ThresholdAbs: abs(9)}, StatusChanged},
+		{"overrides threshold", CompareOptions{Threshold: 0.5, ThresholdAbs: abs(0)}, StatusChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareImages(baseline, current, tt.opts)
+			if err != nil {
+				t.Fatalf("CompareImages failed: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected %s, got %s", tt.wantStatus, result.Status)
+			}
+		})
+	}
+}
+
+func TestCompareImages_IgnoreScrollbar(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	gray := color.RGBA{R: 100, G: 100, B: 100, A: 255}
+
+	// Baseline has a 2px scrollbar strip on the right and bottom edges
+	baseline := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	current := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			current.Set(x, y, white)
+			if x >= 8 || y >= 8 {
+				baseline.Set(x, y, gray)
+			} else {
+				baseline.Set(x, y, white)
+			}
+		}
+	}
+
+	result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.2, IgnoreScrollbar: 2})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.DiffPixels != 16 || result.IgnoredPixels != 20 || result.TotalPixels != 80 {
+		t.Errorf("right strip only: expected 16 diff, 20 ignored, 80 total pixels; got %d, %d, %d",
+			result.DiffPixels, result.IgnoredPixels, result.TotalPixels)
+	}
+
+	result, err = CompareImages(baseline, current, CompareOptions{Threshold: 0.2, IgnoreScrollbar: 2, IgnoreScrollbarBottom: true})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusUnchanged || result.IgnoredPixels != 36 {
+		t.Errorf("right and bottom strips: expected unchanged with 36 ignored pixels, got %s with %d",
+			result.Status, result.IgnoredPixels)
+	}
+}
+
+func TestCompareImages_MinRegionPixels(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black := color.RGBA{R: 0, G: 0, B: 0, A: 255}
+
+	newImage := func() *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 20, 20))
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 20; x++ {
+				img.Set(x, y, white)
+			}
+		}
+		return img
+	}
+
+	// Both current images differ from the baseline in 9 pixels: one as
+	// isolated specks on a 3px grid, the other as a solid 3x3 block.
+	baseline := newImage()
+	scattered := newImage()
+	clustered := newImage()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			scattered.Set(2+i*6, 2+j*6, black)
+			clustered.Set(2+i, 2+j, black)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		current     image.Image
+		wantStatus  Status
+		wantRegions int
+		wantLargest int
+	}{
+		{"scattered", scattered, StatusUnchanged, 9, 1},
+		{"clustered", clustered, StatusChanged, 1, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareImages(baseline, tt.current, CompareOptions{Threshold: 0.2, MinRegionPixels: 4})
+			if err != nil {
+				t.Fatalf("CompareImages failed: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, result.Status)
+			}
+			if result.DiffPixels != 9 || result.Regions != tt.wantRegions || result.LargestRegion != tt.wantLargest {
+				t.Errorf("expected 9 diff pixels in %d region(s), largest %d; got %d in %d, largest %d",
+					tt.wantRegions, tt.wantLargest, result.DiffPixels, result.Regions, result.LargestRegion)
+			}
+		})
+	}
+
+	// Without a minimum, any difference is a change
+	result, err := CompareImages(baseline, scattered, CompareOptions{Threshold: 0.2})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusChanged {
+		t.Errorf("expected scattered diff to be changed without --min-region-pixels, got %s", result.Status)
+	}
+}
+
+func TestCompareFiles_MaskRegions(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.png")
+	currentPath := filepath.Join(dir, "page.png")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	grey := color.RGBA{R: 200, G: 200, B: 200, A: 255} // 55 off white: above 0.2, below 0.3
+
+	createTestPNG(t, baselinePath, 100, 100, white)
+	createTestPNGWithBlock(t, currentPath, 100, 100, white, grey, 0, 0, 20, 20)
+
+	loose := 0.3
+	tests := []struct {
+		name        string
+		regions     []MaskRegion
+		wantStatus  Status
+		wantTotal   int
+		wantChanged int
+	}{
+		{"no regions", nil, StatusChanged, 10000, 400},
+		{"ignored", []MaskRegion{{X: 0, Y: 0, Width: 20, Height: 20}}, StatusUnchanged, 9600, 0},
+		{"local threshold", []MaskRegion{{X: 0, Y: 0, Width: 20, Height: 20, Threshold: &loose}}, StatusUnchanged, 10000, 0},
+		{"other screenshot", []MaskRegion{{Name: "other*.png", X: 0, Y: 0, Width: 20, Height: 20}}, StatusChanged, 10000, 400},
+		{"overlap keeps most permissive", []MaskRegion{
+			{X: 0, Y: 0, Width: 20, Height: 20},
+			{X: 0, Y: 0, Width: 10, Height: 10, Threshold: &loose},
+		}, StatusUnchanged, 9600, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := CompareFiles(baselinePath, currentPath, CompareOptions{Threshold: 0.2, Regions: tt.regions})
+			if err != nil {
+				t.Fatalf("CompareFiles failed: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected %s, got %s", tt.wantStatus, result.Status)
+			}
+			if result.TotalPixels != tt.wantTotal {
+				t.Errorf("expected %d total pixels, got %d", tt.wantTotal, result.TotalPixels)
+			}
+			if result.DiffPixels != tt.wantChanged {
+				t.Errorf("expected %d diff pixels, got %d", tt.wantChanged, result.DiffPixels)
+			}
+		})
+	}
+}
+
+func TestLoadMask(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mask.json")
+	content := `{"regions": [{"x": 0, "y": 0, "width": 10, "height": 5}, {"x": 1, "y": 2, "width": 3, "height": 4, "threshold": 0.5}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	regions, err := LoadMask(path)
+	if err != nil {
+		t.Fatalf("LoadMask failed: %v", err)
+	}
+	if len(regions) != 2 || regions[0].Threshold != nil || regions[1].Threshold == nil || *regions[1].Threshold != 0.5 {
+		t.Errorf("unexpected regions: %+v", regions)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"regions": [{"x": 0, "y": 0, "width": 0, "height": 5}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadMask(path); err == nil {
+		t.Error("expected error for zero-width region")
+	}
+}
+
+func TestCompareDirectories_RenameMap(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	createTestPNG(t, filepath.Join(baselineDir, "old-name.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(baselineDir, "same.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currentDir, "new-name.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currentDir, "same.png"), 10, 10, white)
+
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		RenameMap: map[string]string{
+			"new-name.png": "old-name.png",
+			"missing.png":  "also-missing.png",
+		},
+	})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(results), results)
+	}
+	for _, r := range results {
+		if r.Status != StatusUnchanged {
+			t.Errorf("%s: expected unchanged, got %s", r.Name, r.Status)
+		}
+		switch r.Name {
+		case "new-name.png":
+			if r.RenamedFrom != "old-name.png" {
+				t.Errorf("expected RenamedFrom old-name.png, got %q", r.RenamedFrom)
+			}
+		case "same.png":
+			if r.RenamedFrom != "" {
+				t.Errorf("expected identity match for same.png, got RenamedFrom %q", r.RenamedFrom)
+			}
+		default:
+			t.Errorf("unexpected result %s", r.Name)
+		}
+	}
+}
+
+func TestLoadRenameMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "renames.json")
+	if err := os.WriteFile(path, []byte(`{"new.png": "old.png"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	renames, err := LoadRenameMap(path)
+	if err != nil {
+		t.Fatalf("LoadRenameMap failed: %v", err)
+	}
+	if renames["new.png"] != "old.png" {
+		t.Errorf("unexpected renames: %v", renames)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"a.png": "old.png", "b.png": "old.png"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRenameMap(path); err == nil {
+		t.Error("expected error for duplicate baseline target")
+	}
+}
+
+func TestCompareFiles_Crop(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.png")
+	currentPath := filepath.Join(dir, "current.png")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	// Only the 100x20 "header" differs
+	createTestPNG(t, baselinePath, 100, 100, white)
+	createTestPNGWithBlock(t, currentPath, 100, 100, white, red, 0, 0, 100, 20)
+
+	result, err := CompareFiles(baselinePath, currentPath, CompareOptions{Threshold: 0.2, Crop: CropTop(20)})
+	if err != nil {
+		t.Fatalf("CompareFiles failed: %v", err)
+	}
+	if result.Status != StatusUnchanged {
+		t.Errorf("expected StatusUnchanged with header cropped, got %s", result.Status)
+	}
+	if result.TotalPixels != 8000 {
+		t.Errorf("expected 8000 total pixels within crop, got %d", result.TotalPixels)
+	}
+
+	crop, err := ParseCrop("10,0,20,5")
+	if err != nil {
+		t.Fatalf("ParseCrop failed: %v", err)
+	}
+	result, err = CompareFiles(baselinePath, currentPath, CompareOptions{Threshold: 0.2, Crop: crop})
+	if err != nil {
+		t.Fatalf("CompareFiles failed: %v", err)
+	}
+	if result.DiffPixels != 100 || result.TotalPixels != 100 {
+		t.Errorf("expected 100/100 diff pixels within crop, got %d/%d", result.DiffPixels, result.TotalPixels)
+	}
+
+	for _, bad := range []string{"1,2,3", "a,b,c,d", "0,0,0,10", "-1,0,5,5"} {
+		if _, err := ParseCrop(bad); err == nil {
+			t.Errorf("expected ParseCrop(%q) to fail", bad)
+		}
+	}
+}
+
+func TestCompare_CountsRegions(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.png")
+	currentPath := filepath.Join(dir, "current.png")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	createTestPNG(t, baselinePath, 40, 40, white)
+	createTestPNGWithBlock(t, currentPath, 40, 40, white, red, 0, 0, 5, 5)
+
+	result, err := Compare(baselinePath, currentPath, 0.2)
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if result.Regions != 1 {
+		t.Errorf("expected 1 region, got %d", result.Regions)
+	}
+}
+
+func TestCountRegions(t *testing.T) {
+	// Two clusters: a diagonal pair (8-connected) and an isolated pixel.
+	mask := []bool{
+		true, false, false, false,
+		false, true, false, false,
+		false, false, false, false,
+		false, false, false, true,
+	}
+	if got := countRegions(mask, 4, 4); got != 2 {
+		t.Errorf("expected 2 regions, got %d", got)
+	}
+}
+
+func TestSortResults(t *testing.T) {
+	results := []Result{
+		{Name: "unchanged.png", Status: StatusUnchanged},
+		{Name: "shift.png", Status: StatusChanged, DiffPercent: 40, DiffPixels: 4000, Regions: 1},
+		{Name: "added.png", Status: StatusAdded},
+		{Name: "buttons.png", Status: StatusChanged, DiffPercent: 1, DiffPixels: 5000, Regions: 6},
+	}
+
+	tests := []struct {
+		key   SortKey
+		first string
+	}{
+		{SortByDiffPercent, "shift.png"},
+		{SortByDiffPixels, "buttons.png"},
+		{SortByRegions, "buttons.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.key), func(t *testing.T) {
+			SortResults(results, tt.key)
+			if results[0].Name != tt.first {
+				t.Errorf("expected %s first, got %s", tt.first, results[0].Name)
+			}
+			if results[2].Status != StatusAdded || results[3].Status != StatusUnchanged {
+				t.Errorf("expected added then unchanged after changed results, got %s, %s",
+					results[2].Status, results[3].Status)
+			}
+		})
+	}
+
+	if _, err := ParseSortKey("bogus"); err == nil {
+		t.Error("expected error for invalid sort key")
+	}
+}
+
 func TestCompareDirectories(t *testing.T) {
 	baselineDir := filepath.Join(t.TempDir(), "baseline")
 	currentDir := filepath.Join(t.TempDir(), "current")
@@ -263,7 +753,7 @@ func TestGenerateReport(t *testing.T) {
 	}
 
 	outputPath := filepath.Join(dir, "report", "index.html")
-	if err := GenerateReport(results, outputPath); err != nil {
+	if err := GenerateReport(results, outputPath, ReportOptions{}); err != nil {
 		t.Fatalf("GenerateReport failed: %v", err)
 	}
 
@@ -295,6 +785,117 @@ func TestGenerateReport(t *testing.T) {
 	}
 }
 
+func TestGenerateReport_UnchangedThumbnails(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	createTestPNG(t, filepath.Join(baselineDir, "same.png"), 600, 300, white)
+	createTestPNG(t, filepath.Join(currentDir, "same.png"), 600, 300, white)
+
+	results, err := CompareDirectories(baselineDir, currentDir, 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	plainPath := filepath.Join(dir, "plain", "index.html")
+	if err := GenerateReport(results, plainPath, ReportOptions{}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	thumbPath := filepath.Join(dir, "thumbs", "index.html")
+	if err := GenerateReport(results, thumbPath, ReportOptions{UnchangedThumbnails: true}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	// Unchanged entries are rendered client-side from an embedded JSON list
+	plain, _ := os.ReadFile(plainPath)
+	thumbs, _ := os.ReadFile(thumbPath)
+	if contains(string(plain), `"thumb":"data:image/png;base64,`) {
+		t.Error("default report should not contain thumbnails")
+	}
+	if !contains(string(thumbs), `"thumb":"data:image/png;base64,`) {
+		t.Error("thumbnail report missing thumbnail data")
+	}
+}
+
+func TestGenerateReport_UnchangedSorted(t *testing.T) {
+	dir := t.TempDir()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	for _, name := range []string{"zeta.png", "alpha.png", "mid.png"} {
+		createTestPNG(t, filepath.Join(dir, "baseline", name), 4, 4, white)
+		createTestPNG(t, filepath.Join(dir, "current", name), 4, 4, white)
+	}
+
+	results, err := CompareDirectories(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+	// Reverse so the report cannot rely on the input order
+	for i, j := 0, len(results)-1; i < j; i, j = i+1, j-1 {
+		results[i], results[j] = results[j], results[i]
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	if err := GenerateReport(results, outputPath, ReportOptions{}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	html, _ := os.ReadFile(outputPath)
+	if !contains(string(html), `[{"name":"alpha.png"},{"name":"mid.png"},{"name":"zeta.png"}]`) {
+		t.Error("unchanged screenshots not embedded in alphabetical order")
+	}
+}
+
+func TestScreenshotDataURI_MaxDimensions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.png")
+	createTestPNG(t, path, 800, 400, color.RGBA{R: 255, A: 255})
+
+	tests := []struct {
+		name         string
+		opts         ReportOptions
+		wantW, wantH int
+	}{
+		{"full size by default", ReportOptions{}, 800, 400},
+		{"max width", ReportOptions{MaxWidth: 200}, 200, 100},
+		{"max height", ReportOptions{MaxHeight: 100}, 200, 100},
+		{"tighter bound wins", ReportOptions{MaxWidth: 400, MaxHeight: 50}, 100, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri, err := screenshotDataURI(path, tt.opts)
+			if err != nil {
+				t.Fatalf("screenshotDataURI failed: %v", err)
+			}
+			data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, "data:image/png;base64,"))
+			if err != nil {
+				t.Fatalf("failed to decode data URI: %v", err)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("failed to decode PNG: %v", err)
+			}
+			if cfg.Width != tt.wantW || cfg.Height != tt.wantH {
+				t.Errorf("expected %dx%d, got %dx%d", tt.wantW, tt.wantH, cfg.Width, cfg.Height)
+			}
+		})
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 600, 300))
+
+	got := downscale(img, 240, 0).Bounds()
+	if got.Dx() != 240 || got.Dy() != 120 {
+		t.Errorf("expected 240x120, got %dx%d", got.Dx(), got.Dy())
+	}
+
+	if downscale(img, 1000, 1000) != image.Image(img) {
+		t.Error("expected image that already fits to be returned unchanged")
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && searchString(s, substr)
 }
@@ -307,3 +908,755 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestHasScreenshots(t *testing.T) {
+	dir := t.TempDir()
+
+	if has, err := HasScreenshots(filepath.Join(dir, "missing")); err != nil || has {
+		t.Errorf("missing dir: expected false, nil; got %v, %v", has, err)
+	}
+	if has, err := HasScreenshots(dir); err != nil || has {
+		t.Errorf("empty dir: expected false, nil; got %v, %v", has, err)
+	}
+
+	createTestPNG(t, filepath.Join(dir, "page.png"), 10, 10, color.White)
+	if has, err := HasScreenshots(dir); err != nil || !has {
+		t.Errorf("populated dir: expected true, nil; got %v, %v", has, err)
+	}
+}
+
+func TestSameDirectory(t *testing.T) {
+	dir := t.TempDir()
+	shots := filepath.Join(dir, "shots")
+	other := filepath.Join(dir, "other")
+	for _, d := range []string{shots, other} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(shots, link); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"identical", shots, shots, true},
+		{"unclean path", shots, filepath.Join(other, "..", "shots") + "/", true},
+		{"symlink", shots, link, true},
+		{"different", shots, other, false},
+		{"missing", shots, filepath.Join(dir, "missing"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SameDirectory(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("SameDirectory failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SameDirectory(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateReport_Note(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "current", "new.png"), 10, 10, color.White)
+
+	results, err := CompareDirectories(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	if err := GenerateReport(results, outputPath, ReportOptions{Note: "Baseline: main (fallback)"}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	html, _ := os.ReadFile(outputPath)
+	if !contains(string(html), "Baseline: main (fallback)") {
+		t.Error("report missing note")
+	}
+}
+
+func TestGenerateReport_TitleAndFavicon(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "current", "new.png"), 10, 10, color.White)
+
+	results, err := CompareDirectories(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	opts := ReportOptions{Title: "Admin UI", StatusFavicon: true}
+	if err := GenerateReport(results, outputPath, opts); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	html, _ := os.ReadFile(outputPath)
+	for _, expected := range []string{
+		"<title>Admin UI</title>",
+		"<h1>Admin UI</h1>",
+		`<link rel="icon"`,
+	} {
+		if !contains(string(html), expected) {
+			t.Errorf("report missing expected content: %q", expected)
+		}
+	}
+
+	if statusFavicon(true) == statusFavicon(false) {
+		t.Error("expected pass and fail favicons to differ")
+	}
+}
+
+func TestWriteReport_CollapseBelow(t *testing.T) {
+	results := []Result{
+		{Name: "big.png", Status: StatusChanged, DiffPercent: 30},
+		{Name: "tiny.png", Status: StatusChanged, DiffPercent: 0.2},
+	}
+
+	count := func(opts ReportOptions) (collapsible, collapsed int) {
+		var buf bytes.Buffer
+		if err := writeReport(&buf, results, opts); err != nil {
+			t.Fatalf("writeReport failed: %v", err)
+		}
+		html := buf.String()
+		return strings.Count(html, `class="card card-collapsible`), strings.Count(html, `card-collapsible collapsed"`)
+	}
+
+	if collapsible, collapsed := count(ReportOptions{}); collapsible != 0 || collapsed != 0 {
+		t.Errorf("default: expected no collapsible cards, got %d (%d collapsed)", collapsible, collapsed)
+	}
+	if collapsible, collapsed := count(ReportOptions{CollapseBelow: 1}); collapsible != 2 || collapsed != 1 {
+		t.Errorf("below 1%%: expected 2 collapsible cards with 1 collapsed, got %d with %d", collapsible, collapsed)
+	}
+}
+
+func TestCompareDirectories_OnResult(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	createTestPNG(t, filepath.Join(baselineDir, "same.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(baselineDir, "gone.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "same.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "new.png"), 10, 10, color.White)
+
+	seen := make(map[string]Status)
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		OnResult:  func(r Result) { seen[r.Name] = r.Status },
+	})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+
+	if len(seen) != len(results) {
+		t.Fatalf("expected %d callbacks, got %d", len(results), len(seen))
+	}
+	for _, r := range results {
+		if seen[r.Name] != r.Status {
+			t.Errorf("%s: callback saw %s, result is %s", r.Name, seen[r.Name], r.Status)
+		}
+	}
+}
+
+func TestCompareDirectoriesStream(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	red := color.RGBA{R: 255, A: 255}
+	for _, name := range []string{"a.png", "b.png", "c.png", "gone.png"} {
+		createTestPNG(t, filepath.Join(baselineDir, name), 10, 10, color.White)
+	}
+	createTestPNG(t, filepath.Join(currentDir, "a.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(currentDir, "b.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "c.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(currentDir, "new.png"), 10, 10, color.White)
+
+	stream := func(concurrency int) []string {
+		t.Helper()
+		var names []string
+		err := CompareDirectoriesStream(baselineDir, currentDir, CompareOptions{Threshold: 0.2, Concurrency: concurrency}, func(r Result) error {
+			names = append(names, r.Name+":"+r.Status.String())
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("CompareDirectoriesStream failed: %v", err)
+		}
+		return names
+	}
+
+	want := []string{"new.png:added", "gone.png:removed", "a.png:changed", "b.png:unchanged", "c.png:changed"}
+	for _, concurrency := range []int{1, 4} {
+		if got := stream(concurrency); !slices.Equal(got, want) {
+			t.Errorf("concurrency %d: streamed %v, want %v", concurrency, got, want)
+		}
+	}
+
+	// Sorted, the streamed results match the batch API
+	var streamed []Result
+	if err := CompareDirectoriesStream(baselineDir, currentDir, CompareOptions{Threshold: 0.2}, func(r Result) error {
+		streamed = append(streamed, r)
+		return nil
+	}); err != nil {
+		t.Fatalf("CompareDirectoriesStream failed: %v", err)
+	}
+	SortResults(streamed, SortByDiffPercent)
+	batch, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{Threshold: 0.2})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+	if len(streamed) != len(batch) {
+		t.Fatalf("streamed %d results, batch returned %d", len(streamed), len(batch))
+	}
+	for i := range batch {
+		if streamed[i].Name != batch[i].Name || streamed[i].Status != batch[i].Status {
+			t.Errorf("result %d: streamed %s %s, batch %s %s", i, streamed[i].Name, streamed[i].Status, batch[i].Name, batch[i].Status)
+		}
+	}
+
+	// An error from fn stops the comparison and is returned as-is
+	errStop := errors.New("stop")
+	calls := 0
+	err = CompareDirectoriesStream(baselineDir, currentDir, CompareOptions{Threshold: 0.2}, func(r Result) error {
+		calls++
+		if r.Status == StatusChanged {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected fn's error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected to stop at the first changed result (3 calls), got %d", calls)
+	}
+}
+
+func TestCompareDirectories_Dedupe(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	createTestPNG(t, filepath.Join(baselineDir, "page.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currentDir, "page.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currentDir, "page-retry1.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currentDir, "new.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(currentDir, "new-retry1.png"), 10, 10, red)
+
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{Threshold: 0.2, Dedupe: true})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+
+	got := make(map[string]Result)
+	for _, r := range results {
+		got[r.Name] = r
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 results after dedupe, got %d: %+v", len(got), results)
+	}
+	if r := got["page.png"]; r.Status != StatusUnchanged || len(r.Duplicates) != 1 || r.Duplicates[0] != "page-retry1.png" {
+		t.Errorf("unexpected page.png result: %+v", r)
+	}
+	if r := got["new-retry1.png"]; r.Status != StatusAdded || len(r.Duplicates) != 1 || r.Duplicates[0] != "new.png" {
+		t.Errorf("unexpected new-retry1.png result: %+v", r)
+	}
+
+	// Without --dedupe every capture is reported
+	results, err = CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{Threshold: 0.2})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("expected 4 results without dedupe, got %d", len(results))
+	}
+}
+
+func TestCompareDirectories_TrustMtime(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+	for _, d := range []string{baselineDir, currentDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The files are not valid PNGs, so any pair that is decoded fails
+	mtime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	write := func(path, content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	baseline := filepath.Join(baselineDir, "page.png")
+	current := filepath.Join(currentDir, "page.png")
+	write(baseline, "baseline", mtime)
+	write(current, "current!", mtime)
+
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{TrustMtime: true})
+	if err != nil {
+		t.Fatalf("expected the matching pair not to be decoded, got %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusUnchanged || results[0].CurrentPath != current {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	if _, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{}); err == nil {
+		t.Error("expected the pair to be decoded without TrustMtime")
+	}
+
+	write(current, "current!", mtime.Add(time.Second))
+	if _, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{TrustMtime: true}); err == nil {
+		t.Error("expected a different mtime to be decoded")
+	}
+
+	write(current, "current", mtime)
+	if _, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{TrustMtime: true}); err == nil {
+		t.Error("expected a different size to be decoded")
+	}
+}
+
+func TestPixelHash(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	b := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	if PixelHash(a) != PixelHash(b) {
+		t.Error("expected identical pixels in different image types to hash equal")
+	}
+
+	b.Set(1, 1, color.NRGBA{R: 1, A: 255})
+	if PixelHash(a) == PixelHash(b) {
+		t.Error("expected different pixels to hash differently")
+	}
+
+	if PixelHash(image.NewRGBA(image.Rect(0, 0, 2, 8))) == PixelHash(a) {
+		t.Error("expected different dimensions to hash differently")
+	}
+}
+
+func TestCompareImages_OverlayBase(t *testing.T) {
+	baseline := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	current := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	baseline.Set(0, 0, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+	current.Set(0, 0, color.RGBA{R: 200, G: 200, B: 200, A: 255})
+	baseline.Set(1, 0, color.RGBA{R: 100, A: 255})
+	current.Set(1, 0, color.RGBA{G: 100, A: 255})
+
+	tests := []struct {
+		base OverlayBase
+		want color.RGBA
+	}{
+		{"", color.RGBA{R: 60, G: 60, B: 60, A: 76}},
+		{OverlayBaseCurrent, color.RGBA{R: 60, G: 60, B: 60, A: 76}},
+		{OverlayBaseWhite, color.RGBA{R: 255, G: 255, B: 255, A: 255}},
+		{OverlayBaseBlack, color.RGBA{A: 255}},
+	}
+	for _, tt := range tests {
+		result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.1, OverlayBase: tt.base})
+		if err != nil {
+			t.Fatalf("CompareImages failed: %v", err)
+		}
+		if got := result.DiffImage.At(0, 0); got != tt.want {
+			t.Errorf("%q: unchanged pixel = %v, want %v", tt.base, got, tt.want)
+		}
+		if got := result.DiffImage.At(1, 0); got != (color.RGBA{R: 255, B: 255, A: 255}) {
+			t.Errorf("%q: changed pixel = %v, want magenta", tt.base, got)
+		}
+	}
+
+	// Unchanged pixels are near-identical on both sides, so check that the
+	// baseline is the one dimmed through the helper directly.
+	b := OverlayBaseBaseline.background([4]float64{100, 0, 0, 255}, [4]float64{0, 100, 0, 255})
+	if b != (color.RGBA{R: 30, A: 76}) {
+		t.Errorf("baseline background = %v, want dimmed baseline", b)
+	}
+
+	if _, err := ParseOverlayBase("purple"); err == nil {
+		t.Error("expected error for invalid overlay base")
+	}
+}
+
+func TestCompareDirectories_FailFast(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	for _, name := range []string{"a.png", "b.png", "c.png"} {
+		createTestPNG(t, filepath.Join(baselineDir, name), 10, 10, white)
+	}
+	createTestPNG(t, filepath.Join(currentDir, "a.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currentDir, "b.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(currentDir, "c.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(currentDir, "new.png"), 10, 10, white)
+
+	// Added screenshots are classified before any pixels are compared
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		FailFast:  []Status{StatusChanged, StatusAdded},
+	})
+	if !errors.Is(err, ErrStoppedEarly) {
+		t.Fatalf("expected ErrStoppedEarly, got %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "new.png" || results[0].Status != StatusAdded {
+		t.Errorf("expected to stop at new.png, got %+v", results)
+	}
+
+	// Only changed: stops at the first changed screenshot in name order
+	results, err = CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		FailFast:  []Status{StatusChanged},
+	})
+	if !errors.Is(err, ErrStoppedEarly) {
+		t.Fatalf("expected ErrStoppedEarly, got %v", err)
+	}
+	var changed []string
+	for _, r := range results {
+		if r.Status == StatusChanged {
+			changed = append(changed, r.Name)
+		}
+	}
+	if len(changed) != 1 || changed[0] != "b.png" {
+		t.Errorf("expected to stop at b.png, got changed %v", changed)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected new.png, a.png, and b.png before stopping, got %d results", len(results))
+	}
+
+	// No matching status: runs to completion
+	results, err = CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		FailFast:  []Status{StatusRemoved},
+	})
+	if err != nil || len(results) != 4 {
+		t.Errorf("expected a full run, got %d results, err %v", len(results), err)
+	}
+}
+
+func TestGenerateReport_AcceptCommand(t *testing.T) {
+	dir := t.TempDir()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	createTestPNG(t, filepath.Join(dir, "baseline", "page.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "page.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(dir, "baseline", "same.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "same.png"), 10, 10, white)
+
+	results, err := CompareDirectories(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	opts := ReportOptions{AcceptCommand: func(name string) string {
+		return "ods screenshot-diff accept --project admin --name " + name
+	}}
+	if err := GenerateReport(results, outputPath, opts); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	html, _ := os.ReadFile(outputPath)
+	if !contains(string(html), `data-cmd="ods screenshot-diff accept --project admin --name page.png"`) {
+		t.Error("report missing accept command for the changed screenshot")
+	}
+	if contains(string(html), "--name same.png") {
+		t.Error("unchanged screenshots should not get an accept command")
+	}
+}
+
+func TestCompareThreeWay(t *testing.T) {
+	dir := t.TempDir()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	// page changed since the baseline but matches the reference
+	createTestPNG(t, filepath.Join(dir, "baseline", "page.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "page.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(dir, "reference", "page.png"), 10, 10, red)
+	// new.png is missing from the reference
+	createTestPNG(t, filepath.Join(dir, "baseline", "new.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "new.png"), 10, 10, red)
+
+	results, err := CompareThreeWay(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), filepath.Join(dir, "reference"), CompareOptions{Threshold: 0.2})
+	if err != nil {
+		t.Fatalf("CompareThreeWay failed: %v", err)
+	}
+
+	byName := make(map[string]Result)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	page := byName["page.png"]
+	if page.Status != StatusChanged || page.Reference == nil || page.Reference.Status != StatusUnchanged {
+		t.Errorf("page.png: expected changed vs baseline and unchanged vs reference, got %+v", page)
+	}
+	if ref := byName["new.png"].Reference; ref == nil || ref.Status != StatusAdded {
+		t.Errorf("new.png: expected added vs reference, got %+v", ref)
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	if err := GenerateReport(results, outputPath, ReportOptions{}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	html, _ := os.ReadFile(outputPath)
+	if !contains(string(html), `alt="Reference"`) || !contains(string(html), "Diff vs Reference") {
+		t.Error("report missing the three-way view")
+	}
+}
+
+func TestCompareDirectories_Sidecars(t *testing.T) {
+	dir := t.TempDir()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	createTestPNG(t, filepath.Join(dir, "baseline", "docs.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "docs.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(dir, "baseline", "gone.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "bare.png"), 10, 10, white)
+
+	sidecars := map[string]string{
+		filepath.Join(dir, "current", "docs.json"):      `{"url": "http://localhost:3000/admin/documents?tab=all", "viewport": {"width": 1280, "height": 720}}`,
+		filepath.Join(dir, "baseline", "gone.png.json"): `{"url": "/admin/old"}`,
+	}
+	for path, content := range sidecars {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := CompareDirectoriesWithOptions(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), CompareOptions{Threshold: 0.2, Sidecars: true})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+
+	labels := make(map[string]string)
+	for _, r := range results {
+		labels[r.Name] = r.Context.Label()
+	}
+	want := map[string]string{
+		"docs.png": "1280×720 · /admin/documents?tab=all",
+		"gone.png": "/admin/old",
+		"bare.png": "",
+	}
+	for name, label := range want {
+		if labels[name] != label {
+			t.Errorf("%s: expected context %q, got %q", name, label, labels[name])
+		}
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	if err := GenerateReport(results, outputPath, ReportOptions{}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	html, _ := os.ReadFile(outputPath)
+	if !contains(string(html), `1280×720 · /admin/documents?tab=all</span>`) {
+		t.Error("report card missing the screenshot context")
+	}
+}
+
+func TestWriteReport_Sections(t *testing.T) {
+	results := []Result{
+		{Name: "gone.png", Status: StatusRemoved},
+		{Name: "new.png", Status: StatusAdded},
+		{Name: "a.png", Status: StatusChanged, DiffPercent: 1},
+		{Name: "b.png", Status: StatusChanged, DiffPercent: 2},
+		{Name: "same.png", Status: StatusUnchanged},
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, results, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	html := buf.String()
+
+	// Sections appear in a fixed order regardless of result order, and
+	// cards keep result order within their section
+	order := []string{">Changed (2)<", "a.png", "b.png", ">Added (1)<", "new.png", ">Removed (1)<", "gone.png"}
+	pos := 0
+	for _, s := range order {
+		i := strings.Index(html[pos:], s)
+		if i < 0 {
+			t.Fatalf("expected %q after offset %d in report", s, pos)
+		}
+		pos += i + len(s)
+	}
+
+	buf.Reset()
+	if err := writeReport(&buf, results[2:], ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	if strings.Contains(buf.String(), ">Added (") || strings.Contains(buf.String(), ">Removed (") {
+		t.Error("expected empty sections to be omitted")
+	}
+}
+
+func TestWriteReport_DiffStats(t *testing.T) {
+	results := []Result{
+		{Name: "a.png", Status: StatusChanged, DiffPercent: 1.5, DiffPixels: 150, TotalPixels: 10000, Regions: 3, LargestRegion: 90},
+		{Name: "b.png", Status: StatusChanged, DiffPercent: 0.01, DiffPixels: 1, TotalPixels: 10000, Regions: 1, LargestRegion: 1},
+		{Name: "new.png", Status: StatusAdded},
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, results, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{
+		"150 of 10000 pixels differ (1.50%) &middot; 3 regions, largest 90 px",
+		"1 of 10000 pixels differ (0.01%) &middot; 1 region, largest 1 px",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected stats %q in report", want)
+		}
+	}
+	if n := strings.Count(html, `class="card-stats"`); n != 2 {
+		t.Errorf("expected stats on the 2 changed cards only, got %d", n)
+	}
+}
+
+func TestWriteReport_Print(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, []Result{{Name: "a.png", Status: StatusChanged, DiffPercent: 1}}, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	html := buf.String()
+
+	for _, want := range []string{"@media print", `onclick="printReport()"`, `<div class="print-label">Diff overlay</div>`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %q in report", want)
+		}
+	}
+}
+
+func TestWriteReport_OmitBaseline(t *testing.T) {
+	dir := t.TempDir()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, A: 255}
+	baseline := filepath.Join(dir, "baseline.png")
+	current := filepath.Join(dir, "current.png")
+	createTestPNG(t, baseline, 4, 4, white)
+	createTestPNG(t, current, 4, 4, red)
+	results := []Result{
+		{Name: "changed.png", Status: StatusChanged, DiffPercent: 100, BaselinePath: baseline, CurrentPath: current},
+		{Name: "removed.png", Status: StatusRemoved, BaselinePath: baseline},
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, results, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `alt="Baseline"`) {
+		t.Error("expected the changed baseline to be embedded by default")
+	}
+
+	buf.Reset()
+	if err := writeReport(&buf, results, ReportOptions{OmitBaseline: true}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	html := buf.String()
+	if strings.Contains(html, `alt="Baseline"`) || strings.Contains(html, "switchTab(this, 'slider')") {
+		t.Error("expected no baseline image or slider for the changed screenshot")
+	}
+	if !strings.Contains(html, "switchTab(this, 'current')") {
+		t.Error("expected a Current tab for the changed screenshot")
+	}
+	if !strings.Contains(html, `alt="Removed screenshot"`) {
+		t.Error("expected the removed screenshot to keep its baseline")
+	}
+}
+
+func TestCompareImages_Palette(t *testing.T) {
+	baseline := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	current := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			baseline.SetRGBA(x, y, color.RGBA{A: 255})
+			current.SetRGBA(x, y, color.RGBA{A: 255})
+		}
+	}
+	current.SetRGBA(0, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+
+	for _, name := range []string{"", "default", "deuteranopia", "protanopia", "high-contrast"} {
+		palette, err := ParsePalette(name)
+		if err != nil {
+			t.Fatalf("ParsePalette(%q) failed: %v", name, err)
+		}
+		result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.2, Palette: palette})
+		if err != nil {
+			t.Fatalf("CompareImages failed: %v", err)
+		}
+		if got := result.DiffImage.At(0, 0); got != palette.Highlight() {
+			t.Errorf("%s: expected highlight %v, got %v", palette, palette.Highlight(), got)
+		}
+	}
+
+	if PaletteDefault.Highlight() != (color.RGBA{R: 255, B: 255, A: 255}) {
+		t.Errorf("expected the default palette to stay magenta, got %v", PaletteDefault.Highlight())
+	}
+	if _, err := ParsePalette("rainbow"); err == nil {
+		t.Error("expected an error for an unknown palette")
+	}
+}
+
+func TestWriteReport_PaletteLegend(t *testing.T) {
+	results := []Result{{Name: "a.png", Status: StatusChanged, DiffPercent: 1}}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, results, ReportOptions{Palette: PaletteDeuteranopia}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	html := buf.String()
+	if !strings.Contains(html, "background: #e69f00") || !strings.Contains(html, "(deuteranopia palette)") {
+		t.Error("expected a legend for the deuteranopia palette")
+	}
+}
+
+func TestStatus_JSONRoundTrip(t *testing.T) {
+	for _, status := range []Status{StatusUnchanged, StatusChanged, StatusAdded, StatusRemoved} {
+		data, err := json.Marshal(struct {
+			Status Status `json:"status"`
+		}{status})
+		if err != nil {
+			t.Fatalf("marshal %s failed: %v", status, err)
+		}
+		if want := `{"status":"` + status.String() + `"}`; string(data) != want {
+			t.Errorf("expected %s, got %s", want, data)
+		}
+
+		var decoded struct {
+			Status Status `json:"status"`
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("unmarshal %s failed: %v", data, err)
+		}
+		if decoded.Status != status {
+			t.Errorf("expected %s after round trip, got %s", status, decoded.Status)
+		}
+	}
+
+	var s Status
+	for _, bad := range []string{`"bogus"`, `1`} {
+		if err := json.Unmarshal([]byte(bad), &s); err == nil {
+			t.Errorf("expected an error unmarshaling %s", bad)
+		}
+	}
+	if _, err := json.Marshal(Status(42)); err == nil {
+		t.Error("expected an error marshaling an unknown status")
+	}
+}
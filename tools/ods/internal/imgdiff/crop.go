@@ -0,0 +1,56 @@
+package imgdiff
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParseCrop parses a crop rectangle given as "x,y,w,h" in pixels.
+func ParseCrop(s string) (image.Rectangle, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return image.Rectangle{}, fmt.Errorf("invalid crop %q: expected x,y,w,h", s)
+	}
+
+	var vals [4]int
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || v < 0 {
+			return image.Rectangle{}, fmt.Errorf("invalid crop %q: %q is not a non-negative integer", s, p)
+		}
+		vals[i] = v
+	}
+	if vals[2] == 0 || vals[3] == 0 {
+		return image.Rectangle{}, fmt.Errorf("invalid crop %q: width and height must be positive", s)
+	}
+
+	return image.Rect(vals[0], vals[1], vals[0]+vals[2], vals[1]+vals[3]), nil
+}
+
+// CropTop returns a crop rectangle that skips the top n pixels of an image
+// and keeps everything below it.
+func CropTop(n int) image.Rectangle {
+	return image.Rect(0, n, math.MaxInt32, math.MaxInt32)
+}
+
+// CropImage returns the part of img inside rect, where rect is relative to
+// the image's top-left corner. The result is clipped to the image bounds and
+// may be empty if rect lies entirely outside the image.
+func CropImage(img image.Image, rect image.Rectangle) image.Image {
+	b := img.Bounds()
+	r := rect.Add(b.Min).Intersect(b)
+
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(r)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
@@ -0,0 +1,58 @@
+package imgdiff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// csvHeader lists the columns written by ExportCSV.
+var csvHeader = []string{"name", "status", "diff_percent", "diff_pixels", "total_pixels"}
+
+// ExportCSV writes one row per result with the columns
+// name,status,diff_percent,diff_pixels,total_pixels. Added and removed
+// screenshots were never compared, so their numeric columns are left blank
+// rather than reported as zero; unchanged screenshots report real zeros.
+func ExportCSV(results []Result, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, r := range results {
+		row := []string{r.Name, r.Status.String(), "", "", ""}
+		if r.Status == StatusChanged || r.Status == StatusUnchanged {
+			row[2] = strconv.FormatFloat(r.DiffPercent, 'f', 4, 64)
+			row[3] = strconv.Itoa(r.DiffPixels)
+			row[4] = strconv.Itoa(r.TotalPixels)
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", r.Name, err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes results as CSV (see ExportCSV) to the given path,
+// creating parent directories as needed.
+func WriteCSV(results []Result, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for CSV: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return ExportCSV(results, f)
+}
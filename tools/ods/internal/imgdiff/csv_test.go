@@ -0,0 +1,30 @@
+package imgdiff
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportCSV(t *testing.T) {
+	results := []Result{
+		{Name: "changed.png", Status: StatusChanged, DiffPercent: 12.5, DiffPixels: 125, TotalPixels: 1000},
+		{Name: "new, page.png", Status: StatusAdded},
+		{Name: "gone.png", Status: StatusRemoved},
+		{Name: "same.png", Status: StatusUnchanged, TotalPixels: 1000},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportCSV(results, &buf); err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+
+	want := `name,status,diff_percent,diff_pixels,total_pixels
+changed.png,changed,12.5000,125,1000
+"new, page.png",added,,,
+gone.png,removed,,,
+same.png,unchanged,0.0000,0,1000
+`
+	if buf.String() != want {
+		t.Errorf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
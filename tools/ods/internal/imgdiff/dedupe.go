@@ -0,0 +1,82 @@
+package imgdiff
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// PixelHash returns a hex digest of an image's dimensions and pixel values,
+// so two images hash equal exactly when they would compare identical,
+// regardless of PNG encoding details.
+func PixelHash(img image.Image) string {
+	h := sha256.New()
+	bounds := img.Bounds()
+
+	var dims [8]byte
+	binary.BigEndian.PutUint32(dims[0:4], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(dims[4:8], uint32(bounds.Dy()))
+	h.Write(dims[:])
+
+	row := make([]byte, 0, bounds.Dx()*8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+			row = binary.BigEndian.AppendUint16(row, c.R)
+			row = binary.BigEndian.AppendUint16(row, c.G)
+			row = binary.BigEndian.AppendUint16(row, c.B)
+			row = binary.BigEndian.AppendUint16(row, c.A)
+		}
+		h.Write(row)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// dedupeCurrent collapses current screenshots with identical pixels. Within
+// each group of identical images, every screenshot that is paired with a
+// baseline is kept; the unpaired ones are removed from currentMap and listed
+// under the group's canonical name (its first paired member, or else its
+// first name alphabetically). It returns canonical name -> duplicate names.
+func dedupeCurrent(currentMap, pairs map[string]string, svgDPI float64) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for name, path := range currentMap {
+		img, err := decodeScreenshot(path, svgDPI)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode current %s: %w", path, err)
+		}
+		hash := PixelHash(img)
+		groups[hash] = append(groups[hash], name)
+	}
+
+	duplicates := make(map[string][]string)
+	for _, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+
+		canonical := names[0]
+		for _, name := range names {
+			if _, ok := pairs[name]; ok {
+				canonical = name
+				break
+			}
+		}
+
+		for _, name := range names {
+			if _, ok := pairs[name]; ok || name == canonical {
+				continue
+			}
+			delete(currentMap, name)
+			duplicates[canonical] = append(duplicates[canonical], name)
+		}
+	}
+
+	return duplicates, nil
+}
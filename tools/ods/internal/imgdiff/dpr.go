@@ -0,0 +1,70 @@
+package imgdiff
+
+import (
+	"image"
+	"image/color"
+)
+
+// dprScale reports whether current is an integer multiple of baseline's
+// size in both dimensions, as when the two were captured at different device
+// pixel ratios. It returns the current/baseline ratio (e.g. 2 for a 2x
+// current against a 1x baseline, 0.5 for the reverse), or 0 when the sizes
+// match or are not an integer multiple of each other.
+func dprScale(baseline, current image.Rectangle) float64 {
+	bw, bh := baseline.Dx(), baseline.Dy()
+	cw, ch := current.Dx(), current.Dy()
+	if bw == 0 || bh == 0 || cw == 0 || ch == 0 || (bw == cw && bh == ch) {
+		return 0
+	}
+	if cw%bw == 0 && ch%bh == 0 && cw/bw == ch/bh {
+		return float64(cw / bw)
+	}
+	if bw%cw == 0 && bh%ch == 0 && bw/cw == bh/ch {
+		return 1 / float64(bw/cw)
+	}
+	return 0
+}
+
+// shrink scales img down by an integer factor, averaging each factor ×
+// factor block of source pixels into one output pixel.
+func shrink(img image.Image, factor int) image.Image {
+	b := img.Bounds()
+	dw, dh := b.Dx()/factor, b.Dy()/factor
+	dst := image.NewRGBA64(image.Rect(0, 0, dw, dh))
+	n := uint64(factor * factor)
+
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var r, g, bl, a uint64
+			for sy := b.Min.Y + dy*factor; sy < b.Min.Y+(dy+1)*factor; sy++ {
+				for sx := b.Min.X + dx*factor; sx < b.Min.X+(dx+1)*factor; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					bl += uint64(pb)
+					a += uint64(pa)
+				}
+			}
+			dst.SetRGBA64(dx, dy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+// normalizeDPR scales whichever of baseline and current is larger down to
+// the other's size when scale (see dprScale) is non-zero.
+func normalizeDPR(baseline, current image.Image, scale float64) (image.Image, image.Image) {
+	switch {
+	case scale > 1:
+		current = shrink(current, int(scale))
+	case scale > 0:
+		baseline = shrink(baseline, int(1/scale+0.5))
+	}
+	return baseline, current
+}
@@ -0,0 +1,81 @@
+package imgdiff
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDPRScale(t *testing.T) {
+	tests := []struct {
+		baseline, current image.Rectangle
+		want              float64
+	}{
+		{image.Rect(0, 0, 100, 50), image.Rect(0, 0, 100, 50), 0},
+		{image.Rect(0, 0, 100, 50), image.Rect(0, 0, 200, 100), 2},
+		{image.Rect(0, 0, 300, 150), image.Rect(0, 0, 100, 50), 1.0 / 3},
+		{image.Rect(0, 0, 100, 50), image.Rect(0, 0, 200, 150), 0}, // different factors
+		{image.Rect(0, 0, 100, 50), image.Rect(0, 0, 150, 75), 0},  // not an integer multiple
+		{image.Rect(0, 0, 0, 0), image.Rect(0, 0, 200, 100), 0},
+	}
+	for _, tt := range tests {
+		if got := dprScale(tt.baseline, tt.current); got != tt.want {
+			t.Errorf("dprScale(%v, %v) = %g, want %g", tt.baseline, tt.current, got, tt.want)
+		}
+	}
+}
+
+// upscale returns img enlarged by an integer factor with nearest-neighbour
+// sampling, mimicking a capture at a higher device pixel ratio.
+func upscale(img image.Image, factor int) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx()*factor, b.Dy()*factor))
+	for y := 0; y < dst.Bounds().Dy(); y++ {
+		for x := 0; x < dst.Bounds().Dx(); x++ {
+			dst.Set(x, y, img.At(b.Min.X+x/factor, b.Min.Y+y/factor))
+		}
+	}
+	return dst
+}
+
+func TestCompareImages_DPRChange(t *testing.T) {
+	baseline := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{255, 255, 255, 255}
+			if x >= 10 && x < 20 && y >= 5 && y < 15 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			baseline.Set(x, y, c)
+		}
+	}
+	current := upscale(baseline, 2)
+
+	result, err := CompareImages(baseline, current, CompareOptions{Threshold: 0.1})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusChanged || result.DPRScale != 2 {
+		t.Errorf("without normalizing: got status %s, DPRScale %g; want changed, 2", result.Status, result.DPRScale)
+	}
+
+	result, err = CompareImages(baseline, current, CompareOptions{Threshold: 0.1, NormalizeDPR: true})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusUnchanged || result.DPRScale != 2 {
+		t.Errorf("normalized: got status %s, DPRScale %g; want unchanged, 2", result.Status, result.DPRScale)
+	}
+	if got := result.DiffImage.Bounds(); got.Dx() != 40 || got.Dy() != 20 {
+		t.Errorf("normalized diff image is %dx%d, want the baseline's 40x20", got.Dx(), got.Dy())
+	}
+
+	// Scaling works the other way round too
+	result, err = CompareImages(current, baseline, CompareOptions{Threshold: 0.1, NormalizeDPR: true})
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if result.Status != StatusUnchanged || result.DPRScale != 0.5 {
+		t.Errorf("normalized, reversed: got status %s, DPRScale %g; want unchanged, 0.5", result.Status, result.DPRScale)
+	}
+}
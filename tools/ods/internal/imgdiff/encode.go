@@ -0,0 +1,86 @@
+package imgdiff
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+
+	"github.com/HugoSmits86/nativewebp"
+)
+
+// PNGCompression selects the zlib compression level for PNGs written by this
+// package, trading file size for encode speed.
+type PNGCompression string
+
+const (
+	// PNGCompressionDefault uses Go's default level (the historical behavior).
+	PNGCompressionDefault PNGCompression = "default"
+	// PNGCompressionFast favors encode speed over file size.
+	PNGCompressionFast PNGCompression = "fast"
+	// PNGCompressionBest favors file size over encode speed.
+	PNGCompressionBest PNGCompression = "best"
+)
+
+// ParsePNGCompression validates a compression level name. An empty string
+// selects PNGCompressionDefault.
+func ParsePNGCompression(s string) (PNGCompression, error) {
+	switch c := PNGCompression(s); c {
+	case "":
+		return PNGCompressionDefault, nil
+	case PNGCompressionDefault, PNGCompressionFast, PNGCompressionBest:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid PNG compression %q (valid: %s, %s, %s)", s, PNGCompressionDefault, PNGCompressionFast, PNGCompressionBest)
+}
+
+// level maps the compression name to the image/png encoder level.
+func (c PNGCompression) level() png.CompressionLevel {
+	switch c {
+	case PNGCompressionFast:
+		return png.BestSpeed
+	case PNGCompressionBest:
+		return png.BestCompression
+	default:
+		return png.DefaultCompression
+	}
+}
+
+// ImageFormat is the encoding used for images embedded in the HTML report.
+type ImageFormat string
+
+const (
+	// ImageFormatPNG embeds PNGs, supported by every browser (the default).
+	ImageFormatPNG ImageFormat = "png"
+	// ImageFormatWebP embeds lossless WebP, which is considerably smaller.
+	ImageFormatWebP ImageFormat = "webp"
+)
+
+// ParseImageFormat validates a report image format name. An empty string
+// selects ImageFormatPNG.
+func ParseImageFormat(s string) (ImageFormat, error) {
+	switch f := ImageFormat(s); f {
+	case "":
+		return ImageFormatPNG, nil
+	case ImageFormatPNG, ImageFormatWebP:
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid image format %q (valid: %s, %s)", s, ImageFormatPNG, ImageFormatWebP)
+}
+
+// encodeWebP writes img to w as a lossless WebP image. The encoder can panic
+// on some inputs, so panics are returned as errors for the caller to handle.
+func encodeWebP(w io.Writer, img image.Image) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("webp encoder failed: %v", r)
+		}
+	}()
+	return nativewebp.Encode(w, img, nil)
+}
+
+// encodePNG writes img to w as a PNG at the given compression level.
+func encodePNG(w io.Writer, img image.Image, compression PNGCompression) error {
+	enc := png.Encoder{CompressionLevel: compression.level()}
+	return enc.Encode(w, img)
+}
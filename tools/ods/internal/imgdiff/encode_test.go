@@ -0,0 +1,216 @@
+package imgdiff
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePNGCompression(t *testing.T) {
+	for _, s := range []string{"", "default", "fast", "best"} {
+		if _, err := ParsePNGCompression(s); err != nil {
+			t.Errorf("ParsePNGCompression(%q) failed: %v", s, err)
+		}
+	}
+	if _, err := ParsePNGCompression("fastest"); err == nil {
+		t.Error("expected error for unknown compression level")
+	}
+}
+
+func TestEncodePNG_RoundTrip(t *testing.T) {
+	img := largeOverlay(64, 64)
+	for _, c := range []PNGCompression{PNGCompressionDefault, PNGCompressionFast, PNGCompressionBest} {
+		var buf bytes.Buffer
+		if err := encodePNG(&buf, img, c); err != nil {
+			t.Fatalf("encodePNG(%s) failed: %v", c, err)
+		}
+		decoded, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("decode (%s) failed: %v", c, err)
+		}
+		if decoded.At(10, 10) != img.At(10, 10) {
+			t.Errorf("%s: pixel mismatch after round trip", c)
+		}
+	}
+}
+
+// largeOverlay builds a diff-overlay-like image: mostly dimmed background
+// with scattered red highlights.
+func largeOverlay(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255}
+			if (x/16+y/16)%7 == 0 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func BenchmarkEncodePNG(b *testing.B) {
+	img := largeOverlay(2560, 1600)
+	for _, c := range []PNGCompression{PNGCompressionFast, PNGCompressionBest} {
+		b.Run(string(c), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := encodePNG(io.Discard, img, c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebP(t *testing.T) {
+	var buf bytes.Buffer
+	if err := encodeWebP(&buf, uiScreenshot(320, 240)); err != nil {
+		t.Fatalf("encodeWebP failed: %v", err)
+	}
+	data := buf.Bytes()
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		t.Errorf("output is not a WebP file: % x", data[:min(len(data), 12)])
+	}
+}
+
+func TestScreenshotDataURI_WebP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.png")
+	createTestPNG(t, path, 40, 20, color.White)
+
+	uri, err := screenshotDataURI(path, ReportOptions{ImageFormat: ImageFormatWebP})
+	if err != nil {
+		t.Fatalf("screenshotDataURI failed: %v", err)
+	}
+	if !strings.HasPrefix(uri, "data:image/webp;base64,") {
+		t.Errorf("expected a WebP data URI, got %.40s", uri)
+	}
+}
+
+func TestParseImageFormat(t *testing.T) {
+	if f, err := ParseImageFormat(""); err != nil || f != ImageFormatPNG {
+		t.Errorf("expected png default, got %q, %v", f, err)
+	}
+	if f, err := ParseImageFormat("webp"); err != nil || f != ImageFormatWebP {
+		t.Errorf("expected webp, got %q, %v", f, err)
+	}
+	if _, err := ParseImageFormat("jpeg"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+// uiScreenshot builds a screenshot-like image: white page, light panels,
+// and rows of dark "text" runs.
+func uiScreenshot(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if x < 240 || (y/300)%2 == 1 {
+				c = color.RGBA{R: 245, G: 246, B: 248, A: 255}
+			}
+			if y%24 < 12 && (x*31+y/24*17)%97 < 60 && x%200 > 20 {
+				shade := uint8(40 + (x*7+y*3)%60)
+				c = color.RGBA{R: shade, G: shade, B: shade + 10, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// BenchmarkReportImageSize reports the encoded size of a UI-like screenshot
+// as PNG and WebP, to gauge how much --report-image-format webp saves.
+func BenchmarkReportImageSize(b *testing.B) {
+	img := uiScreenshot(1280, 2400)
+	for _, format := range []ImageFormat{ImageFormatPNG, ImageFormatWebP} {
+		b.Run(string(format), func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				var buf bytes.Buffer
+				var err error
+				if format == ImageFormatWebP {
+					err = encodeWebP(&buf, img)
+				} else {
+					err = encodePNG(&buf, img, PNGCompressionDefault)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = buf.Len()
+			}
+			b.ReportMetric(float64(size), "bytes")
+		})
+	}
+}
+
+// reportResults writes n changed screenshot pairs to dir and returns their
+// results, diff overlays included, for report generation tests.
+func reportResults(t testing.TB, dir string, n int) []Result {
+	t.Helper()
+	shot := uiScreenshot(640, 480)
+	results := make([]Result, n)
+	for i := range results {
+		name := fmt.Sprintf("page-%03d.png", i)
+		for _, side := range []string{"baseline", "current"} {
+			path := filepath.Join(dir, side, name)
+			if err := SaveDiffImage(shot, path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		results[i] = Result{
+			Name:         name,
+			Status:       StatusChanged,
+			DiffPercent:  float64(n - i),
+			BaselinePath: filepath.Join(dir, "baseline", name),
+			CurrentPath:  filepath.Join(dir, "current", name),
+			DiffImage:    shot,
+		}
+	}
+	return results
+}
+
+func TestWriteReport_ConcurrencyMatchesSequential(t *testing.T) {
+	results := reportResults(t, t.TempDir(), 8)
+	results = append(results, Result{Name: "same.png", Status: StatusUnchanged})
+
+	render := func(concurrency int) string {
+		var buf bytes.Buffer
+		if err := writeReport(&buf, results, ReportOptions{MaxWidth: 320, Concurrency: concurrency}); err != nil {
+			t.Fatalf("writeReport (concurrency %d) failed: %v", concurrency, err)
+		}
+		return buf.String()
+	}
+	if render(1) != render(4) {
+		t.Error("expected a concurrent report to be identical to a sequential one")
+	}
+
+	// An error from any worker fails the report
+	results[5].CurrentPath = filepath.Join(t.TempDir(), "missing.png")
+	var buf bytes.Buffer
+	err := writeReport(&buf, results, ReportOptions{Concurrency: 4})
+	if err == nil || !strings.Contains(err.Error(), "page-005.png") {
+		t.Errorf("expected an error naming page-005.png, got %v", err)
+	}
+}
+
+func BenchmarkWriteReport(b *testing.B) {
+	results := reportResults(b, b.TempDir(), 32)
+	for _, concurrency := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			// Downscaling forces every screenshot to be decoded and re-encoded
+			opts := ReportOptions{MaxWidth: 480, Concurrency: concurrency}
+			for i := 0; i < b.N; i++ {
+				if err := writeReport(io.Discard, results, opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package imgdiff
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReportMode selects where the report's images live.
+type ReportMode string
+
+const (
+	// ReportModeInline embeds every image in the HTML as a data URI, so the
+	// report is a single self-contained file (the default).
+	ReportModeInline ReportMode = "inline"
+	// ReportModeExternal writes images to an images/ directory next to the
+	// report and links them with relative paths, keeping the HTML small.
+	ReportModeExternal ReportMode = "external"
+)
+
+// externalImageDir is the directory, relative to the report, that
+// ReportModeExternal writes images to.
+const externalImageDir = "images"
+
+// ParseReportMode validates a report mode name. An empty string selects
+// ReportModeInline.
+func ParseReportMode(s string) (ReportMode, error) {
+	switch m := ReportMode(s); m {
+	case "":
+		return ReportModeInline, nil
+	case ReportModeInline, ReportModeExternal:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid report mode %q (valid: %s, %s)", s, ReportModeInline, ReportModeExternal)
+}
+
+// errNoImage is returned by reportImage when a result has no image of the
+// requested kind.
+var errNoImage = errors.New("no such image")
+
+// reportImage returns one of a result's images encoded as it is shown in the
+// report, and its MIME type.
+func reportImage(r Result, kind ImageKind, opts ReportOptions) ([]byte, string, error) {
+	var (
+		path string
+		img  image.Image
+	)
+	switch kind {
+	case ImageBaseline:
+		path = r.BaselinePath
+	case ImageCurrent:
+		path = r.CurrentPath
+	case ImageDiff:
+		img = r.DiffImage
+	case ImageReference:
+		if r.Reference != nil {
+			path = r.Reference.BaselinePath
+		}
+	case ImageReferenceDiff:
+		if r.Reference != nil {
+			img = r.Reference.DiffImage
+		}
+	case ImageThumbnail:
+		if r.CurrentPath != "" {
+			thumb, err := thumbnail(r.CurrentPath, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			img = thumb
+		}
+	}
+
+	switch {
+	case path != "" && opts.embedsAsIs(path):
+		data, err := os.ReadFile(path)
+		return data, "image/png", err
+	case path != "":
+		decoded, err := reportScreenshot(path, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		img = decoded
+	case img == nil:
+		return nil, "", errNoImage
+	case kind == ImageDiff || kind == ImageReferenceDiff:
+		img = downscale(img, opts.MaxWidth, opts.MaxHeight)
+	}
+
+	return encodeReportImage(img, opts)
+}
+
+// externalImages writes report images to files under dir as the report
+// references them, and returns their URLs relative to the report.
+type externalImages struct {
+	dir     string
+	results map[string]Result
+	opts    ReportOptions
+	used    map[string]bool
+	err     error // first write failure; later images are skipped
+}
+
+func newExternalImages(dir string, results []Result, opts ReportOptions) *externalImages {
+	byName := make(map[string]Result, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	return &externalImages{dir: dir, results: byName, opts: opts, used: make(map[string]bool)}
+}
+
+// url writes the named result's image of the given kind and returns its
+// relative URL. It has the signature of ReportOptions.ImageURL.
+func (e *externalImages) url(name string, kind ImageKind) string {
+	if e.err != nil {
+		return ""
+	}
+
+	data, mimeType, err := reportImage(e.results[name], kind, e.opts)
+	if err != nil {
+		e.err = fmt.Errorf("failed to encode %s image for %s: %w", kind, name, err)
+		return ""
+	}
+
+	ext := ".png"
+	if mimeType == "image/webp" {
+		ext = ".webp"
+	}
+	file := e.fileName(name, kind, ext)
+	if err := os.WriteFile(filepath.Join(e.dir, file), data, 0644); err != nil {
+		e.err = fmt.Errorf("failed to write %s image for %s: %w", kind, name, err)
+		return ""
+	}
+	return externalImageDir + "/" + url.PathEscape(file)
+}
+
+// fileName returns a unique, filesystem-safe file name for an image, e.g.
+// "settings_general-diff.png" for kind "diff" of "settings/general.png".
+// Screenshot names that sanitize to the same name get a numeric suffix.
+func (e *externalImages) fileName(name string, kind ImageKind, ext string) string {
+	base := sanitizeFileName(strings.TrimSuffix(name, filepath.Ext(name)))
+	file := base + "-" + string(kind) + ext
+	for i := 2; e.used[file]; i++ {
+		file = fmt.Sprintf("%s-%d-%s%s", base, i, kind, ext)
+	}
+	e.used[file] = true
+	return file
+}
+
+// sanitizeFileName replaces everything but letters, digits, '-', '_', and
+// '.' with '_', and never returns a name that starts with '.', so the result
+// cannot escape or hide in the image directory.
+func sanitizeFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	if safe == "" || safe[0] == '.' {
+		safe = "_" + safe
+	}
+	return safe
+}
@@ -0,0 +1,82 @@
+package imgdiff
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestGenerateReport_External(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	// "home page" and "home_page" sanitize to the same file name
+	for _, name := range []string{"home page.png", "home_page.png"} {
+		createTestPNG(t, filepath.Join(baselineDir, name), 20, 20, white)
+		createTestPNG(t, filepath.Join(currentDir, name), 20, 20, red)
+	}
+
+	results, err := CompareDirectories(baselineDir, currentDir, 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	outputPath := filepath.Join(dir, "report", "index.html")
+	if err := GenerateReport(results, outputPath, ReportOptions{Mode: ReportModeExternal}); err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "report", "images"))
+	if err != nil {
+		t.Fatalf("failed to read image directory: %v", err)
+	}
+	var files []string
+	for _, e := range entries {
+		files = append(files, e.Name())
+	}
+	sort.Strings(files)
+	want := []string{
+		"home_page-2-baseline.png", "home_page-2-current.png", "home_page-2-diff.png",
+		"home_page-baseline.png", "home_page-current.png", "home_page-diff.png",
+	}
+	if len(files) != len(want) {
+		t.Fatalf("expected images %v, got %v", want, files)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("expected images %v, got %v", want, files)
+			break
+		}
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if contains(string(content), "data:image/png") {
+		t.Error("expected no inline images in an external report")
+	}
+	if !contains(string(content), `src="images/home_page-diff.png"`) {
+		t.Error("expected a relative link to images/home_page-diff.png")
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	for name, want := range map[string]string{
+		"settings-general": "settings-general",
+		"home page":        "home_page",
+		"../../etc/passwd": "_.._.._etc_passwd",
+		".hidden":          "_.hidden",
+		"":                 "_",
+	} {
+		if got := sanitizeFileName(name); got != want {
+			t.Errorf("sanitizeFileName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package imgdiff
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// filmstripGap is the spacing between frames in a filmstrip, in pixels.
+const filmstripGap = 8
+
+// filmstripBackground fills the gaps between frames and below short frames.
+var filmstripBackground = color.RGBA{R: 64, G: 64, B: 64, A: 255}
+
+// Filmstrip lays images out left to right, in order, with a small gap
+// between them. Each frame is downscaled (preserving aspect ratio) to fit
+// within frameHeight; a frameHeight of 0 keeps frames at full size.
+func Filmstrip(frames []image.Image, frameHeight int) image.Image {
+	scaled := make([]image.Image, len(frames))
+	width, height := 0, 0
+	for i, f := range frames {
+		scaled[i] = downscale(f, 0, frameHeight)
+		b := scaled[i].Bounds()
+		width += b.Dx()
+		height = max(height, b.Dy())
+	}
+	width += filmstripGap * max(len(frames)-1, 0)
+
+	strip := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(strip, strip.Bounds(), &image.Uniform{C: filmstripBackground}, image.Point{}, draw.Src)
+
+	x := 0
+	for _, f := range scaled {
+		b := f.Bounds()
+		draw.Draw(strip, image.Rect(x, 0, x+b.Dx(), b.Dy()), f, b.Min, draw.Src)
+		x += b.Dx() + filmstripGap
+	}
+	return strip
+}
+
+// WriteFilmstrip decodes the PNGs at paths and writes them as a single
+// filmstrip PNG (see Filmstrip) to outputPath.
+func WriteFilmstrip(paths []string, outputPath string, frameHeight int) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no images to include in filmstrip")
+	}
+
+	frames := make([]image.Image, 0, len(paths))
+	for _, p := range paths {
+		img, err := decodePNG(p)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s: %w", p, err)
+		}
+		frames = append(frames, img)
+	}
+
+	return SaveDiffImage(Filmstrip(frames, frameHeight), outputPath)
+}
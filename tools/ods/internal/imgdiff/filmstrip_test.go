@@ -0,0 +1,29 @@
+package imgdiff
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFilmstrip(t *testing.T) {
+	red := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	blue := image.NewRGBA(image.Rect(0, 0, 40, 200))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			red.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	strip := Filmstrip([]image.Image{red, blue}, 100)
+	// blue is scaled to 20x100; red already fits
+	if got := strip.Bounds(); got.Dx() != 100+filmstripGap+20 || got.Dy() != 100 {
+		t.Errorf("unexpected filmstrip size %dx%d", got.Dx(), got.Dy())
+	}
+	if r, _, _, _ := strip.At(10, 10).RGBA(); r>>8 != 255 {
+		t.Error("expected first frame at the left edge")
+	}
+	if strip.At(10, 75) != color.Color(filmstripBackground) {
+		t.Error("expected background below the shorter frame")
+	}
+}
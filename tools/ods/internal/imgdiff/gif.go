@@ -0,0 +1,88 @@
+package imgdiff
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultGIFDelayMs is the default time each frame of a blink GIF is shown.
+const DefaultGIFDelayMs = 800
+
+// WriteBlinkGIFs writes a two-frame "blink comparator" GIF (baseline, then
+// current) for every changed result into dir. Added, removed, and unchanged
+// results are skipped. SVG screenshots are rasterized at svgDPI (see
+// CompareOptions.SVGDPI). It returns the paths of the GIFs written.
+func WriteBlinkGIFs(results []Result, dir string, delayMs int, svgDPI float64) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create GIF directory: %w", err)
+	}
+
+	var written []string
+	for _, r := range results {
+		if r.Status != StatusChanged || r.BaselinePath == "" || r.CurrentPath == "" {
+			continue
+		}
+
+		// Nested comparisons name results <project>/<screenshot>
+		path := filepath.Join(dir, strings.TrimSuffix(r.Name, filepath.Ext(r.Name))+".gif")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return written, fmt.Errorf("failed to create GIF directory: %w", err)
+		}
+		if err := WriteBlinkGIF(r.BaselinePath, r.CurrentPath, path, delayMs, svgDPI); err != nil {
+			return written, fmt.Errorf("failed to write GIF for %s: %w", r.Name, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+// WriteBlinkGIF encodes a looping two-frame GIF that alternates between the
+// baseline and current screenshots, rasterizing SVGs at svgDPI (zero means
+// DefaultSVGDPI) so the frames match the compared images. Both frames are
+// quantized to the same palette and drawn onto a canvas large enough to
+// hold either image.
+func WriteBlinkGIF(baselinePath, currentPath, outputPath string, delayMs int, svgDPI float64) error {
+	baseline, err := decodeScreenshot(baselinePath, svgDPI)
+	if err != nil {
+		return fmt.Errorf("failed to decode baseline %s: %w", baselinePath, err)
+	}
+
+	current, err := decodeScreenshot(currentPath, svgDPI)
+	if err != nil {
+		return fmt.Errorf("failed to decode current %s: %w", currentPath, err)
+	}
+
+	width := max(baseline.Bounds().Dx(), current.Bounds().Dx())
+	height := max(baseline.Bounds().Dy(), current.Bounds().Dy())
+	canvas := image.Rect(0, 0, width, height)
+
+	// GIF delays are expressed in hundredths of a second
+	delay := max(delayMs/10, 1)
+
+	anim := &gif.GIF{LoopCount: 0}
+	for _, src := range []image.Image{baseline, current} {
+		frame := image.NewPaletted(canvas, palette.Plan9)
+		draw.FloydSteinberg.Draw(frame, src.Bounds().Sub(src.Bounds().Min), src, src.Bounds().Min)
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := gif.EncodeAll(f, anim); err != nil {
+		return fmt.Errorf("failed to encode GIF: %w", err)
+	}
+
+	return nil
+}
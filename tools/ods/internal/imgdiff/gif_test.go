@@ -0,0 +1,60 @@
+package imgdiff
+
+import (
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteBlinkGIFs(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+	gifDir := filepath.Join(dir, "gifs")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	createTestPNG(t, filepath.Join(baselineDir, "changed.png"), 20, 20, white)
+	createTestPNG(t, filepath.Join(currentDir, "changed.png"), 20, 30, red)
+	createTestPNG(t, filepath.Join(currentDir, "added.png"), 20, 20, red)
+	createTestPNG(t, filepath.Join(baselineDir, "removed.png"), 20, 20, white)
+
+	results, err := CompareDirectories(baselineDir, currentDir, 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	written, err := WriteBlinkGIFs(results, gifDir, 500, DefaultSVGDPI)
+	if err != nil {
+		t.Fatalf("WriteBlinkGIFs failed: %v", err)
+	}
+	if len(written) != 1 {
+		t.Fatalf("expected 1 GIF (changed only), got %d", len(written))
+	}
+	if filepath.Base(written[0]) != "changed.gif" {
+		t.Errorf("expected changed.gif, got %s", filepath.Base(written[0]))
+	}
+
+	f, err := os.Open(written[0])
+	if err != nil {
+		t.Fatalf("failed to open GIF: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("failed to decode GIF: %v", err)
+	}
+	if len(anim.Image) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(anim.Image))
+	}
+	if anim.Delay[0] != 50 || anim.Delay[1] != 50 {
+		t.Errorf("expected delays of 50, got %v", anim.Delay)
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 20 || b.Dy() != 30 {
+		t.Errorf("expected 20x30 frame, got %dx%d", b.Dx(), b.Dy())
+	}
+}
@@ -0,0 +1,161 @@
+package imgdiff
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Subdirectories of a directory written by SaveResultImages.
+const (
+	savedBaselineDir = "baseline"
+	savedCurrentDir  = "current"
+	savedDiffDir     = "diff"
+)
+
+// SaveResultImages writes the images of every changed, added, or removed
+// result into dir (baseline/, current/, and diff/ subdirectories, each keyed
+// by result name), so that ResultsFromSummary can later rebuild the results
+// for a report without comparing again. Diff overlays are encoded at the
+// given compression level. Unchanged and errored results are skipped.
+func SaveResultImages(results []Result, dir string, compression PNGCompression) error {
+	for _, r := range results {
+		if r.Status == StatusUnchanged || r.Status == StatusError {
+			continue
+		}
+		if r.BaselinePath != "" {
+			if err := copyImage(r.BaselinePath, filepath.Join(dir, savedBaselineDir, r.Name)); err != nil {
+				return fmt.Errorf("failed to save baseline %s: %w", r.Name, err)
+			}
+		}
+		if r.CurrentPath != "" {
+			if err := copyImage(r.CurrentPath, filepath.Join(dir, savedCurrentDir, r.Name)); err != nil {
+				return fmt.Errorf("failed to save current %s: %w", r.Name, err)
+			}
+		}
+		if r.DiffImage != nil {
+			path := savedDiffPath(dir, r.Name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", path, err)
+			}
+			if err := SaveDiffImageWithCompression(r.DiffImage, path, compression); err != nil {
+				return fmt.Errorf("failed to save diff %s: %w", r.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResultsFromSummary rebuilds comparison results from a summary's per-file
+// detail and the images SaveResultImages wrote to dir. Diff overlays are
+// decoded from disk rather than recomputed. Unchanged and errored results
+// carry no images, so they are listed by name only.
+func ResultsFromSummary(summary Summary, dir string) ([]Result, error) {
+	if summary.Total > 0 && len(summary.Files) == 0 {
+		return nil, fmt.Errorf("summary has no per-file detail")
+	}
+
+	results := make([]Result, 0, len(summary.Files))
+	for _, f := range summary.Files {
+		r, err := fileResult(f)
+		if err != nil {
+			return nil, err
+		}
+		status := r.Status
+		if status == StatusUnchanged || status == StatusError {
+			results = append(results, r)
+			continue
+		}
+
+		if status != StatusAdded {
+			if r.BaselinePath, err = savedImage(filepath.Join(dir, savedBaselineDir, f.Name)); err != nil {
+				return nil, err
+			}
+		}
+		if status != StatusRemoved {
+			if r.CurrentPath, err = savedImage(filepath.Join(dir, savedCurrentDir, f.Name)); err != nil {
+				return nil, err
+			}
+		}
+		if status == StatusChanged {
+			path := savedDiffPath(dir, f.Name)
+			if _, err := os.Stat(path); err == nil {
+				if r.DiffImage, err = decodePNG(path); err != nil {
+					return nil, fmt.Errorf("failed to decode diff %s: %w", path, err)
+				}
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// fileResult converts a summary's per-file entry back into a Result without
+// any images.
+func fileResult(f FileSummary) (Result, error) {
+	status, err := ParseStatus(f.Status)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid status for %s: %w", f.Name, err)
+	}
+	return Result{
+		Name:          f.Name,
+		Project:       f.Project,
+		Status:        status,
+		DiffPercent:   f.DiffPercent,
+		DiffPixels:    f.DiffPixels,
+		TotalPixels:   f.TotalPixels,
+		IgnoredPixels: f.IgnoredPixels,
+		Regions:       f.Regions,
+		LargestRegion: f.LargestRegion,
+		DPRScale:      f.DPRScale,
+		RenamedFrom:   f.RenamedFrom,
+		Duplicates:    f.Duplicates,
+		Error:         f.Error,
+		Warning:       f.Warning,
+	}, nil
+}
+
+// savedDiffPath returns where SaveResultImages writes a result's diff
+// overlay, which is always a PNG whatever the screenshot's format.
+func savedDiffPath(dir, name string) string {
+	return filepath.Join(dir, savedDiffDir, strings.TrimSuffix(name, filepath.Ext(name))+".png")
+}
+
+// savedImage returns path if it exists, or an error naming the missing image.
+func savedImage(path string) (string, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("image missing from images directory: %s", path)
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// copyImage copies the file at src to dst, creating dst's directory.
+func copyImage(src, dst string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := out.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,81 @@
+package imgdiff
+
+import (
+	"bytes"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResultsFromSummary_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "baseline")
+	currDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{255, 255, 255, 255}
+	red := color.RGBA{255, 0, 0, 255}
+	createTestPNG(t, filepath.Join(baseDir, "same.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(currDir, "same.png"), 10, 10, white)
+	createTestPNGWithBlock(t, filepath.Join(baseDir, "changed.png"), 10, 10, white, red, 0, 0, 2, 2)
+	createTestPNGWithBlock(t, filepath.Join(currDir, "changed.png"), 10, 10, white, red, 5, 5, 3, 3)
+	createTestPNG(t, filepath.Join(currDir, "added.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(baseDir, "removed.png"), 10, 10, red)
+
+	results, err := CompareDirectories(baseDir, currDir, 0.1)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+	imagesDir := filepath.Join(dir, "images")
+	if err := SaveResultImages(results, imagesDir, PNGCompressionFast); err != nil {
+		t.Fatalf("SaveResultImages failed: %v", err)
+	}
+
+	rebuilt, err := ResultsFromSummary(BuildSummary("test", results), imagesDir)
+	if err != nil {
+		t.Fatalf("ResultsFromSummary failed: %v", err)
+	}
+	if len(rebuilt) != len(results) {
+		t.Fatalf("expected %d results, got %d", len(results), len(rebuilt))
+	}
+
+	byName := make(map[string]Result)
+	for _, r := range rebuilt {
+		byName[r.Name] = r
+	}
+	changed := byName["changed.png"]
+	if changed.Status != StatusChanged || changed.DiffImage == nil || changed.BaselinePath == "" || changed.CurrentPath == "" {
+		t.Errorf("changed result not rebuilt with its images: %+v", changed)
+	}
+	if changed.DiffPixels == 0 || changed.Regions == 0 {
+		t.Errorf("changed result lost its stats: %+v", changed)
+	}
+	if added := byName["added.png"]; added.CurrentPath == "" || added.BaselinePath != "" {
+		t.Errorf("added result: unexpected paths %+v", added)
+	}
+	if removed := byName["removed.png"]; removed.BaselinePath == "" || removed.CurrentPath != "" {
+		t.Errorf("removed result: unexpected paths %+v", removed)
+	}
+	if same := byName["same.png"]; same.Status != StatusUnchanged || same.CurrentPath != "" {
+		t.Errorf("unchanged result: unexpected %+v", same)
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, rebuilt, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Changed (1)") {
+		t.Error("expected the rebuilt report to list the changed screenshot")
+	}
+}
+
+func TestResultsFromSummary_MissingImage(t *testing.T) {
+	summary := Summary{
+		Changed: 1,
+		Total:   1,
+		Files:   []FileSummary{{Name: "gone.png", Status: "changed"}},
+	}
+	if _, err := ResultsFromSummary(summary, t.TempDir()); err == nil {
+		t.Error("expected an error for an image missing from the images directory")
+	}
+}
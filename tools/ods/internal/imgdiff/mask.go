@@ -0,0 +1,127 @@
+package imgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+)
+
+// MaskRegion is a rectangular area of a screenshot with special comparison
+// rules. Without a Threshold the region is ignored entirely; with one, pixels
+// inside it are still compared and counted but judged against that threshold
+// instead of the global one (e.g. a chart that may legitimately shift).
+type MaskRegion struct {
+	// Name optionally restricts the region to screenshots whose filename
+	// matches this glob (e.g. "admin-*.png"). Empty applies to all.
+	Name string `json:"name,omitempty"`
+
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Threshold (0.0 to 1.0) overrides the global threshold inside the
+	// region. Nil means the region is ignored.
+	Threshold *float64 `json:"threshold,omitempty"`
+}
+
+// Rect returns the region as an image.Rectangle.
+func (m MaskRegion) Rect() image.Rectangle {
+	return image.Rect(m.X, m.Y, m.X+m.Width, m.Y+m.Height)
+}
+
+// Mask is the on-disk mask file format:
+//
+//	{"regions": [{"x": 0, "y": 0, "width": 1280, "height": 80},
+//	             {"name": "dashboard*.png", "x": 0, "y": 200, "width": 600, "height": 400, "threshold": 0.3}]}
+type Mask struct {
+	Regions []MaskRegion `json:"regions"`
+}
+
+// LoadMask reads and validates a mask file.
+func LoadMask(path string) ([]MaskRegion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mask file: %w", err)
+	}
+
+	var mask Mask
+	if err := json.Unmarshal(data, &mask); err != nil {
+		return nil, fmt.Errorf("failed to parse mask file %s: %w", path, err)
+	}
+
+	for i, r := range mask.Regions {
+		if r.Width <= 0 || r.Height <= 0 {
+			return nil, fmt.Errorf("mask region %d: width and height must be positive", i)
+		}
+		if r.Threshold != nil && (*r.Threshold < 0 || *r.Threshold > 1) {
+			return nil, fmt.Errorf("mask region %d: threshold must be between 0.0 and 1.0", i)
+		}
+		if r.Name != "" {
+			if _, err := filepath.Match(r.Name, ""); err != nil {
+				return nil, fmt.Errorf("mask region %d: invalid name pattern %q: %w", i, r.Name, err)
+			}
+		}
+	}
+
+	return mask.Regions, nil
+}
+
+// regionsFor returns the regions that apply to the named screenshot.
+func regionsFor(regions []MaskRegion, name string) []MaskRegion {
+	var matched []MaskRegion
+	for _, r := range regions {
+		if r.Name == "" {
+			matched = append(matched, r)
+			continue
+		}
+		if ok, _ := filepath.Match(r.Name, name); ok {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// ignoredThreshold marks a pixel inside an ignore region in a threshold map.
+const ignoredThreshold = -1.0
+
+// thresholdMap returns a row-major width×height map of per-pixel thresholds
+// in 8-bit units, or nil if no regions apply. global is the threshold
+// outside every region, also in 8-bit units. offset is the position of the
+// compared area's top-left pixel within the full screenshot, so regions can
+// be expressed in screenshot coordinates even when a crop is applied.
+//
+// Where regions overlap the most permissive rule wins: an ignore region
+// beats any threshold, and otherwise the highest threshold applies.
+func thresholdMap(regions []MaskRegion, width, height int, offset image.Point, global float64) []float64 {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	area := image.Rect(0, 0, width, height)
+	m := make([]float64, width*height)
+	for i := range m {
+		m[i] = global
+	}
+
+	for _, r := range regions {
+		rect := r.Rect().Sub(offset).Intersect(area)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			for x := rect.Min.X; x < rect.Max.X; x++ {
+				idx := y*width + x
+				switch {
+				case m[idx] == ignoredThreshold:
+					// Already ignored; nothing is more permissive
+				case r.Threshold == nil:
+					m[idx] = ignoredThreshold
+				default:
+					m[idx] = max(m[idx], *r.Threshold*255.0)
+				}
+			}
+		}
+	}
+
+	return m
+}
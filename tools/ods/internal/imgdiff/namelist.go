@@ -0,0 +1,38 @@
+package imgdiff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ReadNameList reads newline-separated screenshot paths, e.g. the files a
+// PR touched, for CompareOptions.Only. Paths may be relative to anywhere:
+// screenshots are matched by file name, so only the last element is kept.
+// With nested, the parent directory is kept too, as "project/name", so
+// CompareNestedDirectories can match each entry within its own project.
+// Blank lines and lines starting with '#' are skipped. The result is
+// non-nil even when the list is empty.
+func ReadNameList(r io.Reader, nested bool) (map[string]bool, error) {
+	names := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = path.Clean(filepath.ToSlash(line))
+		name := path.Base(line)
+		if dir := path.Base(path.Dir(line)); nested && dir != "." && dir != "/" && dir != ".." {
+			name = dir + "/" + name
+		}
+		names[name] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read name list: %w", err)
+	}
+	return names, nil
+}
@@ -0,0 +1,70 @@
+package imgdiff
+
+import (
+	"image/color"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadNameList(t *testing.T) {
+	names, err := ReadNameList(strings.NewReader("web/output/screenshots/a.png\n\n  b.png  \n# a comment\nc.png"), false)
+	if err != nil {
+		t.Fatalf("ReadNameList failed: %v", err)
+	}
+	want := map[string]bool{"a.png": true, "b.png": true, "c.png": true}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+
+	nested, err := ReadNameList(strings.NewReader("output/screenshots/admin/page.png\nhome.png"), true)
+	if err != nil {
+		t.Fatalf("ReadNameList failed: %v", err)
+	}
+	want = map[string]bool{"admin/page.png": true, "home.png": true}
+	if !reflect.DeepEqual(nested, want) {
+		t.Errorf("expected %v with nested, got %v", want, nested)
+	}
+
+	empty, err := ReadNameList(strings.NewReader(""), false)
+	if err != nil || empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty non-nil list, got %v (err %v)", empty, err)
+	}
+}
+
+func TestCompareDirectories_Only(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	for _, name := range []string{"listed.png", "unlisted.png", "deleted.png", "old-name.png"} {
+		createTestPNG(t, filepath.Join(baselineDir, name), 10, 10, color.White)
+	}
+	for _, name := range []string{"listed.png", "unlisted.png", "new.png", "new-name.png"} {
+		createTestPNG(t, filepath.Join(currentDir, name), 10, 10, color.White)
+	}
+
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		Only:      map[string]bool{"listed.png": true, "deleted.png": true, "new.png": true, "new-name.png": true},
+		RenameMap: map[string]string{"new-name.png": "old-name.png"},
+	})
+	if err != nil {
+		t.Fatalf("CompareDirectoriesWithOptions failed: %v", err)
+	}
+
+	got := make(map[string]Status)
+	for _, r := range results {
+		got[r.Name] = r.Status
+	}
+	want := map[string]Status{
+		"listed.png":   StatusUnchanged,
+		"deleted.png":  StatusRemoved,
+		"new.png":      StatusAdded,
+		"new-name.png": StatusUnchanged,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
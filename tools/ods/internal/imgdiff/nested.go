@@ -0,0 +1,154 @@
+package imgdiff
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CompareNestedDirectories compares screenshot trees laid out as
+// <dir>/<project>/<screenshot>, such as Playwright's multi-project output,
+// in one run. Each project directory found on either side is compared with
+// CompareDirectoriesWithOptions; a project missing from one side reports
+// all of its screenshots as added or removed. Results carry their Project
+// and are named "<project>/<screenshot>" so names stay unique across
+// projects.
+func CompareNestedDirectories(baselineDir, currentDir string, opts CompareOptions) ([]Result, error) {
+	projects, err := nestedProjects(baselineDir, currentDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Only != nil {
+		warnUnknownProjects(opts.Only, projects)
+	}
+
+	var results []Result
+	for _, project := range projects {
+		projectOpts := opts
+		if opts.Only != nil {
+			projectOpts.Only = projectNames(opts.Only, project)
+		}
+		if opts.OnResult != nil {
+			projectOpts.OnResult = func(r Result) { opts.OnResult(withProject(r, project)) }
+		}
+
+		projectResults, err := CompareDirectoriesWithOptions(
+			filepath.Join(baselineDir, project), filepath.Join(currentDir, project), projectOpts)
+		for _, r := range projectResults {
+			results = append(results, withProject(r, project))
+		}
+		if errors.Is(err, ErrStoppedEarly) {
+			SortResults(results, SortByDiffPercent)
+			return results, err
+		}
+		if err != nil && opts.OnTraverseError == TraverseErrorSkip {
+			r := withProject(errorResult(project, err), project)
+			r.Name = project + "/"
+			results = append(results, r)
+			if opts.OnResult != nil {
+				opts.OnResult(r)
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project, err)
+		}
+	}
+
+	SortResults(results, SortByDiffPercent)
+	return results, nil
+}
+
+// HasNestedScreenshots reports whether any project directory directly
+// inside dir contains screenshots.
+func HasNestedScreenshots(dir string) (bool, error) {
+	projects, err := listProjectDirs(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, project := range projects {
+		has, err := HasScreenshots(filepath.Join(dir, project))
+		if err != nil || has {
+			return has, err
+		}
+	}
+	return false, nil
+}
+
+// projectNames narrows a nested name list to one project: "project/name"
+// entries select name in that project only, and bare names in every
+// project.
+func projectNames(only map[string]bool, project string) map[string]bool {
+	names := make(map[string]bool)
+	for entry := range only {
+		dir, name, ok := strings.Cut(entry, "/")
+		switch {
+		case !ok:
+			names[entry] = true
+		case dir == project:
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// warnUnknownProjects warns about name list entries whose project is in
+// neither directory, since projectNames would silently drop them.
+func warnUnknownProjects(only map[string]bool, projects []string) {
+	for _, entry := range slices.Sorted(maps.Keys(only)) {
+		if dir, _, ok := strings.Cut(entry, "/"); ok && !slices.Contains(projects, dir) {
+			log.Warnf("Listed screenshot %s is in neither the baseline nor the current directory", entry)
+		}
+	}
+}
+
+// withProject tags a result with the project it was compared under.
+func withProject(r Result, project string) Result {
+	r.Project = project
+	r.Name = project + "/" + r.Name
+	return r
+}
+
+// nestedProjects returns the sorted union of the project directories in
+// baselineDir and currentDir.
+func nestedProjects(baselineDir, currentDir string) ([]string, error) {
+	baseline, err := listProjectDirs(baselineDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list baseline directory: %w", err)
+	}
+	current, err := listProjectDirs(currentDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list current directory: %w", err)
+	}
+
+	projects := append(baseline, current...)
+	slices.Sort(projects)
+	return slices.Compact(projects), nil
+}
+
+// listProjectDirs returns the names of the non-hidden directories directly
+// inside dir. A missing dir has none.
+func listProjectDirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, brokenSymlink(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var projects []string
+	for _, e := range entries {
+		if isDirEntry(dir, e) && !strings.HasPrefix(e.Name(), ".") {
+			projects = append(projects, e.Name())
+		}
+	}
+	return projects, nil
+}
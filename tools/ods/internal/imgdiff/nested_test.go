@@ -0,0 +1,124 @@
+package imgdiff
+
+import (
+	"bytes"
+	"image/color"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompareNestedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+	red := color.RGBA{R: 255, A: 255}
+
+	// The same file name in two projects must stay two results
+	createTestPNG(t, filepath.Join(baselineDir, "chromium", "home.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "chromium", "home.png"), 10, 10, red)
+	createTestPNG(t, filepath.Join(baselineDir, "firefox", "home.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "firefox", "home.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(baselineDir, "firefox", "gone.png"), 10, 10, color.White)
+	createTestPNG(t, filepath.Join(currentDir, "webkit", "home.png"), 10, 10, color.White)
+
+	var streamed []string
+	results, err := CompareNestedDirectories(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		OnResult:  func(r Result) { streamed = append(streamed, r.Name) },
+	})
+	if err != nil {
+		t.Fatalf("CompareNestedDirectories failed: %v", err)
+	}
+
+	got := make(map[string]Status)
+	for _, r := range results {
+		if !strings.HasPrefix(r.Name, r.Project+"/") {
+			t.Errorf("expected %s to be prefixed with its project %q", r.Name, r.Project)
+		}
+		got[r.Name] = r.Status
+	}
+	want := map[string]Status{
+		"chromium/home.png": StatusChanged,
+		"firefox/home.png":  StatusUnchanged,
+		"firefox/gone.png":  StatusRemoved,
+		"webkit/home.png":   StatusAdded,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if len(streamed) != len(results) || !strings.Contains(strings.Join(streamed, ","), "webkit/home.png") {
+		t.Errorf("expected OnResult to see project-prefixed names, got %v", streamed)
+	}
+
+	summary := BuildSummary("e2e", results)
+	wantProjects := []ProjectSummary{
+		{Project: "chromium", Changed: 1, Total: 1},
+		{Project: "firefox", Removed: 1, Unchanged: 1, Total: 2},
+		{Project: "webkit", Added: 1, Total: 1},
+	}
+	if !reflect.DeepEqual(summary.Projects, wantProjects) {
+		t.Errorf("expected per-project counts %+v, got %+v", wantProjects, summary.Projects)
+	}
+
+	has, err := HasNestedScreenshots(currentDir)
+	if err != nil || !has {
+		t.Errorf("expected nested screenshots in %s (err %v)", currentDir, err)
+	}
+}
+
+func TestCompareNestedDirectories_Only(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	for _, project := range []string{"admin", "user"} {
+		for _, name := range []string{"page.png", "home.png", "other.png"} {
+			createTestPNG(t, filepath.Join(baselineDir, project, name), 10, 10, color.White)
+			createTestPNG(t, filepath.Join(currentDir, project, name), 10, 10, color.White)
+		}
+	}
+
+	results, err := CompareNestedDirectories(baselineDir, currentDir, CompareOptions{
+		Threshold: 0.2,
+		Only:      map[string]bool{"admin/page.png": true, "home.png": true},
+	})
+	if err != nil {
+		t.Fatalf("CompareNestedDirectories failed: %v", err)
+	}
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name)
+	}
+	slices.Sort(got)
+	want := []string{"admin/home.png", "admin/page.png", "user/home.png"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestWriteReport_ProjectGroups(t *testing.T) {
+	results := []Result{
+		{Name: "webkit/a.png", Project: "webkit", Status: StatusAdded},
+		{Name: "chromium/a.png", Project: "chromium", Status: StatusChanged, DiffPercent: 1},
+		{Name: "firefox/a.png", Project: "firefox", Status: StatusUnchanged},
+	}
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, results, ReportOptions{}); err != nil {
+		t.Fatalf("writeReport failed: %v", err)
+	}
+	html := buf.String()
+
+	chromium := strings.Index(html, `<h2 class="project-title">chromium</h2>`)
+	webkit := strings.Index(html, `<h2 class="project-title">webkit</h2>`)
+	if chromium < 0 || webkit < 0 || chromium > webkit {
+		t.Errorf("expected chromium then webkit project headings (at %d and %d)", chromium, webkit)
+	}
+	if strings.Contains(html, `<h2 class="project-title">firefox</h2>`) {
+		t.Error("expected no heading for a project without differences")
+	}
+}
@@ -0,0 +1,152 @@
+package imgdiff
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// OutputEntries returns the paths, relative to the output directory, that a
+// compare run writes next to the report named reportName: the report
+// itself, summary.json, and the images/ directory of external reports.
+func OutputEntries(reportName string) []string {
+	return []string{reportName, "summary.json", externalImageDir}
+}
+
+// CleanOutputDir removes the given entries of root (paths relative to it,
+// see OutputEntries), so a run starts without an earlier run's results.
+// Nothing else in root is touched, so pointing the report at a shared
+// directory such as /tmp only removes what ods wrote there. It refuses roots
+// that hold more than generated output: the filesystem root, the home or
+// working directory or any of their parents, and git checkouts.
+func CleanOutputDir(root string, entries []string) error {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+	if err := checkCleanable(abs); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path, err := outputEntryPath(abs, entry)
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to clean output directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// PruneOutputDirs removes the directories among root's entries (see
+// CleanOutputDir) that a run left empty, along with their empty
+// subdirectories.
+func PruneOutputDirs(root string, entries []string) error {
+	for _, entry := range entries {
+		path, err := outputEntryPath(root, entry)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Lstat(path); err != nil || !info.IsDir() {
+			continue
+		}
+		if err := PruneEmptyDirs(path); err != nil {
+			return err
+		}
+		if dirEntries, err := os.ReadDir(path); err == nil && len(dirEntries) == 0 {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove empty directory %s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// outputEntryPath joins an output entry onto root, rejecting entries that
+// would resolve to root itself or escape it.
+func outputEntryPath(root, entry string) (string, error) {
+	rel := filepath.Clean(entry)
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to clean %q: not inside the output directory %s", entry, root)
+	}
+	return filepath.Join(root, rel), nil
+}
+
+// checkCleanable returns an error if the absolute directory dir must not be
+// wiped by CleanOutputDir.
+func checkCleanable(dir string) error {
+	if filepath.Dir(dir) == dir {
+		return fmt.Errorf("refusing to clean the filesystem root %s", dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil && withinDir(home, dir) {
+		return fmt.Errorf("refusing to clean %s: it contains the home directory", dir)
+	}
+	if cwd, err := os.Getwd(); err == nil && withinDir(cwd, dir) {
+		return fmt.Errorf("refusing to clean %s: it contains the working directory", dir)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return fmt.Errorf("refusing to clean %s: it is a git checkout", dir)
+	}
+	return nil
+}
+
+// PruneEmptyDirs removes directories under root that are empty, including
+// ones that only become empty once their empty subdirectories are removed.
+// root itself and anything outside it are never removed.
+func PruneEmptyDirs(root string) error {
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Symlinked directories are reported as files, so this never
+		// follows a link out of root
+		if d.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to walk output directory: %w", err)
+	}
+
+	// Children are visited after their parents, so deepest-first removal
+	// empties parents before they are checked
+	slices.Reverse(dirs)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if err := os.Remove(dir); err != nil {
+			return fmt.Errorf("failed to remove empty directory %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// withinDir reports whether path is dir or somewhere beneath it, after
+// resolving symlinks.
+func withinDir(path, dir string) bool {
+	rp, err := resolveDir(path)
+	if err != nil {
+		return false
+	}
+	rd, err := resolveDir(dir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(rd, rp)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,126 @@
+package imgdiff
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanOutputDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "admin")
+	for _, f := range []string{"index.html", "summary.json", "images/a-diff.png", "gifs/nested/a.gif"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := append(OutputEntries("index.html"), "gifs")
+	if err := CleanOutputDir(root, entries); err != nil {
+		t.Fatalf("CleanOutputDir failed: %v", err)
+	}
+	dirEntries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatalf("expected the output root to remain: %v", err)
+	}
+	if len(dirEntries) != 0 {
+		t.Errorf("expected an empty output root, got %d entries", len(dirEntries))
+	}
+
+	if err := CleanOutputDir(filepath.Join(root, "missing"), entries); err != nil {
+		t.Errorf("expected a missing directory to be a no-op, got %v", err)
+	}
+	if err := CleanOutputDir(root, []string{"../other"}); err == nil {
+		t.Error("expected an entry outside the output directory to be refused")
+	}
+}
+
+func TestCleanOutputDir_KeepsUnrelatedFiles(t *testing.T) {
+	// e.g. --output /tmp/report.html must not wipe the rest of /tmp
+	root := t.TempDir()
+	for _, f := range []string{"report.html", "summary.json", "images/a.png", "notes.txt", "project/keep.go"} {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(root, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := OutputEntries("report.html")
+	if err := CleanOutputDir(root, entries); err != nil {
+		t.Fatalf("CleanOutputDir failed: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := PruneOutputDirs(root, entries); err != nil {
+		t.Fatalf("PruneOutputDirs failed: %v", err)
+	}
+
+	for _, f := range []string{"report.html", "summary.json", "images"} {
+		if _, err := os.Stat(filepath.Join(root, f)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", f)
+		}
+	}
+	for _, f := range []string{"notes.txt", "project/keep.go", "empty"} {
+		if _, err := os.Stat(filepath.Join(root, f)); err != nil {
+			t.Errorf("expected unrelated %s to survive: %v", f, err)
+		}
+	}
+}
+
+func TestCleanOutputDir_RefusesUnsafeRoots(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout := t.TempDir()
+	if err := os.Mkdir(filepath.Join(checkout, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{string(filepath.Separator), cwd, filepath.Dir(cwd), checkout} {
+		if err := CleanOutputDir(dir, OutputEntries("index.html")); err == nil {
+			t.Errorf("expected CleanOutputDir(%s) to refuse", dir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(checkout, ".git")); err != nil {
+		t.Errorf("expected the refused directory to be untouched: %v", err)
+	}
+}
+
+func TestPruneEmptyDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"empty", "nested/empty/deeper", "kept/empty"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "kept", "a.png"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := PruneEmptyDirs(root); err != nil {
+		t.Fatalf("PruneEmptyDirs failed: %v", err)
+	}
+
+	for _, d := range []string{"empty", "nested", "kept/empty"} {
+		if _, err := os.Stat(filepath.Join(root, d)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be pruned", d)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "kept", "a.png")); err != nil {
+		t.Errorf("expected kept/a.png to remain: %v", err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Errorf("expected the root to remain: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package imgdiff
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// OverlayBase selects what the non-differing pixels of a diff overlay show.
+type OverlayBase string
+
+const (
+	// OverlayBaseCurrent dims the current image (the default).
+	OverlayBaseCurrent OverlayBase = "current"
+	// OverlayBaseBaseline dims the baseline image.
+	OverlayBaseBaseline OverlayBase = "baseline"
+	// OverlayBaseWhite paints unchanged pixels flat white.
+	OverlayBaseWhite OverlayBase = "white"
+	// OverlayBaseBlack paints unchanged pixels flat black.
+	OverlayBaseBlack OverlayBase = "black"
+)
+
+// ParseOverlayBase validates an overlay base name. An empty string selects
+// OverlayBaseCurrent.
+func ParseOverlayBase(s string) (OverlayBase, error) {
+	switch b := OverlayBase(s); b {
+	case "":
+		return OverlayBaseCurrent, nil
+	case OverlayBaseCurrent, OverlayBaseBaseline, OverlayBaseWhite, OverlayBaseBlack:
+		return b, nil
+	}
+	return "", fmt.Errorf("invalid overlay base %q (valid: %s, %s, %s, %s)", s, OverlayBaseCurrent, OverlayBaseBaseline, OverlayBaseWhite, OverlayBaseBlack)
+}
+
+// Palette selects the color differing pixels are highlighted with in a diff
+// overlay.
+type Palette string
+
+const (
+	// PaletteDefault highlights differences in magenta.
+	PaletteDefault Palette = "default"
+	// PaletteDeuteranopia highlights in orange, which stays distinct from
+	// the dimmed screenshot for red-green (green-weak) color vision.
+	PaletteDeuteranopia Palette = "deuteranopia"
+	// PaletteProtanopia highlights in sky blue, since reds look dark with
+	// red-weak color vision.
+	PaletteProtanopia Palette = "protanopia"
+	// PaletteHighContrast highlights in pure cyan.
+	PaletteHighContrast Palette = "high-contrast"
+)
+
+// ParsePalette validates a palette name. An empty string selects
+// PaletteDefault.
+func ParsePalette(s string) (Palette, error) {
+	switch p := Palette(s); p {
+	case "":
+		return PaletteDefault, nil
+	case PaletteDefault, PaletteDeuteranopia, PaletteProtanopia, PaletteHighContrast:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid palette %q (valid: %s, %s, %s, %s)", s, PaletteDefault, PaletteDeuteranopia, PaletteProtanopia, PaletteHighContrast)
+}
+
+// Highlight returns the overlay color for a differing pixel. The
+// color-vision palettes use the Okabe-Ito colors.
+func (p Palette) Highlight() color.RGBA {
+	switch p {
+	case PaletteDeuteranopia:
+		return color.RGBA{R: 230, G: 159, B: 0, A: 255}
+	case PaletteProtanopia:
+		return color.RGBA{R: 86, G: 180, B: 233, A: 255}
+	case PaletteHighContrast:
+		return color.RGBA{R: 0, G: 255, B: 255, A: 255}
+	default:
+		return color.RGBA{R: 255, G: 0, B: 255, A: 255}
+	}
+}
+
+// background returns the overlay color for an unchanged pixel, given the
+// baseline and current pixel values (8-bit channels as float64).
+func (b OverlayBase) background(baseline, current [4]float64) color.RGBA {
+	switch b {
+	case OverlayBaseWhite:
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	case OverlayBaseBlack:
+		return color.RGBA{A: 255}
+	case OverlayBaseBaseline:
+		return dimmed(baseline)
+	default:
+		return dimmed(current)
+	}
+}
+
+// ignored returns the overlay color for a pixel excluded from the
+// comparison: the unchanged-pixel color washed out towards gray, so ignored
+// areas stand apart from compared ones.
+func (b OverlayBase) ignored(baseline, current [4]float64) color.RGBA {
+	c := b.background(baseline, current)
+	return color.RGBA{
+		R: c.R/2 + 64,
+		G: c.G/2 + 64,
+		B: c.B/2 + 64,
+		A: 255,
+	}
+}
+
+// dimmed returns the pixel at 30% opacity.
+func dimmed(p [4]float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(p[0] * 0.3),
+		G: uint8(p[1] * 0.3),
+		B: uint8(p[2] * 0.3),
+		A: uint8(math.Max(p[3]*0.3, 50)),
+	}
+}
@@ -0,0 +1,91 @@
+package imgdiff
+
+import "sync"
+
+// runOrdered runs work for the indexes 0..n-1 on up to workers goroutines
+// and hands each result to emit in index order, on the calling goroutine.
+// emit (and anything it calls, such as OnResult) therefore never runs
+// concurrently, and its output is the same as a sequential run. It stops at
+// the first error or when emit returns true, and reports which.
+//
+// With one worker, work also runs on the calling goroutine, so a run with
+// Concurrency 1 is exactly the sequential path.
+func runOrdered[T any](n, workers int, work func(i int) (T, error), emit func(T) bool) (stopped bool, err error) {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			result, err := work(i)
+			if err != nil {
+				return false, err
+			}
+			if emit(result) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	type outcome struct {
+		index  int
+		result T
+		err    error
+	}
+
+	indexes := make(chan int)
+	outcomes := make(chan outcome)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	// Stop feeding and release blocked workers on early return
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	go func() {
+		defer close(indexes)
+		for i := 0; i < n; i++ {
+			select {
+			case indexes <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := work(i)
+				select {
+				case outcomes <- outcome{i, result, err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	// Results arrive in completion order; hold them until their turn
+	ready := make(map[int]outcome)
+	for next := 0; next < n; {
+		o := <-outcomes
+		ready[o.index] = o
+		for {
+			o, ok := ready[next]
+			if !ok {
+				break
+			}
+			delete(ready, next)
+			next++
+			if o.err != nil {
+				return false, o.err
+			}
+			if emit(o.result) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,66 @@
+package imgdiff
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCompareDirectories_ConcurrencyMatchesSequential(t *testing.T) {
+	dir := t.TempDir()
+	baselineDir := filepath.Join(dir, "baseline")
+	currentDir := filepath.Join(dir, "current")
+
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("page-%02d.png", i)
+		createTestPNG(t, filepath.Join(baselineDir, name), 20, 20, white)
+		if i%3 == 0 {
+			createTestPNGWithBlock(t, filepath.Join(currentDir, name), 20, 20, white, red, 0, 0, i+1, 5)
+		} else {
+			createTestPNG(t, filepath.Join(currentDir, name), 20, 20, white)
+		}
+	}
+
+	run := func(concurrency int) ([]string, []Result) {
+		var events []string
+		results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+			Threshold:   0.2,
+			Concurrency: concurrency,
+			OnResult:    func(r Result) { events = append(events, r.Name+":"+r.Status.String()) },
+		})
+		if err != nil {
+			t.Fatalf("concurrency %d: CompareDirectoriesWithOptions failed: %v", concurrency, err)
+		}
+		return events, results
+	}
+
+	wantEvents, wantResults := run(1)
+	gotEvents, gotResults := run(4)
+	if !slices.Equal(gotEvents, wantEvents) {
+		t.Errorf("expected OnResult order %v, got %v", wantEvents, gotEvents)
+	}
+	for i := range wantResults {
+		if gotResults[i].Name != wantResults[i].Name || gotResults[i].DiffPixels != wantResults[i].DiffPixels {
+			t.Errorf("result %d: expected %s (%d px), got %s (%d px)", i,
+				wantResults[i].Name, wantResults[i].DiffPixels, gotResults[i].Name, gotResults[i].DiffPixels)
+		}
+	}
+
+	// FailFast still stops at the first changed screenshot in name order
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, CompareOptions{
+		Threshold:   0.2,
+		Concurrency: 4,
+		FailFast:    []Status{StatusChanged},
+	})
+	if !errors.Is(err, ErrStoppedEarly) {
+		t.Fatalf("expected ErrStoppedEarly, got %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "page-00.png" {
+		t.Errorf("expected to stop at page-00.png, got %d result(s)", len(results))
+	}
+}
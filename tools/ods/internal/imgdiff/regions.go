@@ -0,0 +1,50 @@
+package imgdiff
+
+// countRegions returns the number of 8-connected clusters of set pixels in a
+// row-major width×height mask. It is used to tell a handful of localized
+// changes apart from one large shift that touches many pixels.
+func countRegions(mask []bool, width, height int) int {
+	return len(regionSizes(mask, width, height))
+}
+
+// regionSizes returns the pixel count of each 8-connected cluster of set
+// pixels in a row-major width×height mask, in scan order.
+func regionSizes(mask []bool, width, height int) []int {
+	visited := make([]bool, len(mask))
+	var stack []int
+	var sizes []int
+
+	for start, set := range mask {
+		if !set || visited[start] {
+			continue
+		}
+		size := 0
+
+		// Iterative flood fill to avoid deep recursion on large regions
+		visited[start] = true
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			idx := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			x, y := idx%width, idx/width
+
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= width || ny >= height {
+						continue
+					}
+					n := ny*width + nx
+					if mask[n] && !visited[n] {
+						visited[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes
+}
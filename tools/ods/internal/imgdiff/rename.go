@@ -0,0 +1,42 @@
+package imgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadRenameMap reads a JSON object mapping current screenshot filenames to
+// the baseline filenames they replace, e.g.
+//
+//	{"admin-docs-explorer.png": "admin-documents-explorer.png"}
+//
+// Every target must be unique so that a baseline is paired at most once.
+func LoadRenameMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rename map: %w", err)
+	}
+
+	var renames map[string]string
+	if err := json.Unmarshal(data, &renames); err != nil {
+		return nil, fmt.Errorf("failed to parse rename map %s: %w", path, err)
+	}
+
+	targets := make(map[string]string, len(renames))
+	for current, baseline := range renames {
+		if current == "" || baseline == "" {
+			return nil, fmt.Errorf("rename map: names must not be empty")
+		}
+		if filepath.Base(current) != current || filepath.Base(baseline) != baseline {
+			return nil, fmt.Errorf("rename map: %q -> %q must be plain filenames", current, baseline)
+		}
+		if other, ok := targets[baseline]; ok {
+			return nil, fmt.Errorf("rename map: %q and %q both map to %q", other, current, baseline)
+		}
+		targets[baseline] = current
+	}
+
+	return renames, nil
+}
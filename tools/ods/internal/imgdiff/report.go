@@ -6,15 +6,26 @@ import (
 	"fmt"
 	"html/template"
 	"image"
-	"image/png"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 // reportEntry holds data for a single screenshot in the HTML template.
 type reportEntry struct {
 	Name            string
-	Status          string
+	RenamedFrom     string
+	Duplicates      string
+	Context         string
+	ContextURL      string
+	AcceptCommand   string
 	DiffPercent     string
 	BaselineDataURI template.URL
 	CurrentDataURI  template.URL
@@ -22,96 +33,526 @@ type reportEntry struct {
 	HasBaseline     bool
 	HasCurrent      bool
 	HasDiff         bool
+
+	// Error is why an errored screenshot could not be compared.
+	Error string
+
+	// Warning flags a likely misleading comparison, such as a blank capture.
+	Warning string
+
+	// Changed cards only: the numbers behind DiffPercent, shown as a stats
+	// line under the name.
+	DiffPixels    int
+	TotalPixels   int
+	Regions       int
+	LargestRegion int
+
+	// DPRScale, if set, notes that the current image is an integer multiple
+	// of the baseline's size (e.g. "2x"), i.e. a likely capture config change.
+	DPRScale string
+
+	// Collapsible cards toggle their images when the header is clicked;
+	// Collapsed ones start out hidden (see ReportOptions.CollapseBelow).
+	Collapsible bool
+	Collapsed   bool
+
+	// Three-way comparisons only: the reference image and the diff of the
+	// current image against it.
+	HasReference         bool
+	ReferenceStatus      string
+	ReferenceDiffPercent string
+	ReferenceDataURI     template.URL
+	ReferenceDiffDataURI template.URL
+	HasReferenceDiff     bool
 }
 
 // reportData holds all data for the HTML template.
 type reportData struct {
-	Entries        []reportEntry
+	// Groups holds the cards, one group per project for nested comparisons
+	// (sorted by project) and a single unnamed group otherwise.
+	Groups []reportGroup
+
 	ChangedCount   int
 	AddedCount     int
 	RemovedCount   int
+	ErroredCount   int
 	UnchangedCount int
 	TotalCount     int
 	HasDifferences bool
+	ShowThumbnails bool
+	Note           string
+	Title          string
+	Favicon        template.URL
+	Unchanged      []unchangedEntry
+
+	UnchangedPageSize int
+
+	// Palette and PaletteColor describe the diff highlight color for the
+	// legend.
+	Palette      Palette
+	PaletteColor template.CSS
+}
+
+// reportGroup is the cards of one project. Errored, Changed, Added, and
+// Removed are the cards of each report section, in result order; the
+// sections always appear in this order.
+type reportGroup struct {
+	Project string
+	Errored []reportEntry
+	Changed []reportEntry
+	Added   []reportEntry
+	Removed []reportEntry
+}
+
+// unchangedEntry is one unchanged screenshot. Unchanged screenshots are
+// rendered client-side, a page at a time, so large suites don't produce a
+// huge DOM.
+type unchangedEntry struct {
+	Name  string `json:"name"`
+	Thumb string `json:"thumb,omitempty"`
+}
+
+// unchangedPageSize is how many unchanged screenshots the report shows per page.
+const unchangedPageSize = 50
+
+// thumbnailWidth is the maximum width of unchanged-screenshot thumbnails.
+const thumbnailWidth = 240
+
+// DefaultReportTitle is the report's <title> and heading when none is set.
+const DefaultReportTitle = "Visual Regression Report"
+
+// ReportOptions controls optional features of the generated HTML report.
+type ReportOptions struct {
+	// UnchangedThumbnails renders unchanged screenshots as a grid of
+	// downscaled thumbnails instead of a names-only list. This increases
+	// report size, so it is off by default.
+	UnchangedThumbnails bool
+
+	// Crop, if non-empty, shows only this region of each screenshot so the
+	// report matches what was compared (see CompareOptions.Crop).
+	Crop image.Rectangle
+
+	// MaxWidth and MaxHeight, if positive, downscale the embedded baseline,
+	// current, and diff images to fit within these dimensions (preserving
+	// aspect ratio) to keep the report small. Zero embeds full-size images.
+	MaxWidth  int
+	MaxHeight int
+
+	// Note, if set, is shown under the report title (e.g. which baseline
+	// revision was actually used).
+	Note string
+
+	// PNGCompression sets the compression level for re-encoded images
+	// (diff overlays, thumbnails, and cropped or downscaled screenshots).
+	PNGCompression PNGCompression
+
+	// ImageFormat selects the encoding of embedded images. WebP (lossless)
+	// makes reports much smaller but needs a modern browser; PNG is the default.
+	ImageFormat ImageFormat
+
+	// Title replaces DefaultReportTitle in the page title and heading, so
+	// reports for different apps are distinguishable in browser tabs.
+	Title string
+
+	// StatusFavicon embeds a green (no differences) or red (differences)
+	// favicon, so pass/fail is visible from the browser tab.
+	StatusFavicon bool
+
+	// AcceptCommand, if set, returns the shell command that accepts the named
+	// screenshot into the baseline. Changed and added cards then get a button
+	// that copies it to the clipboard.
+	AcceptCommand func(name string) string
+
+	// ImageURL, if set, returns the URL of one of a screenshot's images.
+	// Images are then referenced by URL and fetched lazily instead of being
+	// inlined as data URIs (see NewReportHandler).
+	ImageURL func(name string, kind ImageKind) string
+
+	// SVGDPI is the resolution SVG screenshots are rasterized at for
+	// display; it should match CompareOptions.SVGDPI so the images line up
+	// with the diff. Zero means DefaultSVGDPI.
+	SVGDPI float64
+
+	// Timings, if set, accumulates the time spent encoding report images.
+	Timings *Timings
+
+	// CollapseBelow, if positive, renders changed cards whose DiffPercent is
+	// below it collapsed to their header, expanding on click, so the larger
+	// changes stand out. Zero expands every card.
+	CollapseBelow float64
+
+	// Mode selects whether GenerateReport inlines images or writes them to
+	// an images/ directory next to the report. Empty means ReportModeInline.
+	Mode ReportMode
+
+	// Palette is the palette the diff overlays were drawn with (see
+	// CompareOptions.Palette), shown in a legend. Empty means PaletteDefault.
+	Palette Palette
+
+	// OmitBaseline leaves the baseline images of changed screenshots out of
+	// the report, showing only current and the diff overlay, to keep the
+	// report small. Removed screenshots keep theirs, as it is all they have.
+	OmitBaseline bool
+
+	// Concurrency is the number of screenshots whose images are encoded at
+	// once. Zero or one encodes sequentially; the report is identical either
+	// way.
+	Concurrency int
+}
+
+// ImageKind identifies one of the images shown for a screenshot.
+type ImageKind string
+
+const (
+	// ImageBaseline is the baseline screenshot.
+	ImageBaseline ImageKind = "baseline"
+	// ImageCurrent is the current screenshot.
+	ImageCurrent ImageKind = "current"
+	// ImageDiff is the diff overlay of current against baseline.
+	ImageDiff ImageKind = "diff"
+	// ImageReference is the reference screenshot of a three-way comparison.
+	ImageReference ImageKind = "reference"
+	// ImageReferenceDiff is the diff overlay of current against the reference.
+	ImageReferenceDiff ImageKind = "reference-diff"
+	// ImageThumbnail is the downscaled thumbnail of an unchanged screenshot.
+	ImageThumbnail ImageKind = "thumbnail"
+)
+
+// reencodes reports whether embedded screenshots must be decoded and
+// re-encoded rather than inlined byte-for-byte from the PNG file.
+func (o ReportOptions) reencodes() bool {
+	return !o.Crop.Empty() || o.MaxWidth > 0 || o.MaxHeight > 0 || o.ImageFormat == ImageFormatWebP
+}
+
+// embedsAsIs reports whether the screenshot at path can be inlined
+// byte-for-byte. SVGs never are: they are shown rasterized, exactly as
+// they were compared.
+func (o ReportOptions) embedsAsIs(path string) bool {
+	if o.reencodes() {
+		return false
+	}
+	svg, err := sniffSVG(path)
+	return err == nil && !svg
 }
 
-// GenerateReport produces a self-contained HTML file from comparison results.
-// All images are base64-encoded inline as data URIs.
-func GenerateReport(results []Result, outputPath string) error {
+// GenerateReport produces an HTML file from comparison results. By default
+// it is self-contained, with all images base64-encoded inline as data URIs;
+// with ReportModeExternal, images are written to an images/ directory next
+// to it and linked with relative paths.
+func GenerateReport(results []Result, outputPath string, opts ReportOptions) error {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	data := reportData{}
-
-	for _, r := range results {
-		entry := reportEntry{
-			Name:   r.Name,
-			Status: r.Status.String(),
+	var external *externalImages
+	if opts.Mode == ReportModeExternal {
+		dir := filepath.Join(filepath.Dir(outputPath), externalImageDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create image directory: %w", err)
 		}
+		external = newExternalImages(dir, results, opts)
+		opts.ImageURL = external.url
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := writeReport(f, results, opts); err != nil {
+		return err
+	}
+	if external != nil && external.err != nil {
+		return external.err
+	}
+	return nil
+}
+
+// writeReport renders the HTML report for results to w.
+func writeReport(w io.Writer, results []Result, opts ReportOptions) error {
+	data := reportData{
+		ShowThumbnails:    opts.UnchangedThumbnails,
+		Note:              opts.Note,
+		Title:             opts.Title,
+		UnchangedPageSize: unchangedPageSize,
+		Palette:           opts.Palette,
+	}
+	if data.Palette == "" {
+		data.Palette = PaletteDefault
+	}
+	hl := data.Palette.Highlight()
+	data.PaletteColor = template.CSS(fmt.Sprintf("#%02x%02x%02x", hl.R, hl.G, hl.B))
+	if data.Title == "" {
+		data.Title = DefaultReportTitle
+	}
 
+	groups := make(map[string]*reportGroup)
+	// Encoding images dominates report generation, so cards are built on a
+	// worker pool and collected in result order. External images are named
+	// in the order they are requested, so they are written sequentially.
+	workers := opts.Concurrency
+	if opts.ImageURL != nil {
+		workers = 1
+	}
+	build := func(i int) (builtEntry, error) {
+		r := &results[i]
+		if r.Status == StatusUnchanged {
+			unchanged, err := newUnchangedEntry(*r, opts)
+			return builtEntry{result: r, unchanged: unchanged}, err
+		}
+		entry, err := newReportEntry(*r, opts)
+		return builtEntry{result: r, entry: entry}, err
+	}
+	collect := func(b builtEntry) bool {
+		r := b.result
 		switch r.Status {
 		case StatusChanged:
 			data.ChangedCount++
-			entry.DiffPercent = fmt.Sprintf("%.2f%%", r.DiffPercent)
 		case StatusAdded:
 			data.AddedCount++
 		case StatusRemoved:
 			data.RemovedCount++
+		case StatusError:
+			data.ErroredCount++
 		case StatusUnchanged:
 			data.UnchangedCount++
-			entry.DiffPercent = "0.00%"
+			data.Unchanged = append(data.Unchanged, b.unchanged)
+			return false
+		}
+
+		g, ok := groups[r.Project]
+		if !ok {
+			g = &reportGroup{Project: r.Project}
+			groups[r.Project] = g
+		}
+		switch r.Status {
+		case StatusChanged:
+			g.Changed = append(g.Changed, b.entry)
+		case StatusAdded:
+			g.Added = append(g.Added, b.entry)
+		case StatusRemoved:
+			g.Removed = append(g.Removed, b.entry)
+		case StatusError:
+			g.Errored = append(g.Errored, b.entry)
+		}
+		return false
+	}
+	if _, err := runOrdered(len(results), workers, build, collect); err != nil {
+		return err
+	}
+	for _, project := range slices.Sorted(maps.Keys(groups)) {
+		data.Groups = append(data.Groups, *groups[project])
+	}
+
+	sort.Slice(data.Unchanged, func(i, j int) bool {
+		return data.Unchanged[i].Name < data.Unchanged[j].Name
+	})
+
+	data.TotalCount = data.ChangedCount + data.AddedCount + data.RemovedCount + data.ErroredCount + data.UnchangedCount
+	data.HasDifferences = data.ChangedCount > 0 || data.AddedCount > 0 || data.RemovedCount > 0 || data.ErroredCount > 0
+	if opts.StatusFavicon {
+		data.Favicon = statusFavicon(!data.HasDifferences)
+	}
+
+	tmpl, err := template.New("report").Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return nil
+}
+
+// builtEntry is one result's card (or, for an unchanged result, its
+// unchanged-list entry) with its images encoded.
+type builtEntry struct {
+	result    *Result
+	entry     reportEntry
+	unchanged unchangedEntry
+}
+
+// newReportEntry builds the card for a changed, added, removed, or errored
+// result, encoding its images. It is safe to call concurrently as long as
+// opts.ImageURL is.
+func newReportEntry(r Result, opts ReportOptions) (reportEntry, error) {
+	entry := reportEntry{
+		Name:        r.Name,
+		RenamedFrom: r.RenamedFrom,
+		Duplicates:  strings.Join(r.Duplicates, ", "),
+		Context:     r.Context.Label(),
+		ContextURL:  r.Context.URL,
+		Warning:     r.Warning,
+	}
+
+	if opts.AcceptCommand != nil && (r.Status == StatusChanged || r.Status == StatusAdded) {
+		entry.AcceptCommand = opts.AcceptCommand(r.Name)
+	}
+
+	switch r.Status {
+	case StatusChanged:
+		entry.DiffPercent = fmt.Sprintf("%.2f%%", r.DiffPercent)
+		entry.DiffPixels = r.DiffPixels
+		entry.TotalPixels = r.TotalPixels
+		entry.Regions = r.Regions
+		entry.LargestRegion = r.LargestRegion
+		if r.DPRScale != 0 {
+			entry.DPRScale = fmt.Sprintf("%gx", r.DPRScale)
+		}
+		entry.Collapsible = opts.CollapseBelow > 0
+		entry.Collapsed = r.DiffPercent < opts.CollapseBelow
+	case StatusError:
+		entry.Error = r.Error
+		return entry, nil
+	}
+
+	if r.BaselinePath != "" && !(opts.OmitBaseline && r.Status == StatusChanged) {
+		uri, err := imageSrc(r.Name, ImageBaseline, opts, func() (string, error) {
+			return screenshotDataURI(r.BaselinePath, opts)
+		})
+		if err != nil {
+			return entry, fmt.Errorf("failed to encode baseline %s: %w", r.Name, err)
 		}
+		entry.BaselineDataURI = uri
+		entry.HasBaseline = true
+	}
 
-		if r.BaselinePath != "" {
-			uri, err := pngFileToDataURI(r.BaselinePath)
-			if err != nil {
-				return fmt.Errorf("failed to encode baseline %s: %w", r.Name, err)
-			}
-			entry.BaselineDataURI = template.URL(uri)
-			entry.HasBaseline = true
+	if r.CurrentPath != "" {
+		uri, err := imageSrc(r.Name, ImageCurrent, opts, func() (string, error) {
+			return screenshotDataURI(r.CurrentPath, opts)
+		})
+		if err != nil {
+			return entry, fmt.Errorf("failed to encode current %s: %w", r.Name, err)
 		}
+		entry.CurrentDataURI = uri
+		entry.HasCurrent = true
+	}
 
-		if r.CurrentPath != "" {
-			uri, err := pngFileToDataURI(r.CurrentPath)
-			if err != nil {
-				return fmt.Errorf("failed to encode current %s: %w", r.Name, err)
-			}
-			entry.CurrentDataURI = template.URL(uri)
-			entry.HasCurrent = true
+	if r.DiffImage != nil {
+		uri, err := imageSrc(r.Name, ImageDiff, opts, func() (string, error) {
+			return imageToDataURI(downscale(r.DiffImage, opts.MaxWidth, opts.MaxHeight), opts)
+		})
+		if err != nil {
+			return entry, fmt.Errorf("failed to encode diff %s: %w", r.Name, err)
 		}
+		entry.DiffDataURI = uri
+		entry.HasDiff = true
+	}
 
-		if r.DiffImage != nil {
-			uri, err := imageToDataURI(r.DiffImage)
-			if err != nil {
-				return fmt.Errorf("failed to encode diff %s: %w", r.Name, err)
-			}
-			entry.DiffDataURI = template.URL(uri)
-			entry.HasDiff = true
+	if r.Reference != nil {
+		if err := addReference(&entry, r.Reference, opts); err != nil {
+			return entry, err
 		}
+	}
+	return entry, nil
+}
 
-		data.Entries = append(data.Entries, entry)
+// addReference fills in the three-way fields of entry from the comparison
+// of the current image against the reference.
+func addReference(entry *reportEntry, ref *Result, opts ReportOptions) error {
+	entry.HasReference = true
+	entry.ReferenceStatus = ref.Status.String()
+	if ref.Status == StatusChanged {
+		entry.ReferenceDiffPercent = fmt.Sprintf("%.2f%%", ref.DiffPercent)
 	}
 
-	data.TotalCount = len(results)
-	data.HasDifferences = data.ChangedCount > 0 || data.AddedCount > 0 || data.RemovedCount > 0
+	if ref.BaselinePath != "" {
+		uri, err := imageSrc(entry.Name, ImageReference, opts, func() (string, error) {
+			return screenshotDataURI(ref.BaselinePath, opts)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode reference %s: %w", entry.Name, err)
+		}
+		entry.ReferenceDataURI = uri
+	}
 
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+	if ref.DiffImage != nil && ref.Status == StatusChanged {
+		uri, err := imageSrc(entry.Name, ImageReferenceDiff, opts, func() (string, error) {
+			return imageToDataURI(downscale(ref.DiffImage, opts.MaxWidth, opts.MaxHeight), opts)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode reference diff %s: %w", entry.Name, err)
+		}
+		entry.ReferenceDiffDataURI = uri
+		entry.HasReferenceDiff = true
+	}
+
+	return nil
+}
+
+// newUnchangedEntry builds the report entry for an unchanged screenshot,
+// including a downscaled thumbnail when thumbnails are enabled.
+func newUnchangedEntry(r Result, opts ReportOptions) (unchangedEntry, error) {
+	entry := unchangedEntry{Name: r.Name}
+	if !opts.UnchangedThumbnails || r.CurrentPath == "" {
+		return entry, nil
+	}
+
+	uri, err := imageSrc(r.Name, ImageThumbnail, opts, func() (string, error) {
+		img, err := thumbnail(r.CurrentPath, opts)
+		if err != nil {
+			return "", err
+		}
+		return imageToDataURI(img, opts)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return entry, fmt.Errorf("failed to encode thumbnail %s: %w", r.Name, err)
 	}
+	entry.Thumb = string(uri)
+	return entry, nil
+}
 
-	f, err := os.Create(outputPath)
+// thumbnail decodes a screenshot and returns its cropped, downscaled
+// thumbnail.
+func thumbnail(path string, opts ReportOptions) (image.Image, error) {
+	img, err := decodeScreenshot(path, opts.SVGDPI)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return nil, err
 	}
-	defer func() { _ = f.Close() }()
+	if !opts.Crop.Empty() {
+		img = CropImage(img, opts.Crop)
+	}
+	return downscale(img, thumbnailWidth, 0), nil
+}
 
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
+// imageSrc returns the src of one of a screenshot's images: its URL when
+// opts.ImageURL is set, otherwise the data URI built by encode.
+func imageSrc(name string, kind ImageKind, opts ReportOptions, encode func() (string, error)) (template.URL, error) {
+	if opts.ImageURL != nil {
+		return template.URL(opts.ImageURL(name, kind)), nil
 	}
+	uri, err := encode()
+	return template.URL(uri), err
+}
 
-	return nil
+// screenshotDataURI returns a data URI for a screenshot file, cropped,
+// downscaled, and transcoded according to opts. PNG files that need none of
+// these are embedded as-is.
+func screenshotDataURI(path string, opts ReportOptions) (string, error) {
+	if opts.embedsAsIs(path) {
+		return pngFileToDataURI(path)
+	}
+	img, err := reportScreenshot(path, opts)
+	if err != nil {
+		return "", err
+	}
+	return imageToDataURI(img, opts)
+}
+
+// reportScreenshot decodes a screenshot file and crops and downscales it
+// according to opts.
+func reportScreenshot(path string, opts ReportOptions) (image.Image, error) {
+	img, err := decodeScreenshot(path, opts.SVGDPI)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.Crop.Empty() {
+		img = CropImage(img, opts.Crop)
+	}
+	return downscale(img, opts.MaxWidth, opts.MaxHeight), nil
 }
 
 // pngFileToDataURI reads a PNG file and returns a base64 data URI.
@@ -124,14 +565,47 @@ func pngFileToDataURI(path string) (string, error) {
 	return "data:image/png;base64," + encoded, nil
 }
 
-// imageToDataURI encodes an image.Image to a PNG base64 data URI.
-func imageToDataURI(img image.Image) (string, error) {
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
+// imageToDataURI encodes an image.Image to a base64 data URI in the
+// report's image format.
+func imageToDataURI(img image.Image, opts ReportOptions) (string, error) {
+	data, mimeType, err := encodeReportImage(img, opts)
+	if err != nil {
 		return "", err
 	}
-	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
-	return "data:image/png;base64," + encoded, nil
+	return "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// encodeReportImage encodes an image in the report's image format and
+// returns the bytes and their MIME type.
+func encodeReportImage(img image.Image, opts ReportOptions) ([]byte, string, error) {
+	defer func(start time.Time) { opts.Timings.addEncode(time.Since(start)) }(time.Now())
+
+	var buf bytes.Buffer
+	if opts.ImageFormat == ImageFormatWebP {
+		err := encodeWebP(&buf, img)
+		if err == nil {
+			return buf.Bytes(), "image/webp", nil
+		}
+		// Fall back to PNG for this image rather than failing the whole report
+		log.Debugf("Embedding image as PNG: %v", err)
+		buf.Reset()
+	}
+
+	if err := encodePNG(&buf, img, opts.PNGCompression); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// statusFavicon returns an inline SVG favicon: a green dot when pass is true,
+// a red one otherwise.
+func statusFavicon(pass bool) template.URL {
+	fill := "#c62828"
+	if pass {
+		fill = "#2e7d32"
+	}
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"><circle cx="8" cy="8" r="7" fill="` + fill + `"/></svg>`
+	return template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg)))
 }
 
 const htmlTemplate = `<!DOCTYPE html>
@@ -139,11 +613,12 @@ const htmlTemplate = `<!DOCTYPE html>
 <head>
 <meta charset="UTF-8">
 <meta name="viewport" content="width=device-width, initial-scale=1.0">
-<title>Visual Regression Report</title>
+<title>{{.Title}}</title>
+{{if .Favicon}}<link rel="icon" type="image/svg+xml" href="{{.Favicon}}">{{end}}
 <style>
   * { box-sizing: border-box; margin: 0; padding: 0; }
   body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f5f5f5; color: #333; }
-  .header { background: #1a1a2e; color: #fff; padding: 24px 32px; }
+  .header { display: flex; justify-content: space-between; align-items: flex-start; gap: 16px; background: #1a1a2e; color: #fff; padding: 24px 32px; }
   .header h1 { font-size: 24px; font-weight: 600; }
   .header p { margin-top: 8px; opacity: 0.8; font-size: 14px; }
   .summary { display: flex; gap: 16px; padding: 20px 32px; background: #fff; border-bottom: 1px solid #e0e0e0; flex-wrap: wrap; }
@@ -151,18 +626,36 @@ const htmlTemplate = `<!DOCTYPE html>
   .summary-changed { background: #fff3e0; color: #e65100; }
   .summary-added { background: #e8f5e9; color: #2e7d32; }
   .summary-removed { background: #fce4ec; color: #c62828; }
+  .summary-errored { background: #ede7f6; color: #4527a0; }
   .summary-unchanged { background: #e3f2fd; color: #1565c0; }
+  .summary-legend { display: flex; align-items: center; gap: 8px; margin-left: auto; font-size: 13px; color: #6b7280; }
+  .legend-swatch { width: 14px; height: 14px; border-radius: 3px; border: 1px solid rgba(0,0,0,0.2); }
   .content { padding: 24px 32px; max-width: 1400px; margin: 0 auto; }
+  .project-title { font-size: 22px; font-weight: 700; margin: 32px 0 8px; }
   .section-title { font-size: 18px; font-weight: 600; margin: 24px 0 16px; padding-bottom: 8px; border-bottom: 2px solid #e0e0e0; }
   .no-changes { text-align: center; padding: 60px 20px; color: #666; }
   .no-changes h2 { font-size: 24px; margin-bottom: 8px; color: #2e7d32; }
   .card { background: #fff; border-radius: 12px; margin-bottom: 24px; box-shadow: 0 1px 3px rgba(0,0,0,0.1); overflow: hidden; }
   .card-header { display: flex; justify-content: space-between; align-items: center; padding: 16px 20px; border-bottom: 1px solid #eee; }
   .card-name { font-weight: 600; font-size: 15px; }
+  .card-collapsible .card-header { cursor: pointer; }
+  .card-collapsible .card-name::before { content: "\25BE  "; color: #6b7280; }
+  .card-collapsible.collapsed .card-name::before { content: "\25B8  "; }
+  .card.collapsed .card-header { border-bottom: none; }
+  .card.collapsed .tabs, .card.collapsed .tab-content { display: none; }
+  .renamed-from { font-weight: 400; color: #6b7280; }
+  .card-stats { display: block; margin-top: 2px; font-weight: 400; font-size: 12px; color: #6b7280; font-variant-numeric: tabular-nums; }
+  .card-context { display: block; margin-top: 2px; font-weight: 400; font-size: 12px; color: #6b7280; }
+  .card-actions { display: flex; align-items: center; gap: 8px; }
+  .copy-cmd { font-size: 12px; padding: 4px 10px; border: 1px solid #ccc; border-radius: 12px; background: #fff; cursor: pointer; }
+  .copy-cmd:hover { background: #f0f0f0; }
   .card-badge { font-size: 12px; padding: 4px 10px; border-radius: 12px; font-weight: 500; }
   .badge-changed { background: #fff3e0; color: #e65100; }
   .badge-added { background: #e8f5e9; color: #2e7d32; }
   .badge-removed { background: #fce4ec; color: #c62828; }
+  .badge-errored { background: #ede7f6; color: #4527a0; }
+  .card-warning { padding: 8px 20px; background: #fff8e1; border-bottom: 1px solid #ffe082; color: #8d6e00; font-size: 13px; }
+  .card-error { padding: 16px 20px; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 13px; color: #4527a0; white-space: pre-wrap; word-break: break-word; }
   .tabs { display: flex; gap: 0; border-bottom: 1px solid #eee; }
   .tab { padding: 10px 20px; cursor: pointer; font-size: 13px; font-weight: 500; color: #666; border-bottom: 2px solid transparent; transition: all 0.2s; }
   .tab:hover { color: #333; background: #f9f9f9; }
@@ -183,6 +676,8 @@ const htmlTemplate = `<!DOCTYPE html>
   .side-by-side .img-container { border: 1px solid #eee; border-radius: 4px; overflow: hidden; }
   .side-by-side .img-label { font-size: 12px; font-weight: 500; padding: 8px 12px; background: #f5f5f5; color: #666; }
   .side-by-side img { display: block; width: 100%; height: auto; }
+  .side-by-side.three-way { grid-template-columns: 1fr 1fr 1fr; }
+  .badge-reference { background: #e3f2fd; color: #1565c0; }
   .diff-overlay img { display: block; max-width: 100%; height: auto; border: 1px solid #eee; border-radius: 4px; }
   .single-image img { display: block; max-width: 100%; height: auto; border: 1px solid #eee; border-radius: 4px; }
   .unchanged-section { margin-top: 32px; }
@@ -191,20 +686,48 @@ const htmlTemplate = `<!DOCTYPE html>
   .unchanged-list { display: none; }
   .unchanged-list.open { display: block; }
   .unchanged-item { padding: 8px 0; font-size: 13px; color: #888; border-bottom: 1px solid #f0f0f0; }
+  .thumb-grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(200px, 1fr)); gap: 16px; padding-top: 8px; }
+  .thumb { background: #fff; border-radius: 8px; box-shadow: 0 1px 3px rgba(0,0,0,0.1); overflow: hidden; }
+  .thumb img { display: block; width: 100%; height: auto; border-bottom: 1px solid #eee; }
+  .thumb-name { padding: 8px 10px; font-size: 12px; color: #666; word-break: break-all; }
+  .unchanged-pager { display: flex; align-items: center; gap: 12px; padding: 12px 0; font-size: 13px; color: #666; }
+  .unchanged-pager button { padding: 4px 10px; font-size: 13px; border: 1px solid #ddd; border-radius: 4px; background: #fff; cursor: pointer; }
+  .unchanged-pager button:disabled { opacity: 0.4; cursor: default; }
+  .print-button { flex-shrink: 0; padding: 8px 14px; font-size: 13px; border: 1px solid rgba(255,255,255,0.4); border-radius: 6px; background: transparent; color: #fff; cursor: pointer; }
+  .print-button:hover { background: rgba(255,255,255,0.1); }
+  .print-label { display: none; }
+  /* Printing (e.g. Save as PDF): every view of a card at once, one card per page, no controls */
+  @media print {
+    * { -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+    body { background: #fff; }
+    .print-button, .copy-cmd, .tabs, .unchanged-section, .tab-content[data-tab="slider"] { display: none !important; }
+    .tab-content { display: block !important; padding: 12px 20px; }
+    .card { box-shadow: none; border: 1px solid #ddd; break-inside: avoid-page; break-after: page; }
+    .card-collapsible .card-name::before { content: none; }
+    .section-title, .project-title { break-after: avoid-page; }
+    .print-label { display: block; font-size: 12px; font-weight: 500; color: #666; margin-bottom: 6px; }
+    .content { max-width: none; padding: 16px 0; }
+  }
 </style>
 </head>
 <body>
 
 <div class="header">
-  <h1>Visual Regression Report</h1>
-  <p>{{.TotalCount}} screenshot{{if ne .TotalCount 1}}s{{end}} compared</p>
+  <div>
+    <h1>{{.Title}}</h1>
+    <p>{{.TotalCount}} screenshot{{if ne .TotalCount 1}}s{{end}} compared</p>
+    {{if .Note}}<p>{{.Note}}</p>{{end}}
+  </div>
+  <button class="print-button" onclick="printReport()" title="Print every change with its side-by-side and diff views, one per page">Print / Save PDF</button>
 </div>
 
 <div class="summary">
   {{if gt .ChangedCount 0}}<div class="summary-card summary-changed">{{.ChangedCount}} Changed</div>{{end}}
   {{if gt .AddedCount 0}}<div class="summary-card summary-added">{{.AddedCount}} Added</div>{{end}}
   {{if gt .RemovedCount 0}}<div class="summary-card summary-removed">{{.RemovedCount}} Removed</div>{{end}}
+  {{if gt .ErroredCount 0}}<div class="summary-card summary-errored">{{.ErroredCount}} Errored</div>{{end}}
   <div class="summary-card summary-unchanged">{{.UnchangedCount}} Unchanged</div>
+  {{if gt .ChangedCount 0}}<div class="summary-legend"><span class="legend-swatch" style="background: {{.PaletteColor}}"></span>Differing pixels ({{.Palette}} palette)</div>{{end}}
 </div>
 
 <div class="content">
@@ -215,23 +738,47 @@ const htmlTemplate = `<!DOCTYPE html>
   </div>
 {{end}}
 
-{{range .Entries}}
-{{if eq .Status "changed"}}
+{{range .Groups}}
+{{if .Project}}<h2 class="project-title">{{.Project}}</h2>{{end}}
+{{if .Errored}}
+<h2 class="section-title">Errored ({{len .Errored}})</h2>
+{{range .Errored}}
 <div class="card">
   <div class="card-header">
     <span class="card-name">{{.Name}}</span>
-    <span class="card-badge badge-changed">{{.DiffPercent}} changed</span>
+    <span class="card-badge badge-errored">error</span>
+  </div>
+  <div class="card-error">{{.Error}}</div>
+</div>
+{{end}}
+{{end}}
+{{if .Changed}}
+<h2 class="section-title">Changed ({{len .Changed}})</h2>
+{{range .Changed}}
+<div class="card{{if .Collapsible}} card-collapsible{{end}}{{if .Collapsed}} collapsed{{end}}">
+  <div class="card-header"{{if .Collapsible}} onclick="toggleCard(event, this)" title="Click to expand or collapse"{{end}}>
+    <span class="card-name">{{.Name}}{{if .RenamedFrom}} <span class="renamed-from">(was {{.RenamedFrom}})</span>{{end}}{{if .Duplicates}} <span class="renamed-from">(also captured as {{.Duplicates}})</span>{{end}}{{template "stats" .}}{{template "context" .}}</span>
+    <span class="card-actions">
+      {{if .AcceptCommand}}<button class="copy-cmd" data-cmd="{{.AcceptCommand}}" title="{{.AcceptCommand}}" onclick="copyCommand(this)">Copy accept command</button>{{end}}
+      {{if .HasReference}}<span class="card-badge badge-reference">vs reference: {{if .ReferenceDiffPercent}}{{.ReferenceDiffPercent}} changed{{else}}{{.ReferenceStatus}}{{end}}</span>{{end}}
+      <span class="card-badge badge-changed">{{.DiffPercent}} changed</span>
+    </span>
   </div>
+  {{if .Warning}}<div class="card-warning">&#9888; {{.Warning}}</div>{{end}}
   <div class="tabs">
-    <div class="tab active" onclick="switchTab(this, 'slider')">Slider</div>
+    {{if .HasBaseline}}<div class="tab active" onclick="switchTab(this, 'slider')">Slider</div>
     <div class="tab" onclick="switchTab(this, 'sidebyside')">Side by Side</div>
+    {{else}}<div class="tab active" onclick="switchTab(this, 'current')">Current</div>{{end}}
     <div class="tab" onclick="switchTab(this, 'diff')">Diff Overlay</div>
+    {{if .HasReference}}<div class="tab" onclick="switchTab(this, 'threeway')">Three-way</div>
+    <div class="tab" onclick="switchTab(this, 'refdiff')">Diff vs Reference</div>{{end}}
   </div>
+  {{if .HasBaseline}}
   <div class="tab-content active" data-tab="slider">
     <div class="slider-container" onmousedown="startSlider(event, this)" onmousemove="moveSlider(event, this)" ontouchstart="startSlider(event, this)" ontouchmove="moveSlider(event, this)">
-      <img src="{{.CurrentDataURI}}" alt="Current" draggable="false">
+      <img loading="lazy" src="{{.CurrentDataURI}}" alt="Current" draggable="false">
       <div class="slider-baseline">
-        <img src="{{.BaselineDataURI}}" alt="Baseline" draggable="false">
+        <img loading="lazy" src="{{.BaselineDataURI}}" alt="Baseline" draggable="false">
       </div>
       <div class="slider-divider" style="left: calc(50% - 1.5px);"></div>
       <span class="slider-label slider-label-left">Baseline</span>
@@ -242,50 +789,92 @@ const htmlTemplate = `<!DOCTYPE html>
     <div class="side-by-side">
       <div class="img-container">
         <div class="img-label">Baseline</div>
-        <img src="{{.BaselineDataURI}}" alt="Baseline">
+        <img loading="lazy" src="{{.BaselineDataURI}}" alt="Baseline">
       </div>
       <div class="img-container">
         <div class="img-label">Current</div>
-        <img src="{{.CurrentDataURI}}" alt="Current">
+        <img loading="lazy" src="{{.CurrentDataURI}}" alt="Current">
       </div>
     </div>
   </div>
+  {{else}}
+  <div class="tab-content active" data-tab="current">
+    <div class="single-image">
+      <img loading="lazy" src="{{.CurrentDataURI}}" alt="Current">
+    </div>
+  </div>
+  {{end}}
   <div class="tab-content" data-tab="diff">
+    <div class="print-label">Diff overlay</div>
     <div class="diff-overlay">
-      {{if .HasDiff}}<img src="{{.DiffDataURI}}" alt="Diff overlay">{{end}}
+      {{if .HasDiff}}<img loading="lazy" src="{{.DiffDataURI}}" alt="Diff overlay">{{end}}
     </div>
   </div>
+  {{if .HasReference}}
+  <div class="tab-content" data-tab="threeway">
+    <div class="side-by-side three-way">
+      {{if .HasBaseline}}<div class="img-container">
+        <div class="img-label">Baseline</div>
+        <img loading="lazy" src="{{.BaselineDataURI}}" alt="Baseline">
+      </div>{{end}}
+      <div class="img-container">
+        <div class="img-label">Current</div>
+        <img loading="lazy" src="{{.CurrentDataURI}}" alt="Current">
+      </div>
+      <div class="img-container">
+        <div class="img-label">Reference</div>
+        {{if .ReferenceDataURI}}<img loading="lazy" src="{{.ReferenceDataURI}}" alt="Reference">{{else}}<div class="img-label">Not in reference</div>{{end}}
+      </div>
+    </div>
+  </div>
+  <div class="tab-content" data-tab="refdiff">
+    <div class="print-label">Diff vs reference</div>
+    <div class="diff-overlay">
+      {{if .HasReferenceDiff}}<img loading="lazy" src="{{.ReferenceDiffDataURI}}" alt="Diff against reference">{{else}}<p>Current is {{.ReferenceStatus}} compared to the reference.</p>{{end}}
+    </div>
+  </div>
+  {{end}}
 </div>
 {{end}}
+{{end}}
 
-{{if eq .Status "added"}}
+{{if .Added}}
+<h2 class="section-title">Added ({{len .Added}})</h2>
+{{range .Added}}
 <div class="card">
   <div class="card-header">
-    <span class="card-name">{{.Name}}</span>
-    <span class="card-badge badge-added">added</span>
+    <span class="card-name">{{.Name}}{{if .Duplicates}} <span class="renamed-from">(also captured as {{.Duplicates}})</span>{{end}}{{template "context" .}}</span>
+    <span class="card-actions">
+      {{if .AcceptCommand}}<button class="copy-cmd" data-cmd="{{.AcceptCommand}}" title="{{.AcceptCommand}}" onclick="copyCommand(this)">Copy accept command</button>{{end}}
+      <span class="card-badge badge-added">added</span>
+    </span>
   </div>
   <div class="tab-content active" data-tab="single">
     <div class="single-image">
-      {{if .HasCurrent}}<img src="{{.CurrentDataURI}}" alt="New screenshot">{{end}}
+      {{if .HasCurrent}}<img loading="lazy" src="{{.CurrentDataURI}}" alt="New screenshot">{{end}}
     </div>
   </div>
 </div>
 {{end}}
+{{end}}
 
-{{if eq .Status "removed"}}
+{{if .Removed}}
+<h2 class="section-title">Removed ({{len .Removed}})</h2>
+{{range .Removed}}
 <div class="card">
   <div class="card-header">
-    <span class="card-name">{{.Name}}</span>
+    <span class="card-name">{{.Name}}{{template "context" .}}</span>
     <span class="card-badge badge-removed">removed</span>
   </div>
   <div class="tab-content active" data-tab="single">
     <div class="single-image">
-      {{if .HasBaseline}}<img src="{{.BaselineDataURI}}" alt="Removed screenshot">{{end}}
+      {{if .HasBaseline}}<img loading="lazy" src="{{.BaselineDataURI}}" alt="Removed screenshot">{{end}}
     </div>
   </div>
 </div>
 {{end}}
 {{end}}
+{{end}}
 
 {{if gt .UnchangedCount 0}}
 <div class="unchanged-section">
@@ -293,7 +882,13 @@ const htmlTemplate = `<!DOCTYPE html>
     &#9654; {{.UnchangedCount}} unchanged screenshot{{if ne .UnchangedCount 1}}s{{end}} (click to expand)
   </div>
   <div class="unchanged-list">
-    {{range .Entries}}{{if eq .Status "unchanged"}}<div class="unchanged-item">{{.Name}}</div>{{end}}{{end}}
+    <div id="unchanged-items"{{if .ShowThumbnails}} class="thumb-grid"{{end}}></div>
+    <div class="unchanged-pager">
+      <button id="unchanged-prev" onclick="pageUnchanged(-1)">&larr; Prev</button>
+      <span id="unchanged-range"></span>
+      <button id="unchanged-next" onclick="pageUnchanged(1)">Next &rarr;</button>
+      <button id="unchanged-all" onclick="toggleAllUnchanged()"></button>
+    </div>
   </div>
 </div>
 {{end}}
@@ -301,6 +896,55 @@ const htmlTemplate = `<!DOCTYPE html>
 </div>
 
 <script>
+// Copy a card's accept command to the clipboard
+function copyCommand(btn) {
+  const text = btn.dataset.cmd;
+  const done = function() {
+    const label = btn.dataset.label || (btn.dataset.label = btn.textContent);
+    btn.textContent = 'Copied!';
+    setTimeout(function() { btn.textContent = label; }, 1500);
+  };
+  if (navigator.clipboard && window.isSecureContext) {
+    navigator.clipboard.writeText(text).then(done);
+    return;
+  }
+  // Fallback for file:// pages where the async clipboard API is unavailable
+  const area = document.createElement('textarea');
+  area.value = text;
+  document.body.appendChild(area);
+  area.select();
+  document.execCommand('copy');
+  document.body.removeChild(area);
+  done();
+}
+
+// Expand or collapse a changed card (buttons in the header keep working)
+function toggleCard(e, header) {
+  if (e.target.closest('button')) return;
+  header.closest('.card').classList.toggle('collapsed');
+}
+
+// Lazy images below the fold may not have loaded yet; load them all before
+// printing so the PDF is complete
+function loadAllImages() {
+  const images = Array.from(document.querySelectorAll('img[loading="lazy"]'));
+  images.forEach(function(img) { img.loading = 'eager'; });
+  return Promise.all(images.map(function(img) {
+    if (img.complete) return null;
+    return new Promise(function(resolve) {
+      img.addEventListener('load', resolve, { once: true });
+      img.addEventListener('error', resolve, { once: true });
+    });
+  }));
+}
+
+function printReport() {
+  loadAllImages().then(function() { window.print(); });
+}
+
+// Printing from the browser menu can't wait for images, but still start them
+window.addEventListener('beforeprint', loadAllImages);
+
 // Tab switching
 function switchTab(tabEl, tabName) {
   const card = tabEl.closest('.card');
@@ -339,7 +983,64 @@ function toggleUnchanged(el) {
   const list = el.nextElementSibling;
   const isOpen = list.classList.toggle('open');
   el.innerHTML = (isOpen ? '&#9660;' : '&#9654;') + ' {{.UnchangedCount}} unchanged screenshot{{if ne .UnchangedCount 1}}s{{end}} (click to ' + (isOpen ? 'collapse' : 'expand') + ')';
+  if (isOpen) renderUnchanged();
+}
+
+// Unchanged screenshots are rendered a page at a time to keep the DOM small
+const unchanged = {{.Unchanged}} || [];
+const unchangedPageSize = {{.UnchangedPageSize}};
+const showThumbnails = {{.ShowThumbnails}};
+let unchangedPage = 0;
+let unchangedShowAll = false;
+
+function unchangedItem(entry) {
+  const item = document.createElement('div');
+  if (!showThumbnails) {
+    item.className = 'unchanged-item';
+    item.textContent = entry.name;
+    return item;
+  }
+  item.className = 'thumb';
+  if (entry.thumb) {
+    const img = document.createElement('img');
+    img.src = entry.thumb;
+    img.alt = entry.name;
+    img.loading = 'lazy';
+    item.appendChild(img);
+  }
+  const name = document.createElement('div');
+  name.className = 'thumb-name';
+  name.textContent = entry.name;
+  item.appendChild(name);
+  return item;
+}
+
+function renderUnchanged() {
+  const pages = Math.max(1, Math.ceil(unchanged.length / unchangedPageSize));
+  unchangedPage = Math.min(Math.max(unchangedPage, 0), pages - 1);
+  const start = unchangedShowAll ? 0 : unchangedPage * unchangedPageSize;
+  const end = unchangedShowAll ? unchanged.length : Math.min(start + unchangedPageSize, unchanged.length);
+
+  document.getElementById('unchanged-items').replaceChildren(...unchanged.slice(start, end).map(unchangedItem));
+  document.getElementById('unchanged-range').textContent = (start + 1) + '–' + end + ' of ' + unchanged.length;
+  document.getElementById('unchanged-prev').disabled = unchangedShowAll || unchangedPage === 0;
+  document.getElementById('unchanged-next').disabled = unchangedShowAll || unchangedPage === pages - 1;
+  const all = document.getElementById('unchanged-all');
+  all.textContent = unchangedShowAll ? 'Show ' + unchangedPageSize : 'Show all';
+  all.hidden = unchanged.length <= unchangedPageSize;
+}
+
+function pageUnchanged(delta) {
+  unchangedPage += delta;
+  renderUnchanged();
+}
+
+function toggleAllUnchanged() {
+  unchangedShowAll = !unchangedShowAll;
+  renderUnchanged();
 }
 </script>
 </body>
-</html>`
+</html>
+{{- define "stats"}}<span class="card-stats">{{.DiffPixels}} of {{.TotalPixels}} pixels differ ({{.DiffPercent}}){{if .Regions}} &middot; {{.Regions}} region{{if ne .Regions 1}}s{{end}}, largest {{.LargestRegion}} px{{end}}{{if .DPRScale}} &middot; current is {{.DPRScale}} the baseline's size (device pixel ratio changed?){{end}}</span>{{end}}
+{{- define "context"}}{{if .Context}}<span class="card-context"{{if .ContextURL}} title="{{.ContextURL}}"{{end}}>{{.Context}}</span>{{end}}{{end}}`
@@ -0,0 +1,62 @@
+package imgdiff
+
+import (
+	"image"
+	"image/color"
+)
+
+// downscale returns a copy of img scaled so that it fits within maxWidth ×
+// maxHeight, preserving aspect ratio. A limit of 0 means unbounded. Images
+// that already fit are returned unchanged. Each output pixel is the average
+// of the source pixels it covers, which keeps thin UI lines visible.
+func downscale(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return img
+	}
+
+	scale := 1.0
+	if maxWidth > 0 && w > maxWidth {
+		scale = min(scale, float64(maxWidth)/float64(w))
+	}
+	if maxHeight > 0 && h > maxHeight {
+		scale = min(scale, float64(maxHeight)/float64(h))
+	}
+	if scale >= 1.0 {
+		return img
+	}
+
+	dw := max(int(float64(w)*scale), 1)
+	dh := max(int(float64(h)*scale), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for dy := 0; dy < dh; dy++ {
+		sy0 := b.Min.Y + dy*h/dh
+		sy1 := max(b.Min.Y+(dy+1)*h/dh, sy0+1)
+		for dx := 0; dx < dw; dx++ {
+			sx0 := b.Min.X + dx*w/dw
+			sx1 := max(b.Min.X+(dx+1)*w/dw, sx0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					bl += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			dst.Set(dx, dy, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
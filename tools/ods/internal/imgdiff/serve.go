@@ -0,0 +1,70 @@
+package imgdiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// imagePath is the URL path NewReportHandler serves images from.
+const imagePath = "/image"
+
+// NewReportHandler returns an HTTP handler that serves the report for
+// results at "/" and its images on demand, so the page loads without first
+// encoding every image. The page is rendered once, up front.
+func NewReportHandler(results []Result, opts ReportOptions) (http.Handler, error) {
+	byName := make(map[string]Result, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	opts.ImageURL = func(name string, kind ImageKind) string {
+		return imagePath + "?" + url.Values{"name": {name}, "kind": {string(kind)}}.Encode()
+	}
+	var page bytes.Buffer
+	if err := writeReport(&page, results, opts); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page.Bytes())
+	})
+	mux.HandleFunc("GET "+imagePath, func(w http.ResponseWriter, req *http.Request) {
+		r, ok := byName[req.URL.Query().Get("name")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		serveImage(w, req, r, ImageKind(req.URL.Query().Get("kind")), opts)
+	})
+
+	return mux, nil
+}
+
+// serveImage writes one of a result's images, encoded as it would be
+// embedded in the report.
+func serveImage(w http.ResponseWriter, req *http.Request, r Result, kind ImageKind, opts ReportOptions) {
+	data, mimeType, err := reportImage(r, kind, opts)
+	if errors.Is(err, errNoImage) {
+		http.NotFound(w, req)
+		return
+	}
+	if err != nil {
+		serveImageError(w, r.Name, kind, err)
+		return
+	}
+	w.Header().Set("Content-Type", mimeType)
+	_, _ = w.Write(data)
+}
+
+// serveImageError logs and reports a failure to produce an image.
+func serveImageError(w http.ResponseWriter, name string, kind ImageKind, err error) {
+	log.Warnf("Failed to serve %s image for %s: %v", kind, name, err)
+	http.Error(w, fmt.Sprintf("failed to encode %s image: %v", kind, err), http.StatusInternalServerError)
+}
@@ -0,0 +1,62 @@
+package imgdiff
+
+import (
+	"image/color"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewReportHandler(t *testing.T) {
+	dir := t.TempDir()
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	createTestPNG(t, filepath.Join(dir, "baseline", "home page.png"), 10, 10, white)
+	createTestPNG(t, filepath.Join(dir, "current", "home page.png"), 10, 10, red)
+
+	results, err := CompareDirectories(filepath.Join(dir, "baseline"), filepath.Join(dir, "current"), 0.2)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+
+	handler, err := NewReportHandler(results, ReportOptions{})
+	if err != nil {
+		t.Fatalf("NewReportHandler failed: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	get := func(path string) (int, string, string) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(body)
+	}
+
+	status, _, page := get("/")
+	if status != http.StatusOK {
+		t.Fatalf("expected 200 for the report, got %d", status)
+	}
+	if contains(page, "data:image/png") {
+		t.Error("served report should reference images by URL, not inline them")
+	}
+	if !contains(page, `src="/image?kind=diff&amp;name=home&#43;page.png"`) {
+		t.Error("served report missing the diff image URL")
+	}
+
+	for _, kind := range []ImageKind{ImageBaseline, ImageCurrent, ImageDiff} {
+		status, contentType, _ := get("/image?kind=" + string(kind) + "&name=home+page.png")
+		if status != http.StatusOK || contentType != "image/png" {
+			t.Errorf("%s image: expected 200 image/png, got %d %s", kind, status, contentType)
+		}
+	}
+	if status, _, _ := get("/image?kind=diff&name=missing.png"); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown screenshot, got %d", status)
+	}
+}
@@ -0,0 +1,80 @@
+package imgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Viewport is the browser viewport a screenshot was captured at.
+type Viewport struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ScreenshotContext describes how a screenshot was captured, as recorded
+// in a JSON sidecar file next to it (see CompareOptions.Sidecars):
+//
+//	{"url": "http://localhost:3000/admin/documents",
+//	 "viewport": {"width": 1280, "height": 720},
+//	 "test": "admin documents explorer"}
+type ScreenshotContext struct {
+	URL      string   `json:"url,omitempty"`
+	Viewport Viewport `json:"viewport"`
+	Test     string   `json:"test,omitempty"`
+}
+
+// Label returns a short description such as "1280×720 · /admin/documents".
+// Absolute URLs are shortened to their path and query.
+func (c ScreenshotContext) Label() string {
+	var parts []string
+	if c.Viewport.Width > 0 && c.Viewport.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", c.Viewport.Width, c.Viewport.Height))
+	}
+	if c.URL != "" {
+		page := c.URL
+		if u, err := url.Parse(c.URL); err == nil && u.Host != "" && u.Path != "" {
+			page = u.RequestURI()
+		}
+		parts = append(parts, page)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// sidecarPaths returns the candidate sidecar files for a screenshot:
+// "page.json" and "page.png.json" for "page.png".
+func sidecarPaths(screenshotPath string) []string {
+	ext := strings.LastIndex(screenshotPath, ".")
+	if ext <= strings.LastIndexAny(screenshotPath, `/\`) {
+		return []string{screenshotPath + ".json"}
+	}
+	return []string{screenshotPath[:ext] + ".json", screenshotPath + ".json"}
+}
+
+// readContext reads the sidecar of the current screenshot, falling back to
+// the baseline's (e.g. for removed screenshots). Missing sidecars yield an
+// empty context; malformed ones are logged and skipped.
+func readContext(r Result) ScreenshotContext {
+	for _, path := range []string{r.CurrentPath, r.BaselinePath} {
+		if path == "" {
+			continue
+		}
+		for _, sidecar := range sidecarPaths(path) {
+			data, err := os.ReadFile(sidecar)
+			if err != nil {
+				continue
+			}
+			var c ScreenshotContext
+			if err := json.Unmarshal(data, &c); err != nil {
+				log.Warnf("Ignoring malformed sidecar %s: %v", sidecar, err)
+				continue
+			}
+			return c
+		}
+	}
+	return ScreenshotContext{}
+}
@@ -0,0 +1,60 @@
+package imgdiff
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortKey controls how changed results are ordered relative to each other.
+type SortKey string
+
+const (
+	// SortByDiffPercent orders changed results by the share of differing pixels.
+	SortByDiffPercent SortKey = "diff-percent"
+	// SortByDiffPixels orders changed results by the absolute number of differing pixels.
+	SortByDiffPixels SortKey = "diff-pixels"
+	// SortByRegions orders changed results by the number of distinct changed clusters.
+	SortByRegions SortKey = "regions"
+)
+
+// SortKeys lists all supported sort keys.
+var SortKeys = []SortKey{SortByDiffPercent, SortByDiffPixels, SortByRegions}
+
+// ParseSortKey validates a sort key name.
+func ParseSortKey(s string) (SortKey, error) {
+	for _, k := range SortKeys {
+		if string(k) == s {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("invalid sort key %q (valid: %s, %s, %s)", s, SortByDiffPercent, SortByDiffPixels, SortByRegions)
+}
+
+// SortResults orders results in place: errors first, then changed (by key,
+// descending), added, removed, and unchanged, each alphabetically. The key only
+// affects ordering among changed results, never their classification.
+func SortResults(results []Result, key SortKey) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Status != b.Status {
+			return statusOrder(a.Status) < statusOrder(b.Status)
+		}
+		if a.Status == StatusChanged {
+			switch key {
+			case SortByDiffPixels:
+				if a.DiffPixels != b.DiffPixels {
+					return a.DiffPixels > b.DiffPixels
+				}
+			case SortByRegions:
+				if a.Regions != b.Regions {
+					return a.Regions > b.Regions
+				}
+			default:
+				if a.DiffPercent != b.DiffPercent {
+					return a.DiffPercent > b.DiffPercent
+				}
+			}
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,94 @@
+package imgdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AllScreenshots is the SourceMap value that selects every screenshot, for
+// sources such as shared components that any page may render.
+const AllScreenshots = "*"
+
+// SourceMap maps source file patterns (relative to the repository root) to
+// the screenshots that depend on them, e.g.
+//
+//	{
+//	  "web/src/app/admin/documents/": ["admin-documents-explorer.png"],
+//	  "web/src/app/chat/*.tsx": ["chat-empty.png", "chat-thread.png"],
+//	  "web/src/components/": ["*"]
+//	}
+//
+// A pattern ending in "/" matches every file under that directory; any other
+// pattern is matched against the whole path with path.Match.
+type SourceMap map[string][]string
+
+// LoadSourceMap reads and validates a SourceMap JSON file.
+func LoadSourceMap(file string) (SourceMap, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source map: %w", err)
+	}
+
+	var m SourceMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse source map %s: %w", file, err)
+	}
+
+	for pattern, names := range m {
+		if pattern == "" {
+			return nil, fmt.Errorf("source map: patterns must not be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("source map: invalid pattern %q: %w", pattern, err)
+		}
+		for _, name := range names {
+			if name == "" || filepath.Base(name) != name {
+				return nil, fmt.Errorf("source map: %q -> %q must be a plain screenshot filename", pattern, name)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Screenshots returns the screenshots that depend on the changed source
+// files, for CompareOptions.Only, and the changed files no pattern matched.
+// all is true when a matching pattern maps to AllScreenshots, in which case
+// the comparison should not be restricted.
+func (m SourceMap) Screenshots(changed []string) (names map[string]bool, all bool, unmapped []string) {
+	names = make(map[string]bool)
+	for _, file := range changed {
+		file = filepath.ToSlash(file)
+		matched := false
+		for pattern, screenshots := range m {
+			if !matchSource(pattern, file) {
+				continue
+			}
+			matched = true
+			for _, name := range screenshots {
+				if name == AllScreenshots {
+					all = true
+					continue
+				}
+				names[name] = true
+			}
+		}
+		if !matched {
+			unmapped = append(unmapped, file)
+		}
+	}
+	return names, all, unmapped
+}
+
+// matchSource reports whether a source file matches a SourceMap pattern.
+func matchSource(pattern, file string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(file, pattern)
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
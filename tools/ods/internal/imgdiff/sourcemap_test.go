@@ -0,0 +1,67 @@
+package imgdiff
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeSourceMap(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "sources.json")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestSourceMap_Screenshots(t *testing.T) {
+	m, err := LoadSourceMap(writeSourceMap(t, `{
+		"web/src/app/admin/documents/": ["admin-documents-explorer.png"],
+		"web/src/app/chat/*.tsx": ["chat-empty.png", "chat-thread.png"],
+		"web/src/components/": ["*"]
+	}`))
+	if err != nil {
+		t.Fatalf("LoadSourceMap: %v", err)
+	}
+
+	names, all, unmapped := m.Screenshots([]string{
+		"web/src/app/admin/documents/table/Row.tsx",
+		"web/src/app/chat/page.tsx",
+		"backend/main.py",
+	})
+	if all {
+		t.Error("expected no pattern to select every screenshot")
+	}
+	want := []string{"admin-documents-explorer.png", "chat-empty.png", "chat-thread.png"}
+	if got := slices.Sorted(maps.Keys(names)); !slices.Equal(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+	if !slices.Equal(unmapped, []string{"backend/main.py"}) {
+		t.Errorf("unmapped = %v, want [backend/main.py]", unmapped)
+	}
+
+	// path.Match's * does not cross directories
+	if names, _, _ := m.Screenshots([]string{"web/src/app/chat/thread/page.tsx"}); len(names) != 0 {
+		t.Errorf("expected a nested file not to match chat/*.tsx, got %v", names)
+	}
+
+	if _, all, _ := m.Screenshots([]string{"web/src/components/Button.tsx"}); !all {
+		t.Error("expected a shared component to select every screenshot")
+	}
+}
+
+func TestLoadSourceMap_Invalid(t *testing.T) {
+	for _, content := range []string{
+		`{"web/[": ["a.png"]}`,
+		`{"web/": ["shots/a.png"]}`,
+		`{"": ["a.png"]}`,
+		`["a.png"]`,
+	} {
+		if _, err := LoadSourceMap(writeSourceMap(t, content)); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
@@ -3,27 +3,120 @@ package imgdiff
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/atomicfile"
 )
 
 // Summary holds aggregate comparison results in a JSON-friendly format.
 // It is written alongside the HTML report so that CI pipelines can read it
 // without parsing HTML.
 type Summary struct {
-	Project        string `json:"project"`
-	Changed        int    `json:"changed"`
-	Added          int    `json:"added"`
-	Removed        int    `json:"removed"`
-	Unchanged      int    `json:"unchanged"`
-	Total          int    `json:"total"`
-	HasDifferences bool   `json:"has_differences"`
+	Project        string        `json:"project"`
+	Changed        int           `json:"changed"`
+	Added          int           `json:"added"`
+	Removed        int           `json:"removed"`
+	Unchanged      int           `json:"unchanged"`
+	Errored        int           `json:"errored,omitempty"` // unreadable screenshots (--on-traverse-error skip)
+	Total          int           `json:"total"`
+	HasDifferences bool          `json:"has_differences"`
+	NoScreenshots  bool          `json:"no_screenshots"`    // the current directory was missing or empty
+	Partial        bool          `json:"partial,omitempty"` // comparison stopped early (--fail-fast)
+	Files          []FileSummary `json:"files,omitempty"`
+	Delta          *SummaryDelta `json:"delta,omitempty"`
+
+	// Timings is the per-phase breakdown recorded with --profile-timings.
+	Timings *Timings `json:"timings,omitempty"`
+
+	// Projects breaks the counts down per project for nested comparisons
+	// (see CompareNestedDirectories), sorted by project name.
+	Projects []ProjectSummary `json:"projects,omitempty"`
 }
 
-// BuildSummary computes a Summary from a slice of comparison results.
+// ProjectSummary holds one project's counts in a nested comparison.
+type ProjectSummary struct {
+	Project   string `json:"project"`
+	Changed   int    `json:"changed"`
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Unchanged int    `json:"unchanged"`
+	Errored   int    `json:"errored,omitempty"`
+	Total     int    `json:"total"`
+}
+
+// FileSummary records the outcome for a single screenshot.
+type FileSummary struct {
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	DiffPercent float64  `json:"diff_percent,omitempty"`
+	RenamedFrom string   `json:"renamed_from,omitempty"`
+	Duplicates  []string `json:"duplicates,omitempty"`
+
+	// IgnoredPixels counts pixels excluded by ignore regions or
+	// --ignore-scrollbar.
+	IgnoredPixels int `json:"ignored_pixels,omitempty"`
+
+	// Project is set for nested comparisons (see Result.Project).
+	Project string `json:"project,omitempty"`
+
+	// DPRScale is set when the images' sizes differ by an integer factor
+	// (see Result.DPRScale).
+	DPRScale float64 `json:"dpr_scale,omitempty"`
+
+	// Error is why the screenshot could not be compared (status "error").
+	Error string `json:"error,omitempty"`
+
+	// Warning flags a likely misleading comparison (see Result.Warning).
+	Warning string `json:"warning,omitempty"`
+
+	// The numbers behind DiffPercent, so a report can be rebuilt from the
+	// summary (see ResultsFromSummary).
+	DiffPixels    int `json:"diff_pixels,omitempty"`
+	TotalPixels   int `json:"total_pixels,omitempty"`
+	Regions       int `json:"regions,omitempty"`
+	LargestRegion int `json:"largest_region,omitempty"`
+}
+
+// SummaryDelta describes how a run differs from a previous run's summary,
+// so reviewers can focus on new regressions rather than known ones.
+type SummaryDelta struct {
+	ChangedDelta int      `json:"changed_delta"`
+	NewlyChanged []string `json:"newly_changed"`
+	NewlyAdded   []string `json:"newly_added"`
+	NewlyRemoved []string `json:"newly_removed"`
+	NewlyFixed   []string `json:"newly_fixed"`
+}
+
+// BuildSummary computes a Summary from a slice of comparison results. Total
+// is the sum of the per-status counts, so the counts always reconcile; a
+// result with a status that is not counted is logged as a warning rather
+// than silently inflating Total.
 func BuildSummary(project string, results []Result) Summary {
 	s := Summary{Project: project}
 	for _, r := range results {
+		s.Files = append(s.Files, FileSummary{
+			Name:        r.Name,
+			Status:      r.Status.String(),
+			DiffPercent: r.DiffPercent,
+			RenamedFrom: r.RenamedFrom,
+			Duplicates:  r.Duplicates,
+
+			IgnoredPixels: r.IgnoredPixels,
+			Project:       r.Project,
+			DPRScale:      r.DPRScale,
+			Error:         r.Error,
+			Warning:       r.Warning,
+
+			DiffPixels:    r.DiffPixels,
+			TotalPixels:   r.TotalPixels,
+			Regions:       r.Regions,
+			LargestRegion: r.LargestRegion,
+		})
 		switch r.Status {
 		case StatusChanged:
 			s.Changed++
@@ -33,13 +126,58 @@ func BuildSummary(project string, results []Result) Summary {
 			s.Removed++
 		case StatusUnchanged:
 			s.Unchanged++
+		case StatusError:
+			s.Errored++
 		}
 	}
-	s.Total = len(results)
-	s.HasDifferences = s.Changed > 0 || s.Added > 0 || s.Removed > 0
+	s.Total = s.Changed + s.Added + s.Removed + s.Unchanged + s.Errored
+	if s.Total != len(results) {
+		log.Warnf("Summary for %s counts %d of %d results; %d have an uncounted status",
+			project, s.Total, len(results), len(results)-s.Total)
+	}
+	s.HasDifferences = s.Changed > 0 || s.Added > 0 || s.Removed > 0 || s.Errored > 0
+	s.Projects = projectSummaries(results)
 	return s
 }
 
+// projectSummaries counts results per project, or returns nil if none of
+// them came from a nested comparison.
+func projectSummaries(results []Result) []ProjectSummary {
+	byProject := make(map[string]*ProjectSummary)
+	for _, r := range results {
+		if r.Project == "" {
+			continue
+		}
+		p, ok := byProject[r.Project]
+		if !ok {
+			p = &ProjectSummary{Project: r.Project}
+			byProject[r.Project] = p
+		}
+		switch r.Status {
+		case StatusChanged:
+			p.Changed++
+		case StatusAdded:
+			p.Added++
+		case StatusRemoved:
+			p.Removed++
+		case StatusUnchanged:
+			p.Unchanged++
+		case StatusError:
+			p.Errored++
+		}
+		p.Total++
+	}
+	if len(byProject) == 0 {
+		return nil
+	}
+
+	projects := make([]ProjectSummary, 0, len(byProject))
+	for _, name := range slices.Sorted(maps.Keys(byProject)) {
+		projects = append(projects, *byProject[name])
+	}
+	return projects
+}
+
 // WriteSummary writes a Summary as pretty-printed JSON to the given path,
 // creating parent directories as needed.
 func WriteSummary(summary Summary, path string) error {
@@ -52,9 +190,122 @@ func WriteSummary(summary Summary, path string) error {
 		return fmt.Errorf("failed to marshal summary: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	// Replace the file atomically, so a concurrent reader (e.g. another
+	// --append shard) never sees a partial summary
+	if err := atomicfile.WriteFile(path, data); err != nil {
 		return fmt.Errorf("failed to write summary: %w", err)
 	}
 
 	return nil
 }
+
+// LoadSummary reads a Summary previously written by WriteSummary.
+func LoadSummary(path string) (Summary, error) {
+	var summary Summary
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return summary, fmt.Errorf("failed to read summary: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return summary, fmt.Errorf("failed to parse summary %s: %w", path, err)
+	}
+
+	return summary, nil
+}
+
+// MergeSummaries merges incoming into existing, for sharded runs in which
+// each shard compares part of the screenshots against the full baseline.
+// Files are matched by name. A shard reports the screenshots it did not
+// capture as removed, so a removed entry never replaces another status;
+// otherwise incoming wins, and names both summaries captured are returned
+// as conflicts. Counts and per-project totals are recomputed from the
+// merged files. Delta and Timings describe a single run and are dropped.
+func MergeSummaries(existing, incoming Summary) (Summary, []string, error) {
+	byName := make(map[string]int, len(existing.Files))
+	files := slices.Clone(existing.Files)
+	for i, f := range files {
+		byName[f.Name] = i
+	}
+
+	var conflicts []string
+	for _, f := range incoming.Files {
+		if i, ok := byName[f.Name]; ok {
+			removed := StatusRemoved.String()
+			if f.Status == removed {
+				continue
+			}
+			if files[i].Status != removed {
+				conflicts = append(conflicts, f.Name)
+			}
+			files[i] = f
+			continue
+		}
+		byName[f.Name] = len(files)
+		files = append(files, f)
+	}
+
+	results := make([]Result, 0, len(files))
+	for _, f := range files {
+		r, err := fileResult(f)
+		if err != nil {
+			return Summary{}, nil, err
+		}
+		results = append(results, r)
+	}
+
+	merged := BuildSummary(incoming.Project, results)
+	merged.NoScreenshots = existing.NoScreenshots && incoming.NoScreenshots
+	merged.Partial = existing.Partial || incoming.Partial
+	return merged, conflicts, nil
+}
+
+// DiffSummaries compares the current summary against a previous one. A file
+// is "newly" changed/added/removed if it has that status now but did not in
+// the previous run, and "newly fixed" if it had any difference before and is
+// unchanged (or no longer present) now. The previous summary must contain
+// per-file detail for the name lists to be meaningful.
+func DiffSummaries(previous, current Summary) SummaryDelta {
+	prevStatus := make(map[string]string, len(previous.Files))
+	for _, f := range previous.Files {
+		prevStatus[f.Name] = f.Status
+	}
+	curStatus := make(map[string]string, len(current.Files))
+	for _, f := range current.Files {
+		curStatus[f.Name] = f.Status
+	}
+
+	delta := SummaryDelta{
+		ChangedDelta: current.Changed - previous.Changed,
+		NewlyChanged: []string{},
+		NewlyAdded:   []string{},
+		NewlyRemoved: []string{},
+		NewlyFixed:   []string{},
+	}
+
+	for _, f := range current.Files {
+		if prevStatus[f.Name] == f.Status {
+			continue
+		}
+		switch f.Status {
+		case StatusChanged.String():
+			delta.NewlyChanged = append(delta.NewlyChanged, f.Name)
+		case StatusAdded.String():
+			delta.NewlyAdded = append(delta.NewlyAdded, f.Name)
+		case StatusRemoved.String():
+			delta.NewlyRemoved = append(delta.NewlyRemoved, f.Name)
+		}
+	}
+
+	for _, f := range previous.Files {
+		if f.Status == StatusUnchanged.String() {
+			continue
+		}
+		if status, ok := curStatus[f.Name]; !ok || status == StatusUnchanged.String() {
+			delta.NewlyFixed = append(delta.NewlyFixed, f.Name)
+		}
+	}
+
+	return delta
+}
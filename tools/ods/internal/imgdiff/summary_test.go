@@ -0,0 +1,133 @@
+package imgdiff
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSummaries(t *testing.T) {
+	previous := BuildSummary("admin", []Result{
+		{Name: "known.png", Status: StatusChanged, DiffPercent: 2},
+		{Name: "fixed.png", Status: StatusChanged, DiffPercent: 1},
+		{Name: "stable.png", Status: StatusUnchanged},
+		{Name: "regressed.png", Status: StatusUnchanged},
+	})
+	current := BuildSummary("admin", []Result{
+		{Name: "known.png", Status: StatusChanged, DiffPercent: 2},
+		{Name: "regressed.png", Status: StatusChanged, DiffPercent: 5},
+		{Name: "brand-new.png", Status: StatusAdded},
+		{Name: "fixed.png", Status: StatusUnchanged},
+		{Name: "stable.png", Status: StatusUnchanged},
+	})
+
+	delta := DiffSummaries(previous, current)
+
+	if delta.ChangedDelta != 0 {
+		t.Errorf("expected changed delta 0, got %d", delta.ChangedDelta)
+	}
+	if !reflect.DeepEqual(delta.NewlyChanged, []string{"regressed.png"}) {
+		t.Errorf("unexpected newly changed: %v", delta.NewlyChanged)
+	}
+	if !reflect.DeepEqual(delta.NewlyAdded, []string{"brand-new.png"}) {
+		t.Errorf("unexpected newly added: %v", delta.NewlyAdded)
+	}
+	if !reflect.DeepEqual(delta.NewlyFixed, []string{"fixed.png"}) {
+		t.Errorf("unexpected newly fixed: %v", delta.NewlyFixed)
+	}
+}
+
+func TestSummaryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	summary := BuildSummary("admin", []Result{
+		{Name: "page.png", Status: StatusChanged, DiffPercent: 3.5},
+	})
+
+	if err := WriteSummary(summary, path); err != nil {
+		t.Fatalf("WriteSummary failed: %v", err)
+	}
+	loaded, err := LoadSummary(path)
+	if err != nil {
+		t.Fatalf("LoadSummary failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, summary) {
+		t.Errorf("round-tripped summary mismatch:\n got %+v\nwant %+v", loaded, summary)
+	}
+}
+
+func TestBuildSummary_Reconciles(t *testing.T) {
+	results := []Result{
+		{Name: "changed.png", Status: StatusChanged, DiffPercent: 3},
+		{Name: "added.png", Status: StatusAdded},
+		{Name: "removed.png", Status: StatusRemoved},
+		{Name: "removed-2.png", Status: StatusRemoved},
+		{Name: "unchanged.png", Status: StatusUnchanged},
+		{Name: "unchanged-2.png", Status: StatusUnchanged},
+		{Name: "unchanged-3.png", Status: StatusUnchanged},
+	}
+
+	s := BuildSummary("admin", results)
+	if s.Changed != 1 || s.Added != 1 || s.Removed != 2 || s.Unchanged != 3 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if s.Total != len(results) || s.Changed+s.Added+s.Removed+s.Unchanged != s.Total {
+		t.Errorf("counts do not reconcile with total %d: %+v", s.Total, s)
+	}
+	if len(s.Files) != len(results) {
+		t.Errorf("expected %d files, got %d", len(results), len(s.Files))
+	}
+
+	// A status without a count is excluded from Total instead of silently
+	// breaking the invariant.
+	s = BuildSummary("admin", append(results, Result{Name: "odd.png", Status: Status(99)}))
+	if s.Total != len(results) || s.Changed+s.Added+s.Removed+s.Unchanged != s.Total {
+		t.Errorf("expected total %d to exclude the uncounted status, got %+v", len(results), s)
+	}
+}
+
+func TestMergeSummaries(t *testing.T) {
+	// Each shard compares against the full baseline, so it reports the other
+	// shard's screenshots as removed
+	shard1 := BuildSummary("admin", []Result{
+		{Name: "a.png", Status: StatusChanged, DiffPercent: 3},
+		{Name: "b.png", Status: StatusRemoved},
+		{Name: "gone.png", Status: StatusRemoved},
+		{Name: "twice.png", Status: StatusUnchanged},
+	})
+	shard2 := BuildSummary("admin", []Result{
+		{Name: "a.png", Status: StatusRemoved},
+		{Name: "b.png", Status: StatusUnchanged},
+		{Name: "gone.png", Status: StatusRemoved},
+		{Name: "twice.png", Status: StatusChanged, DiffPercent: 1},
+		{Name: "new.png", Status: StatusAdded},
+	})
+
+	merged, conflicts, err := MergeSummaries(shard1, shard2)
+	if err != nil {
+		t.Fatalf("MergeSummaries failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(conflicts, []string{"twice.png"}) {
+		t.Errorf("conflicts = %v, want [twice.png]", conflicts)
+	}
+	status := make(map[string]string)
+	for _, f := range merged.Files {
+		status[f.Name] = f.Status
+	}
+	want := map[string]string{
+		"a.png":     "changed",
+		"b.png":     "unchanged",
+		"gone.png":  "removed",
+		"twice.png": "changed", // last writer wins
+		"new.png":   "added",
+	}
+	if !reflect.DeepEqual(status, want) {
+		t.Errorf("merged statuses = %v, want %v", status, want)
+	}
+	if merged.Changed != 2 || merged.Added != 1 || merged.Removed != 1 || merged.Unchanged != 1 || merged.Total != 5 {
+		t.Errorf("unexpected merged counts: %+v", merged)
+	}
+	if !merged.HasDifferences {
+		t.Error("expected merged summary to have differences")
+	}
+}
@@ -0,0 +1,121 @@
+package imgdiff
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultSVGDPI is the resolution SVG screenshots are rasterized at when
+// none is set. At 96 DPI one SVG user unit (px) becomes one pixel.
+const DefaultSVGDPI = 96
+
+// ErrSVGRendererMissing is returned when an SVG screenshot must be
+// rasterized but no supported renderer is installed.
+var ErrSVGRendererMissing = errors.New("no SVG renderer found: install rsvg-convert (librsvg) or resvg to compare SVG screenshots")
+
+// svgRenderer is an external command that rasterizes an SVG file to PNG on
+// stdout.
+type svgRenderer struct {
+	name string
+	args func(path string, dpi float64) []string
+}
+
+// svgRenderers are tried in order; the first one on PATH is used.
+var svgRenderers = []svgRenderer{
+	{"rsvg-convert", func(path string, dpi float64) []string {
+		d := strconv.FormatFloat(dpi, 'f', -1, 64)
+		return []string{"--format", "png", "--dpi-x", d, "--dpi-y", d, path}
+	}},
+	{"resvg", func(path string, dpi float64) []string {
+		// resvg only takes an integer DPI
+		return []string{"--dpi", strconv.Itoa(int(dpi + 0.5)), path, "-c"}
+	}},
+}
+
+// IsScreenshotFile reports whether a filename has a supported screenshot
+// extension (.png or .svg), i.e. whether a compare would pick it up.
+func IsScreenshotFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".png" || ext == ".svg"
+}
+
+// isSVG reports whether data (the start of a file) is an SVG document, so
+// SVGs saved under another extension are still recognised.
+func isSVG(data []byte) bool {
+	head := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if bytes.HasPrefix(head, []byte("<svg")) {
+		return true
+	}
+	return bytes.HasPrefix(head, []byte("<?xml")) && bytes.Contains(head, []byte("<svg"))
+}
+
+// decodeScreenshot reads a screenshot file, decoding PNGs directly and
+// rasterizing SVGs at dpi (DefaultSVGDPI if zero).
+func decodeScreenshot(path string, dpi float64) (image.Image, error) {
+	svg, err := sniffSVG(path)
+	if err != nil {
+		return nil, err
+	}
+	if !svg {
+		return decodePNG(path)
+	}
+	if dpi <= 0 {
+		dpi = DefaultSVGDPI
+	}
+	return rasterizeSVG(path, dpi)
+}
+
+// sniffSVG reports whether the file at path is an SVG, by extension or
+// content.
+func sniffSVG(path string) (bool, error) {
+	if strings.EqualFold(filepath.Ext(path), ".svg") {
+		return true, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return isSVG(head[:n]), nil
+}
+
+// rasterizeSVG renders an SVG file to an image with the first available
+// renderer.
+func rasterizeSVG(path string, dpi float64) (image.Image, error) {
+	for _, r := range svgRenderers {
+		bin, err := exec.LookPath(r.name)
+		if err != nil {
+			continue
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(bin, r.args(path, dpi)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("%s failed to render %s: %w: %s", r.name, path, err, strings.TrimSpace(stderr.String()))
+		}
+
+		img, err := png.Decode(&stdout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s output for %s: %w", r.name, path, err)
+		}
+		return img, nil
+	}
+	return nil, fmt.Errorf("cannot rasterize %s: %w", path, ErrSVGRendererMissing)
+}
@@ -0,0 +1,59 @@
+package imgdiff
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="10" height="10"><rect width="10" height="10" fill="red"/></svg>`
+
+func TestIsSVG(t *testing.T) {
+	for data, want := range map[string]bool{
+		testSVG:                                  true,
+		`<?xml version="1.0"?>` + "\n" + testSVG: true,
+		"\n  " + testSVG:                         true,
+		"\x89PNG\r\n\x1a\n":                      false,
+		`<?xml version="1.0"?><html/>`:           false,
+	} {
+		if got := isSVG([]byte(data)); got != want {
+			t.Errorf("isSVG(%q) = %v, want %v", data, got, want)
+		}
+	}
+}
+
+func TestDecodeScreenshot_SVGWithoutRenderer(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	dir := t.TempDir()
+
+	// Detected by content even without an .svg extension
+	path := filepath.Join(dir, "icon.png")
+	if err := os.WriteFile(path, []byte(testSVG), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := decodeScreenshot(path, 0)
+	if !errors.Is(err, ErrSVGRendererMissing) {
+		t.Errorf("expected ErrSVGRendererMissing, got %v", err)
+	}
+}
+
+func TestListScreenshots_IncludesSVG(t *testing.T) {
+	dir := t.TempDir()
+	createTestPNG(t, filepath.Join(dir, "page.png"), 1, 1, color.White)
+	for _, name := range []string{"icon.svg", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(testSVG), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := listScreenshots(dir)
+	if err != nil {
+		t.Fatalf("listScreenshots failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected page.png and icon.svg, got %v", files)
+	}
+}
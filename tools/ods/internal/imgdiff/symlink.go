@@ -0,0 +1,73 @@
+package imgdiff
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrBrokenSymlink is returned for a screenshot directory that is, or sits
+// under, a symlink whose target does not exist. It is reported instead of
+// treating the directory as absent, which would silently compare nothing.
+var ErrBrokenSymlink = errors.New("broken symlink")
+
+// StatDir reports whether dir exists as a directory, following symlinks. A
+// genuinely absent dir returns false and no error; one that is missing
+// because a symlink along its path dangles returns an error wrapping
+// ErrBrokenSymlink, and a path that is not a directory is an error too.
+func StatDir(dir string) (bool, error) {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return false, fmt.Errorf("%s is not a directory", dir)
+		}
+		return true, nil
+	}
+	if !os.IsNotExist(err) {
+		return false, err
+	}
+	if err := brokenSymlink(dir); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// brokenSymlink returns an error wrapping ErrBrokenSymlink if path does not
+// exist because of a dangling symlink: path itself, or its deepest existing
+// ancestor. Otherwise it returns nil.
+func brokenSymlink(path string) error {
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		info, err := os.Lstat(p)
+		if err == nil {
+			if info.Mode()&os.ModeSymlink == 0 {
+				return nil
+			}
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				// A working symlink whose target lacks the rest of path
+				return nil
+			}
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if p == filepath.Clean(path) {
+				return fmt.Errorf("%s points to %s, which does not exist: %w", path, target, ErrBrokenSymlink)
+			}
+			return fmt.Errorf("%s is under %s, which points to %s, which does not exist: %w", path, p, target, ErrBrokenSymlink)
+		}
+		if parent := filepath.Dir(p); parent == p {
+			return nil
+		}
+	}
+}
+
+// isDirEntry reports whether entry, found in dir, is a directory or a
+// symlink to one.
+func isDirEntry(dir string, entry os.DirEntry) bool {
+	if entry.Type()&os.ModeSymlink == 0 {
+		return entry.IsDir()
+	}
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,81 @@
+package imgdiff
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatDir_Symlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "cache")
+	createTestPNG(t, filepath.Join(target, "page.png"), 4, 4, color.White)
+
+	valid := filepath.Join(dir, "valid")
+	dangling := filepath.Join(dir, "dangling")
+	if err := os.Symlink(target, valid); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "gone"), dangling); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, err := StatDir(valid); err != nil || !exists {
+		t.Errorf("valid symlink: expected true, nil; got %v, %v", exists, err)
+	}
+	if has, err := HasScreenshots(valid); err != nil || !has {
+		t.Errorf("valid symlink: expected screenshots, got %v, %v", has, err)
+	}
+
+	if _, err := StatDir(filepath.Join(dir, "absent")); err != nil {
+		t.Errorf("absent dir: expected no error, got %v", err)
+	}
+
+	for _, path := range []string{dangling, filepath.Join(dangling, "screenshots")} {
+		if _, err := StatDir(path); !errors.Is(err, ErrBrokenSymlink) {
+			t.Errorf("StatDir(%s): expected ErrBrokenSymlink, got %v", path, err)
+		}
+		if _, err := HasScreenshots(path); !errors.Is(err, ErrBrokenSymlink) {
+			t.Errorf("HasScreenshots(%s): expected ErrBrokenSymlink, got %v", path, err)
+		}
+	}
+
+	// A working symlink whose target lacks the subdirectory is just absent
+	if _, err := StatDir(filepath.Join(valid, "missing")); err != nil {
+		t.Errorf("missing dir under a valid symlink: expected no error, got %v", err)
+	}
+
+	if _, err := StatDir(filepath.Join(target, "page.png")); err == nil {
+		t.Error("expected an error for a file")
+	}
+}
+
+func TestCompareDirectories_SymlinkedFilesAndDirs(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "baseline")
+	createTestPNG(t, filepath.Join(baseDir, "page.png"), 4, 4, color.White)
+
+	// The current directory is a symlink, and so is the screenshot in it
+	cache := filepath.Join(dir, "cache")
+	createTestPNG(t, filepath.Join(dir, "store", "page.png"), 4, 4, color.White)
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "store", "page.png"), filepath.Join(cache, "page.png")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	currDir := filepath.Join(dir, "current")
+	if err := os.Symlink(cache, currDir); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := CompareDirectories(baseDir, currDir, 0.1)
+	if err != nil {
+		t.Fatalf("CompareDirectories failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != StatusUnchanged {
+		t.Errorf("expected page.png unchanged, got %+v", results)
+	}
+}
@@ -0,0 +1,35 @@
+package imgdiff
+
+import "fmt"
+
+// CompareThreeWay compares currentDir against both baselineDir and
+// referenceDir (e.g. PR head against the PR's base and against main's
+// head). The returned results are those of the baseline comparison, each
+// with Reference set to the same screenshot's comparison against the
+// reference. Screenshots that exist only in referenceDir are not reported.
+func CompareThreeWay(baselineDir, currentDir, referenceDir string, opts CompareOptions) ([]Result, error) {
+	results, err := CompareDirectoriesWithOptions(baselineDir, currentDir, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare against baseline: %w", err)
+	}
+
+	refOpts := opts
+	refOpts.OnResult = nil
+	refOpts.FailFast = nil
+	refResults, err := CompareDirectoriesWithOptions(referenceDir, currentDir, refOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare against reference: %w", err)
+	}
+
+	byName := make(map[string]Result, len(refResults))
+	for _, r := range refResults {
+		byName[r.Name] = r
+	}
+	for i := range results {
+		if ref, ok := byName[results[i].Name]; ok {
+			results[i].Reference = &ref
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package imgdiff
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Timings records wall-clock time spent in each phase of a comparison run.
+// Decode and Compare are accumulated by CompareFiles, and Encode by report
+// generation, when a *Timings is set in their options; the other phases are
+// measured by the caller. The phases do not overlap, so anything not covered
+// by them (listing, hashing, writing files) is Total minus their sum. With
+// CompareOptions.Concurrency, Decode and Compare are summed across workers
+// and may exceed the wall-clock time they took.
+type Timings struct {
+	mu sync.Mutex
+
+	Download time.Duration // fetching remote baseline/current screenshots
+	Decode   time.Duration // decoding screenshots for comparison
+	Compare  time.Duration // pixel comparison
+	Encode   time.Duration // encoding report images
+	Report   time.Duration // report generation, excluding Encode
+	Total    time.Duration
+}
+
+// addDecode, addCompare, and addEncode accumulate time into a phase. They
+// are safe for concurrent use and do nothing on a nil Timings.
+func (t *Timings) addDecode(d time.Duration)  { t.add(func() { t.Decode += d }) }
+func (t *Timings) addCompare(d time.Duration) { t.add(func() { t.Compare += d }) }
+func (t *Timings) addEncode(d time.Duration)  { t.add(func() { t.Encode += d }) }
+
+func (t *Timings) add(update func()) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update()
+}
+
+// TimingPhase is one named entry of a Timings breakdown.
+type TimingPhase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Phases returns the breakdown in run order, with an "other" phase for time
+// not attributed to any of the others.
+func (t *Timings) Phases() []TimingPhase {
+	phases := []TimingPhase{
+		{"download", t.Download},
+		{"decode", t.Decode},
+		{"compare", t.Compare},
+		{"encode", t.Encode},
+		{"report", t.Report},
+	}
+	other := t.Total
+	for _, p := range phases {
+		other -= p.Duration
+	}
+	return append(phases, TimingPhase{"other", max(other, 0)})
+}
+
+// MarshalJSON writes each phase and the total in milliseconds, e.g.
+// {"download_ms": 1250.4, ..., "total_ms": 5300.2}.
+func (t *Timings) MarshalJSON() ([]byte, error) {
+	ms := make(map[string]float64)
+	for _, p := range t.Phases() {
+		ms[p.Name+"_ms"] = milliseconds(p.Duration)
+	}
+	ms["total_ms"] = milliseconds(t.Total)
+	return json.Marshal(ms)
+}
+
+// milliseconds converts d to milliseconds, rounded to 0.1ms.
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Round(100*time.Microsecond)) / float64(time.Millisecond)
+}
@@ -0,0 +1,52 @@
+package imgdiff
+
+import (
+	"encoding/json"
+	"image/color"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTimings(t *testing.T) {
+	timings := &Timings{
+		Download: 2 * time.Second,
+		Decode:   time.Second,
+		Compare:  500 * time.Millisecond,
+		Total:    4 * time.Second,
+	}
+
+	phases := timings.Phases()
+	last := phases[len(phases)-1]
+	if last.Name != "other" || last.Duration != 500*time.Millisecond {
+		t.Errorf("expected other=500ms, got %s=%s", last.Name, last.Duration)
+	}
+
+	data, err := json.Marshal(timings)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var ms map[string]float64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if ms["download_ms"] != 2000 || ms["compare_ms"] != 500 || ms["total_ms"] != 4000 {
+		t.Errorf("unexpected JSON breakdown: %s", data)
+	}
+}
+
+func TestCompareFiles_Timings(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "baseline.png")
+	currentPath := filepath.Join(dir, "page.png")
+	createTestPNG(t, baselinePath, 50, 50, color.White)
+	createTestPNG(t, currentPath, 50, 50, color.Black)
+
+	timings := &Timings{}
+	if _, err := CompareFiles(baselinePath, currentPath, CompareOptions{Threshold: 0.2, Timings: timings}); err != nil {
+		t.Fatalf("CompareFiles failed: %v", err)
+	}
+	if timings.Decode <= 0 || timings.Compare <= 0 {
+		t.Errorf("expected decode and compare time to be recorded, got %+v", timings)
+	}
+}
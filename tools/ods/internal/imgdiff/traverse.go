@@ -0,0 +1,37 @@
+package imgdiff
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// TraverseErrorPolicy selects what a directory comparison does when a
+// screenshot or project directory cannot be read (e.g. a permission error).
+type TraverseErrorPolicy string
+
+const (
+	// TraverseErrorFail aborts the comparison with the error (the default).
+	TraverseErrorFail TraverseErrorPolicy = "fail"
+	// TraverseErrorSkip records a StatusError result and carries on, so one
+	// unreadable file doesn't block an otherwise useful report.
+	TraverseErrorSkip TraverseErrorPolicy = "skip"
+)
+
+// ParseTraverseErrorPolicy validates a policy name. An empty string selects
+// TraverseErrorFail.
+func ParseTraverseErrorPolicy(s string) (TraverseErrorPolicy, error) {
+	switch p := TraverseErrorPolicy(s); p {
+	case "":
+		return TraverseErrorFail, nil
+	case TraverseErrorFail, TraverseErrorSkip:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid traverse error policy %q (valid: %s, %s)", s, TraverseErrorFail, TraverseErrorSkip)
+}
+
+// errorResult records a screenshot or directory that could not be read.
+func errorResult(name string, err error) Result {
+	log.WithField("screenshot", name).Warnf("Skipping unreadable screenshot: %v", err)
+	return Result{Name: name, Status: StatusError, Error: err.Error()}
+}
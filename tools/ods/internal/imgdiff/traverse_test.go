@@ -0,0 +1,87 @@
+package imgdiff
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareDirectories_OnTraverseError(t *testing.T) {
+	baseline := t.TempDir()
+	current := t.TempDir()
+	createTestPNG(t, filepath.Join(baseline, "ok.png"), 4, 4, color.White)
+	createTestPNG(t, filepath.Join(current, "ok.png"), 4, 4, color.White)
+	createTestPNG(t, filepath.Join(baseline, "bad.png"), 4, 4, color.White)
+	if err := os.WriteFile(filepath.Join(current, "bad.png"), []byte("not a png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CompareDirectoriesWithOptions(baseline, current, CompareOptions{}); err == nil {
+		t.Fatal("expected the default policy to fail on an unreadable screenshot")
+	}
+
+	results, err := CompareDirectoriesWithOptions(baseline, current, CompareOptions{OnTraverseError: TraverseErrorSkip})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if r := results[0]; r.Name != "bad.png" || r.Status != StatusError || r.Error == "" {
+		t.Errorf("expected bad.png first with an error, got %+v", r)
+	}
+	if r := results[1]; r.Name != "ok.png" || r.Status != StatusUnchanged {
+		t.Errorf("expected ok.png unchanged, got %+v", r)
+	}
+
+	summary := BuildSummary("test", results)
+	if summary.Errored != 1 || summary.Total != 2 || !summary.HasDifferences {
+		t.Errorf("expected 1 errored of 2 with differences, got %+v", summary)
+	}
+	if summary.Files[0].Error == "" {
+		t.Error("expected the summary to keep the error message")
+	}
+}
+
+func TestCompareNestedDirectories_OnTraverseError(t *testing.T) {
+	baseline := t.TempDir()
+	current := t.TempDir()
+	for _, project := range []string{"admin", "chat"} {
+		createTestPNG(t, filepath.Join(baseline, project, "page.png"), 4, 4, color.White)
+	}
+	createTestPNG(t, filepath.Join(current, "admin", "page.png"), 4, 4, color.White)
+	// A file where the chat project directory should be cannot be listed.
+	if err := os.WriteFile(filepath.Join(current, "chat"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CompareNestedDirectories(baseline, current, CompareOptions{}); err == nil {
+		t.Fatal("expected the default policy to fail on an unlistable project")
+	}
+
+	results, err := CompareNestedDirectories(baseline, current, CompareOptions{OnTraverseError: TraverseErrorSkip})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if r := results[0]; r.Name != "chat/" || r.Project != "chat" || r.Status != StatusError {
+		t.Errorf("expected an error result for chat/, got %+v", r)
+	}
+	if r := results[1]; r.Name != "admin/page.png" || r.Status != StatusUnchanged {
+		t.Errorf("expected admin/page.png unchanged, got %+v", r)
+	}
+}
+
+func TestParseTraverseErrorPolicy(t *testing.T) {
+	for in, want := range map[string]TraverseErrorPolicy{"": TraverseErrorFail, "fail": TraverseErrorFail, "skip": TraverseErrorSkip} {
+		if got, err := ParseTraverseErrorPolicy(in); err != nil || got != want {
+			t.Errorf("ParseTraverseErrorPolicy(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseTraverseErrorPolicy("ignore"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
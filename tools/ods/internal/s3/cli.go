@@ -0,0 +1,19 @@
+package s3
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// ErrCLINotInstalled is returned instead of running an AWS CLI command when
+// the aws binary is not on PATH, so first-time users get install
+// instructions rather than an exec error followed by a login hint.
+var ErrCLINotInstalled = errors.New("AWS CLI (aws) is not installed; install it from https://aws.amazon.com/cli/")
+
+// CheckCLI returns ErrCLINotInstalled if the aws binary is not on PATH.
+func CheckCLI() error {
+	if _, err := exec.LookPath("aws"); err != nil {
+		return ErrCLINotInstalled
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package s3
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncDown_CLINotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	destDir := filepath.Join(t.TempDir(), "baseline")
+	err := SyncDown("s3://bucket/baselines/admin/main/", destDir)
+	if !errors.Is(err, ErrCLINotInstalled) {
+		t.Fatalf("expected ErrCLINotInstalled, got %v", err)
+	}
+	if strings.Contains(err.Error(), "sso login") {
+		t.Errorf("expected no login hint for a missing CLI, got %q", err)
+	}
+	if _, statErr := os.Stat(destDir); !os.IsNotExist(statErr) {
+		t.Errorf("expected no destination directory to be created, got %v", statErr)
+	}
+
+	if err := SyncUp(t.TempDir(), "s3://bucket/baselines/admin/main/", false); !errors.Is(err, ErrCLINotInstalled) {
+		t.Errorf("SyncUp: expected ErrCLINotInstalled, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package s3
+
+import "sync"
+
+// Config selects the AWS credentials profile and region used for S3
+// operations. Empty fields fall back to the ambient AWS configuration
+// (AWS_PROFILE, AWS_REGION, ~/.aws/config), which the AWS CLI reads itself.
+type Config struct {
+	Profile string
+	Region  string
+}
+
+var (
+	configMu sync.RWMutex
+	config   Config
+)
+
+// Configure sets the profile and region passed to every subsequent AWS CLI
+// invocation in this package.
+func Configure(c Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	config = c
+}
+
+// currentConfig returns the Config set by Configure.
+func currentConfig() Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return config
+}
+
+// awsArgs appends the configured --profile and --region to AWS CLI args.
+func awsArgs(args ...string) []string {
+	c := currentConfig()
+	if c.Profile != "" {
+		args = append(args, "--profile", c.Profile)
+	}
+	if c.Region != "" {
+		args = append(args, "--region", c.Region)
+	}
+	return args
+}
+
+// LoginArgs returns the AWS CLI args for "aws sso login" against the
+// configured profile.
+func LoginArgs() []string {
+	return awsArgs("sso", "login")
+}
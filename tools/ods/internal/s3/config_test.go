@@ -0,0 +1,39 @@
+package s3
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAWSArgs(t *testing.T) {
+	t.Cleanup(func() { Configure(Config{}) })
+
+	tests := []struct {
+		config Config
+		want   []string
+	}{
+		{Config{}, []string{"s3", "ls"}},
+		{Config{Profile: "dev"}, []string{"s3", "ls", "--profile", "dev"}},
+		{Config{Region: "eu-west-1"}, []string{"s3", "ls", "--region", "eu-west-1"}},
+		{Config{Profile: "dev", Region: "eu-west-1"}, []string{"s3", "ls", "--profile", "dev", "--region", "eu-west-1"}},
+	}
+	for _, tt := range tests {
+		Configure(tt.config)
+		if got := awsArgs("s3", "ls"); !slices.Equal(got, tt.want) {
+			t.Errorf("awsArgs with %+v = %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPEndpoint_Region(t *testing.T) {
+	t.Cleanup(func() { Configure(Config{}) })
+	u := &S3URL{Bucket: "bucket", Key: "a/b.tar.gz"}
+
+	if got, want := u.HTTPEndpoint(), "https://bucket.s3.amazonaws.com/a/b.tar.gz"; got != want {
+		t.Errorf("HTTPEndpoint() = %q, want %q", got, want)
+	}
+	Configure(Config{Region: "eu-west-1"})
+	if got, want := u.HTTPEndpoint(), "https://bucket.s3.eu-west-1.amazonaws.com/a/b.tar.gz"; got != want {
+		t.Errorf("HTTPEndpoint() with region = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorKind classifies AWS CLI failures so callers can react to them.
+type ErrorKind int
+
+const (
+	// ErrorKindOther is any failure that could not be classified.
+	ErrorKindOther ErrorKind = iota
+	// ErrorKindAuth means credentials are missing, expired, or rejected.
+	ErrorKindAuth
+	// ErrorKindNotFound means the bucket or key does not exist.
+	ErrorKindNotFound
+)
+
+// String returns a human-readable name for the kind.
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorKindAuth:
+		return "auth"
+	case ErrorKindNotFound:
+		return "not found"
+	default:
+		return "other"
+	}
+}
+
+// authHint is appended to errors that may be fixed by logging in.
+const authHint = "\n\nTo authenticate, run:\n  aws sso login\n\nOr configure AWS credentials with:\n  aws configure sso"
+
+// authMarkers are substrings of AWS CLI stderr that indicate a credentials problem.
+var authMarkers = []string{
+	"unable to locate credentials",
+	"expiredtoken",
+	"token has expired",
+	"the sso session",
+	"error loading sso token",
+	"invalidaccesskeyid",
+	"signaturedoesnotmatch",
+	"invalidclienttokenid",
+	"accessdenied",
+	"access denied",
+}
+
+// notFoundMarkers are substrings of AWS CLI stderr that indicate a missing bucket or key.
+var notFoundMarkers = []string{
+	"nosuchbucket",
+	"the specified bucket does not exist",
+	"nosuchkey",
+	"(404)",
+}
+
+// Error is returned by AWS CLI backed operations. Use errors.As to inspect
+// the Kind, e.g. to prompt for "aws sso login" on ErrorKindAuth.
+type Error struct {
+	// Op is the AWS CLI operation that failed (e.g. "aws s3 sync").
+	Op string
+	// Kind classifies the failure.
+	Kind ErrorKind
+	// Stderr is the captured standard error output of the AWS CLI.
+	Stderr string
+	// Err is the underlying process error.
+	Err error
+}
+
+// Error implements the error interface, including a hint on how to fix the failure.
+func (e *Error) Error() string {
+	switch e.Kind {
+	case ErrorKindNotFound:
+		return fmt.Sprintf("%s failed: %v (bucket or prefix not found — check the S3 URL)", e.Op, e.Err)
+	default:
+		return fmt.Sprintf("%s failed: %v%s", e.Op, e.Err, authHint)
+	}
+}
+
+// Unwrap returns the underlying process error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsAuthError reports whether err is an S3 error caused by missing or invalid credentials.
+func IsAuthError(err error) bool {
+	var s3Err *Error
+	return errors.As(err, &s3Err) && s3Err.Kind == ErrorKindAuth
+}
+
+// IsNotFoundError reports whether err is an S3 error caused by a missing bucket or key.
+func IsNotFoundError(err error) bool {
+	var s3Err *Error
+	return errors.As(err, &s3Err) && s3Err.Kind == ErrorKindNotFound
+}
+
+// newCLIError builds an Error for a failed AWS CLI invocation, classifying
+// it from the captured stderr.
+func newCLIError(op string, err error, stderr string) *Error {
+	return &Error{Op: op, Kind: classifyStderr(stderr), Stderr: stderr, Err: err}
+}
+
+// classifyStderr maps AWS CLI stderr output to an ErrorKind.
+func classifyStderr(stderr string) ErrorKind {
+	msg := strings.ToLower(stderr)
+	for _, marker := range authMarkers {
+		if strings.Contains(msg, marker) {
+			return ErrorKindAuth
+		}
+	}
+	for _, marker := range notFoundMarkers {
+		if strings.Contains(msg, marker) {
+			return ErrorKindNotFound
+		}
+	}
+	return ErrorKindOther
+}
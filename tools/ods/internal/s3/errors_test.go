@@ -0,0 +1,42 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCLIErrorClassification(t *testing.T) {
+	tests := []struct {
+		stderr string
+		kind   ErrorKind
+	}{
+		{"fatal error: Unable to locate credentials", ErrorKindAuth},
+		{"An error occurred (ExpiredToken) when calling the ListObjectsV2 operation", ErrorKindAuth},
+		{"Error loading SSO Token: Token for my-sso does not exist", ErrorKindAuth},
+		{"An error occurred (NoSuchBucket) when calling the ListObjectsV2 operation: The specified bucket does not exist", ErrorKindNotFound},
+		{"Could not connect to the endpoint URL", ErrorKindOther},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("failed to download: %w", newCLIError("aws s3 sync", errors.New("exit status 1"), tt.stderr))
+
+		var s3Err *Error
+		if !errors.As(err, &s3Err) {
+			t.Fatalf("expected *Error to be found via errors.As for %q", tt.stderr)
+		}
+		if s3Err.Kind != tt.kind {
+			t.Errorf("stderr %q: expected kind %s, got %s", tt.stderr, tt.kind, s3Err.Kind)
+		}
+		if IsAuthError(err) != (tt.kind == ErrorKindAuth) {
+			t.Errorf("stderr %q: IsAuthError mismatch", tt.stderr)
+		}
+		if IsNotFoundError(err) != (tt.kind == ErrorKindNotFound) {
+			t.Errorf("stderr %q: IsNotFoundError mismatch", tt.stderr)
+		}
+		if tt.kind == ErrorKindAuth && !strings.Contains(err.Error(), "aws sso login") {
+			t.Errorf("stderr %q: expected login hint in error message", tt.stderr)
+		}
+	}
+}
@@ -36,8 +36,12 @@ func ParseS3URL(s3url string) (*S3URL, error) {
 	}, nil
 }
 
-// HTTPEndpoint returns the HTTP endpoint for unsigned access.
+// HTTPEndpoint returns the HTTP endpoint for unsigned access, using the
+// regional endpoint when a region has been configured.
 func (s *S3URL) HTTPEndpoint() string {
+	if region := currentConfig().Region; region != "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, region, s.Key)
+	}
 	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s.Bucket, s.Key)
 }
 
@@ -64,6 +68,9 @@ func FetchToFile(s3url string, destPath string) error {
 	}
 
 	// Try signed request using AWS CLI
+	if err := CheckCLI(); err != nil {
+		return fmt.Errorf("unsigned download of %s failed and signed download needs the AWS CLI: %w", s3url, err)
+	}
 	log.Info("Unsigned download failed, attempting signed download...")
 	if err := fetchWithAWSCLI(s3url, destPath); err != nil {
 		return fmt.Errorf("failed to download from S3: %w\n\nTo authenticate, run:\n  aws sso login\n\nOr configure AWS credentials with:\n  aws configure sso", err)
@@ -112,7 +119,10 @@ func fetchUnsigned(s3url *S3URL, destPath string) (err error) {
 
 // fetchWithAWSCLI attempts to download the file using AWS CLI.
 func fetchWithAWSCLI(s3url string, destPath string) error {
-	cmd := exec.Command("aws", "s3", "cp", s3url, destPath)
+	release := acquire()
+	defer release()
+
+	cmd := exec.Command("aws", awsArgs("s3", "cp", s3url, destPath)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
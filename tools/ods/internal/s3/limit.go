@@ -0,0 +1,101 @@
+package s3
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/onyx-dot-app/onyx/tools/ods/internal/filelock"
+)
+
+const (
+	// MaxConcurrencyEnv names the environment variable that caps how many
+	// AWS CLI processes may run at once across every ods process on the
+	// machine. Unset or 0 means no limit.
+	MaxConcurrencyEnv = "ODS_S3_MAX_CONCURRENCY"
+
+	// SlotDirEnv names the environment variable that overrides the
+	// directory holding the slot lock files the processes share.
+	SlotDirEnv = "ODS_S3_SLOT_DIR"
+)
+
+// slotPollInterval is how often a waiting acquire retries the slots.
+var slotPollInterval = 100 * time.Millisecond
+
+var (
+	limiterOnce sync.Once
+	// maxSlots is the number of AWS CLI process slots; 0 means unlimited.
+	maxSlots int
+	// slotDir holds one lock file per slot.
+	slotDir string
+)
+
+// acquire blocks until an AWS CLI process slot is free and returns a func
+// that releases it. A slot is an exclusive lock on one of maxSlots files in
+// slotDir, so the limit holds across separate ods processes (e.g. parallel
+// CI jobs on one runner), not just within this one.
+func acquire() func() {
+	limiterOnce.Do(loadLimit)
+	if maxSlots == 0 {
+		return func() {}
+	}
+
+	waiting := false
+	for {
+		for i := 0; i < maxSlots; i++ {
+			release, ok, err := filelock.TryLock(filepath.Join(slotDir, fmt.Sprintf("slot-%d.lock", i)))
+			if err != nil {
+				log.Warnf("Ignoring %s: failed to lock an S3 slot: %v", MaxConcurrencyEnv, err)
+				return func() {}
+			}
+			if ok {
+				return release
+			}
+		}
+		if !waiting {
+			log.Debugf("Waiting for an S3 slot (%s=%d)...", MaxConcurrencyEnv, maxSlots)
+			waiting = true
+		}
+		time.Sleep(slotPollInterval)
+	}
+}
+
+// loadLimit reads MaxConcurrencyEnv and SlotDirEnv and prepares the slot
+// directory, leaving the limit off if either is unusable.
+func loadLimit() {
+	n, err := parseMaxConcurrency(os.Getenv(MaxConcurrencyEnv))
+	if err != nil {
+		log.Warnf("Ignoring %s: %v", MaxConcurrencyEnv, err)
+		return
+	}
+	if n == 0 {
+		return
+	}
+
+	dir := os.Getenv(SlotDirEnv)
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "ods-s3-slots")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warnf("Ignoring %s: failed to create %s: %v", MaxConcurrencyEnv, dir, err)
+		return
+	}
+	maxSlots, slotDir = n, dir
+}
+
+// parseMaxConcurrency parses a MaxConcurrencyEnv value. An empty value is 0.
+func parseMaxConcurrency(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value %q: expected a non-negative integer", s)
+	}
+	return n, nil
+}
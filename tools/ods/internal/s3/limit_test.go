@@ -0,0 +1,122 @@
+package s3
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseMaxConcurrency(t *testing.T) {
+	for value, want := range map[string]int{"": 0, "0": 0, "4": 4} {
+		got, err := parseMaxConcurrency(value)
+		if err != nil || got != want {
+			t.Errorf("parseMaxConcurrency(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"-1", "many", "1.5"} {
+		if _, err := parseMaxConcurrency(value); err == nil {
+			t.Errorf("parseMaxConcurrency(%q): expected an error", value)
+		}
+	}
+}
+
+// resetLimit clears the limiter so the next acquire rereads the environment.
+func resetLimit(t *testing.T) {
+	t.Helper()
+	interval := slotPollInterval
+	reset := func() { limiterOnce, maxSlots, slotDir = sync.Once{}, 0, "" }
+	reset()
+	slotPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() {
+		reset()
+		slotPollInterval = interval
+	})
+}
+
+func TestAcquireLimitsConcurrency(t *testing.T) {
+	t.Setenv(MaxConcurrencyEnv, "2")
+	t.Setenv(SlotDirEnv, t.TempDir())
+	resetLimit(t)
+
+	releaseA := acquire()
+	releaseB := acquire()
+
+	acquired := make(chan func())
+	go func() { acquired <- acquire() }()
+
+	select {
+	case <-acquired:
+		t.Fatal("third acquire should block while both slots are held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	releaseA()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(time.Second):
+		t.Fatal("third acquire should proceed once a slot is released")
+	}
+	releaseB()
+}
+
+// holdSlotEnv makes TestHoldSlotHelper hold a slot until its stdin closes,
+// so TestAcquireAcrossProcesses can compete with a second process.
+const holdSlotEnv = "ODS_TEST_HOLD_S3_SLOT"
+
+func TestHoldSlotHelper(t *testing.T) {
+	if os.Getenv(holdSlotEnv) == "" {
+		t.Skip("helper process for TestAcquireAcrossProcesses")
+	}
+	release := acquire()
+	os.Stdout.WriteString("held\n")
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+	release()
+}
+
+func TestAcquireAcrossProcesses(t *testing.T) {
+	t.Setenv(MaxConcurrencyEnv, "1")
+	t.Setenv(SlotDirEnv, t.TempDir())
+	resetLimit(t)
+
+	helper := exec.Command(os.Args[0], "-test.run=^TestHoldSlotHelper$")
+	helper.Env = append(os.Environ(), holdSlotEnv+"=1")
+	stdin, err := helper.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := helper.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := helper.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = stdin.Close()
+		_ = helper.Wait()
+	})
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "held\n" {
+		t.Fatalf("helper did not take the slot: %q, %v", line, err)
+	}
+
+	acquired := make(chan func())
+	go func() { acquired <- acquire() }()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquire should block while another process holds the only slot")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	_ = stdin.Close()
+	select {
+	case release := <-acquired:
+		release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquire should proceed once the other process releases its slot")
+	}
+}
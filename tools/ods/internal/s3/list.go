@@ -0,0 +1,118 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Object describes an S3 object returned by ListObjects.
+type Object struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+	ETag         string // without quotes; the MD5 hex digest for single-part uploads
+}
+
+// ListObjects lists every object under an S3 prefix using AWS CLI (which
+// follows pagination itself). Failures are returned as *Error.
+// This is equivalent to: aws s3api list-objects-v2 --bucket <b> --prefix <p>
+func ListObjects(s3url string) ([]Object, error) {
+	return listObjects(s3url)
+}
+
+// HasObjects reports whether at least one object exists under an S3 prefix,
+// fetching a single key rather than the whole listing.
+func HasObjects(s3url string) (bool, error) {
+	objects, err := listObjects(s3url, "--max-items", "1")
+	if err != nil {
+		return false, err
+	}
+	return len(objects) > 0, nil
+}
+
+// LastModified returns the most recent last-modified time of any object
+// under an S3 prefix, or the zero time if the prefix is empty.
+func LastModified(s3url string) (time.Time, error) {
+	objects, err := listObjects(s3url)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return newestModified(objects), nil
+}
+
+// newestModified returns the latest LastModified among objects.
+func newestModified(objects []Object) time.Time {
+	var newest time.Time
+	for _, o := range objects {
+		if o.LastModified.After(newest) {
+			newest = o.LastModified
+		}
+	}
+	return newest
+}
+
+// listObjects runs list-objects-v2 for an S3 prefix with extra CLI args.
+func listObjects(s3url string, extraArgs ...string) ([]Object, error) {
+	parsed, err := ParseS3URL(s3url)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"s3api", "list-objects-v2",
+		"--bucket", parsed.Bucket, "--prefix", parsed.Key, "--output", "json"}
+	args = append(args, extraArgs...)
+
+	if err := CheckCLI(); err != nil {
+		return nil, err
+	}
+
+	release := acquire()
+	defer release()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("aws", awsArgs(args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		_, _ = os.Stderr.Write(stderr.Bytes())
+		return nil, newCLIError("aws s3api list-objects-v2", err, stderr.String())
+	}
+
+	return parseListObjects(stdout.Bytes())
+}
+
+// parseListObjects decodes `aws s3api list-objects-v2` JSON output. An empty
+// prefix produces no output at all, which is treated as no objects.
+func parseListObjects(data []byte) ([]Object, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var out struct {
+		Contents []struct {
+			Key          string
+			LastModified string
+			Size         int64
+			ETag         string
+		}
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse S3 listing: %w", err)
+	}
+
+	objects := make([]Object, 0, len(out.Contents))
+	for _, c := range out.Contents {
+		modified, err := time.Parse(time.RFC3339, strings.Replace(c.LastModified, "+00:00", "Z", 1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse last-modified time for %s: %w", c.Key, err)
+		}
+		objects = append(objects, Object{Key: c.Key, LastModified: modified, Size: c.Size, ETag: strings.Trim(c.ETag, `"`)})
+	}
+	return objects, nil
+}
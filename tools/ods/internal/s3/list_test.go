@@ -0,0 +1,45 @@
+package s3
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseListObjects(t *testing.T) {
+	data := []byte(`{
+  "Contents": [
+    {"Key": "baselines/admin/main/page.png", "LastModified": "2025-03-04T05:06:07+00:00", "Size": 1234},
+    {"Key": "baselines/admin/pr-1/page.png", "LastModified": "2025-03-05T00:00:00.000Z", "Size": 99}
+  ]
+}`)
+
+	objects, err := parseListObjects(data)
+	if err != nil {
+		t.Fatalf("parseListObjects failed: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(objects))
+	}
+	want := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	if objects[0].Key != "baselines/admin/main/page.png" || !objects[0].LastModified.Equal(want) || objects[0].Size != 1234 {
+		t.Errorf("unexpected first object: %+v", objects[0])
+	}
+
+	empty, err := parseListObjects(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected no objects for empty output, got %v, %v", empty, err)
+	}
+}
+
+func TestNewestModified(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	got := newestModified([]Object{{Key: "a", LastModified: older}, {Key: "b", LastModified: newer}})
+	if !got.Equal(newer) {
+		t.Errorf("expected %v, got %v", newer, got)
+	}
+	if !newestModified(nil).IsZero() {
+		t.Error("expected zero time for no objects")
+	}
+}
@@ -0,0 +1,182 @@
+package s3
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// progressInterval is the minimum time between periodic progress log lines.
+const progressInterval = 5 * time.Second
+
+// remainingPattern matches the file count in AWS CLI progress lines such as
+// "Completed 1.2 MiB/3.4 MiB (2.0 MiB/s) with 12 file(s) remaining".
+var remainingPattern = regexp.MustCompile(`with (\d+) file\(s\) remaining`)
+
+// TransferStats summarises an AWS CLI transfer.
+type TransferStats struct {
+	Objects int
+	Bytes   int64
+}
+
+// transferTracker accumulates stats from AWS CLI output lines.
+type transferTracker struct {
+	verb      string // "download" or "upload"
+	stats     TransferStats
+	remaining int
+	lastLog   time.Time
+}
+
+// handleLine processes a single line of AWS CLI output. It returns false if
+// the line was not recognised, in which case the caller should echo it.
+func (t *transferTracker) handleLine(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+
+	if m := remainingPattern.FindStringSubmatch(line); m != nil {
+		t.remaining, _ = strconv.Atoi(m[1])
+		return true
+	}
+
+	prefix := t.verb + ": "
+	if !strings.HasPrefix(line, prefix) {
+		return false
+	}
+
+	t.stats.Objects++
+	if path := t.localPath(strings.TrimPrefix(line, prefix)); path != "" {
+		if info, err := os.Stat(path); err == nil {
+			t.stats.Bytes += info.Size()
+		}
+	}
+	log.Debug(line)
+	return true
+}
+
+// localPath extracts the local file path from the "<src> to <dst>" part of
+// a transfer line: the destination for downloads, the source for uploads.
+func (t *transferTracker) localPath(srcDst string) string {
+	parts := strings.SplitN(srcDst, " to ", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	if t.verb == "download" {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+// progress returns a human-readable progress string such as "120/400 objects".
+func (t *transferTracker) progress() string {
+	if t.remaining > 0 {
+		return fmt.Sprintf("%d/%d objects", t.stats.Objects, t.stats.Objects+t.remaining)
+	}
+	return fmt.Sprintf("%d objects", t.stats.Objects)
+}
+
+// maybeLogProgress emits a periodic progress line if enough time has passed.
+func (t *transferTracker) maybeLogProgress() {
+	if t.stats.Objects == 0 || time.Since(t.lastLog) < progressInterval {
+		return
+	}
+	t.lastLog = time.Now()
+	log.Infof("Progress: %sed %s (%s)", t.verb, t.progress(), humanizeBytes(t.stats.Bytes))
+}
+
+// splitLinesOrCR is a bufio.SplitFunc that splits on both '\n' and '\r', since
+// the AWS CLI redraws its progress line in place with carriage returns.
+func splitLinesOrCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// runAWS runs an AWS CLI command, streaming its output to stderr so stdout
+// stays clean for machine-readable output such as compare --ndjson. Failures
+// are returned as *Error, classified from the captured stderr.
+func runAWS(args ...string) error {
+	if err := CheckCLI(); err != nil {
+		return err
+	}
+	op := "aws " + strings.Join(args[:min(len(args), 2)], " ")
+
+	release := acquire()
+	defer release()
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("aws", awsArgs(args...)...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+
+	if err := cmd.Run(); err != nil {
+		return newCLIError(op, err, stderr.String())
+	}
+	return nil
+}
+
+// runTransfer runs an AWS CLI transfer command, parsing its output to log
+// periodic progress and a final object/byte total. Lines that are not
+// recognised are streamed through unchanged to stderr. Failures are returned
+// as *Error. Like every AWS CLI call in this package, it waits for a free
+// slot when ODS_S3_MAX_CONCURRENCY is set.
+func runTransfer(verb string, args ...string) (TransferStats, error) {
+	tracker := &transferTracker{verb: verb, lastLog: time.Now()}
+	op := "aws " + strings.Join(args[:min(len(args), 2)], " ")
+
+	release := acquire()
+	defer release()
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("aws", awsArgs(args...)...)
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return tracker.stats, fmt.Errorf("failed to capture aws output: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return tracker.stats, newCLIError(op, err, "")
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(splitLinesOrCR)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !tracker.handleLine(line) {
+			_, _ = fmt.Fprintln(os.Stderr, line)
+		}
+		tracker.maybeLogProgress()
+	}
+	// Drain anything left so the process never blocks on a full pipe
+	_, _ = io.Copy(io.Discard, stdout)
+
+	if err := cmd.Wait(); err != nil {
+		return tracker.stats, newCLIError(op, err, stderr.String())
+	}
+
+	if tracker.stats.Objects == 0 {
+		log.Infof("No objects to %s (already up to date)", verb)
+	} else {
+		log.Infof("Transfer complete: %d objects, %s transferred", tracker.stats.Objects, humanizeBytes(tracker.stats.Bytes))
+	}
+
+	return tracker.stats, nil
+}
@@ -0,0 +1,46 @@
+package s3
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransferTracker_ParsesDownloadOutput(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "page.png")
+	if err := os.WriteFile(dest, make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := "Completed 2.0 KiB/6.0 KiB (1.0 KiB/s) with 3 file(s) remaining\r" +
+		"download: s3://bucket/baselines/admin/main/page.png to " + dest + "\n" +
+		"download: s3://bucket/baselines/admin/main/missing.png to " + filepath.Join(dir, "missing.png") + "\n" +
+		"warning: Skipping file /tmp/x. File does not exist.\n"
+
+	tracker := &transferTracker{verb: "download"}
+	var unrecognised []string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Split(splitLinesOrCR)
+	for scanner.Scan() {
+		if !tracker.handleLine(scanner.Text()) {
+			unrecognised = append(unrecognised, scanner.Text())
+		}
+	}
+
+	if tracker.stats.Objects != 2 {
+		t.Errorf("expected 2 objects, got %d", tracker.stats.Objects)
+	}
+	if tracker.stats.Bytes != 2048 {
+		t.Errorf("expected 2048 bytes, got %d", tracker.stats.Bytes)
+	}
+	if got := tracker.progress(); got != "2/5 objects" {
+		t.Errorf("expected progress \"2/5 objects\", got %q", got)
+	}
+	if len(unrecognised) != 1 || !strings.HasPrefix(unrecognised[0], "warning:") {
+		t.Errorf("expected only the warning line to be passed through, got %v", unrecognised)
+	}
+}
@@ -3,47 +3,63 @@ package s3
 import (
 	"fmt"
 	"os"
-	"os/exec"
 
 	log "github.com/sirupsen/logrus"
 )
 
 // SyncDown downloads an S3 prefix to a local directory using AWS CLI.
 // This is equivalent to: aws s3 sync <s3url> <destDir>
+// CLI failures are returned as *Error, or ErrCLINotInstalled if there is no
+// AWS CLI to run.
 func SyncDown(s3url string, destDir string) error {
+	if err := CheckCLI(); err != nil {
+		return err
+	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
 	log.Infof("Downloading from %s to %s ...", s3url, destDir)
-	cmd := exec.Command("aws", "s3", "sync", s3url, destDir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("aws s3 sync failed: %w\n\nTo authenticate, run:\n  aws sso login\n\nOr configure AWS credentials with:\n  aws configure sso", err)
-	}
-
-	return nil
+	_, err := runTransfer("download", "s3", "sync", s3url, destDir)
+	return err
 }
 
 // SyncUp uploads a local directory to an S3 prefix using AWS CLI.
 // If delete is true, files in S3 that don't exist locally are removed.
 // This is equivalent to: aws s3 sync <srcDir> <s3url> [--delete]
 func SyncUp(srcDir string, s3url string, delete bool) error {
+	if err := CheckCLI(); err != nil {
+		return err
+	}
+
 	args := []string{"s3", "sync", srcDir, s3url}
 	if delete {
 		args = append(args, "--delete")
 	}
 
 	log.Infof("Uploading from %s to %s ...", srcDir, s3url)
-	cmd := exec.Command("aws", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	_, err := runTransfer("upload", args...)
+	return err
+}
+
+// RemovePrefix recursively deletes every object under an S3 prefix using AWS CLI.
+// This is equivalent to: aws s3 rm <s3url> --recursive
+func RemovePrefix(s3url string) error {
+	if _, err := ParseS3URL(s3url); err != nil {
+		return err
+	}
+
+	log.Infof("Deleting %s ...", s3url)
+	return runAWS("s3", "rm", s3url, "--recursive")
+}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("aws s3 sync failed: %w\n\nTo authenticate, run:\n  aws sso login\n\nOr configure AWS credentials with:\n  aws configure sso", err)
+// CopyUp uploads a single local file to an S3 object URL using AWS CLI.
+// This is equivalent to: aws s3 cp <srcPath> <s3url>
+func CopyUp(srcPath string, s3url string) error {
+	if _, err := ParseS3URL(s3url); err != nil {
+		return err
 	}
 
-	return nil
+	log.Debugf("Uploading %s to %s", srcPath, s3url)
+	return runAWS("s3", "cp", "--only-show-errors", srcPath, s3url)
 }
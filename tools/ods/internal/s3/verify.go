@@ -0,0 +1,112 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// verifyRetries is how many times VerifyDown re-fetches objects that fail
+// verification before giving up on them.
+const verifyRetries = 2
+
+// VerifyDown checks the files SyncDown wrote to destDir against the S3
+// listing of s3url, re-fetching any that are missing, have the wrong size, or
+// (for single-part uploads) the wrong MD5, since an interrupted sync can
+// leave partial files behind. It returns the keys that still fail after
+// retrying.
+func VerifyDown(s3url, destDir string) ([]string, error) {
+	parsed, err := ParseS3URL(s3url)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := listObjects(s3url)
+	if err != nil {
+		return nil, err
+	}
+
+	bad := mismatchedObjects(objects, parsed.Key, destDir)
+	for attempt := 1; attempt <= verifyRetries && len(bad) > 0; attempt++ {
+		log.Warnf("%d downloaded object(s) failed verification; re-fetching (attempt %d of %d)", len(bad), attempt, verifyRetries)
+		for _, o := range bad {
+			path := localPath(o.Key, parsed.Key, destDir)
+			if err := runAWS("s3", "cp", "--only-show-errors", "s3://"+parsed.Bucket+"/"+o.Key, path); err != nil {
+				log.Warnf("Failed to re-fetch %s: %v", o.Key, err)
+			}
+		}
+		bad = mismatchedObjects(bad, parsed.Key, destDir)
+	}
+
+	var failed []string
+	for _, o := range bad {
+		failed = append(failed, o.Key)
+	}
+	if len(failed) == 0 {
+		log.Infof("Verified %d downloaded object(s)", len(objects))
+	}
+	return failed, nil
+}
+
+// mismatchedObjects returns the objects under prefix whose local copy in
+// destDir is missing or differs from the listing.
+func mismatchedObjects(objects []Object, prefix, destDir string) []Object {
+	var bad []Object
+	for _, o := range objects {
+		path := localPath(o.Key, prefix, destDir)
+		if path == "" {
+			continue // outside the synced prefix (e.g. "main2/" for "main")
+		}
+		if err := verifyFile(path, o); err != nil {
+			log.Debugf("Verification failed for %s: %v", o.Key, err)
+			bad = append(bad, o)
+		}
+	}
+	return bad
+}
+
+// localPath returns where aws s3 sync writes key when syncing prefix into
+// destDir, or "" if key is not under prefix.
+func localPath(key, prefix, destDir string) string {
+	dirPrefix := strings.TrimSuffix(prefix, "/") + "/"
+	rel, ok := strings.CutPrefix(key, dirPrefix)
+	if !ok || rel == "" || strings.HasSuffix(rel, "/") {
+		return ""
+	}
+	return filepath.Join(destDir, filepath.FromSlash(rel))
+}
+
+// verifyFile checks a downloaded file against its listing entry. Multipart
+// ETags ("<hash>-<parts>") are not content MD5s, so those are checked by
+// size only.
+func verifyFile(path string, o Object) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() != o.Size {
+		return fmt.Errorf("size %d, expected %d", info.Size(), o.Size)
+	}
+	if o.ETag == "" || strings.Contains(o.ETag, "-") {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != o.ETag {
+		return fmt.Errorf("MD5 %s, expected %s", sum, o.ETag)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package s3
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMismatchedObjects(t *testing.T) {
+	destDir := t.TempDir()
+	content := []byte("png bytes")
+	sum := md5.Sum(content)
+	etag := hex.EncodeToString(sum[:])
+
+	write := func(rel string, data []byte) {
+		path := filepath.Join(destDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("ok.png", content)
+	write("nested/ok.png", content)
+	write("truncated.png", content[:3])
+	write("corrupt.png", []byte("PNG BYTES"))
+	write("multipart.png", []byte("PNG BYTES"))
+
+	prefix := "baselines/admin/main/"
+	objects := []Object{
+		{Key: prefix + "ok.png", Size: int64(len(content)), ETag: etag},
+		{Key: prefix + "nested/ok.png", Size: int64(len(content)), ETag: etag},
+		{Key: prefix + "truncated.png", Size: int64(len(content)), ETag: etag},
+		{Key: prefix + "corrupt.png", Size: int64(len(content)), ETag: etag},
+		{Key: prefix + "missing.png", Size: int64(len(content)), ETag: etag},
+		{Key: prefix + "multipart.png", Size: int64(len(content)), ETag: etag + "-2"},
+		{Key: "baselines/admin/main2/other.png", Size: 1},
+	}
+
+	bad := mismatchedObjects(objects, "baselines/admin/main", destDir)
+	var got []string
+	for _, o := range bad {
+		got = append(got, o.Key[len(prefix):])
+	}
+	want := []string{"truncated.png", "corrupt.png", "missing.png"}
+	if len(got) != len(want) {
+		t.Fatalf("expected mismatches %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected mismatches %v, got %v", want, got)
+			break
+		}
+	}
+}